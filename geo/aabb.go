@@ -36,6 +36,20 @@ func (aabb *AABB) InertiaTensor() glm.Mat3 {
 	return glm.Mat3{}
 }
 
+// Support returns the corner of the aabb that is the most in the given
+// direction.
+func (aabb *AABB) Support(direction *glm.Vec3) glm.Vec3 {
+	p := aabb.Center
+	for i := 0; i < 3; i++ {
+		if *direction.I(i) < 0 {
+			*p.I(i) -= *aabb.HalfExtend.I(i)
+		} else {
+			*p.I(i) += *aabb.HalfExtend.I(i)
+		}
+	}
+	return p
+}
+
 // TestAABBAABB returns true if these AABB overlap.
 func TestAABBAABB(a, b *AABB) bool {
 	if math.Abs(a.Center.X-b.Center.X) > a.HalfExtend.X+b.HalfExtend.X ||
@@ -46,6 +60,36 @@ func TestAABBAABB(a, b *AABB) bool {
 	return true
 }
 
+// SurfaceArea returns the area of the 6 faces of the aabb.
+func (aabb *AABB) SurfaceArea() float32 {
+	h := &aabb.HalfExtend
+	return 8 * (h.X*h.Y + h.Y*h.Z + h.Z*h.X)
+}
+
+// Contains returns true if b is entirely inside this aabb.
+func (aabb *AABB) Contains(b *AABB) bool {
+	for i := 0; i < 3; i++ {
+		c, h := *aabb.Center.I(i), *aabb.HalfExtend.I(i)
+		bc, bh := *b.Center.I(i), *b.HalfExtend.I(i)
+		if bc-bh < c-h || bc+bh > c+h {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeAABB returns the smallest aabb enclosing both a and b.
+func MergeAABB(a, b *AABB) AABB {
+	var m AABB
+	for i := 0; i < 3; i++ {
+		min := math.Min(*a.Center.I(i)-*a.HalfExtend.I(i), *b.Center.I(i)-*b.HalfExtend.I(i))
+		max := math.Max(*a.Center.I(i)+*a.HalfExtend.I(i), *b.Center.I(i)+*b.HalfExtend.I(i))
+		*m.Center.I(i) = (min + max) / 2
+		*m.HalfExtend.I(i) = (max - min) / 2
+	}
+	return m
+}
+
 // UpdateAABB3x4 computes an enclosing AABB base transformed by t and puts the
 // result in fill. base and fill must not be the same.
 func UpdateAABB3x4(base, fill *AABB, t *glm.Mat3x4) {
@@ -99,6 +99,25 @@ func TestAABB_Intersects(t *testing.T) {
 	}
 }
 
+func TestAABB_Support(t *testing.T) {
+	a := AABB{
+		Center:     glm.Vec3{X: 1, Y: 2, Z: 3},
+		HalfExtend: glm.Vec3{X: 1, Y: 2, Z: 3},
+	}
+	tests := []struct {
+		direction, support glm.Vec3
+	}{
+		{glm.Vec3{X: 1, Y: 1, Z: 1}, glm.Vec3{X: 2, Y: 4, Z: 6}},
+		{glm.Vec3{X: -1, Y: 1, Z: -1}, glm.Vec3{X: 0, Y: 4, Z: 0}},
+		{glm.Vec3{X: 0, Y: -3, Z: 0.5}, glm.Vec3{X: 2, Y: 0, Z: 6}},
+	}
+	for i, test := range tests {
+		if support := a.Support(&test.direction); support != test.support {
+			t.Errorf("[%d] support = %v, want %v", i, support, test.support)
+		}
+	}
+}
+
 func TestClosestPointPointAABB(t *testing.T) {
 	tests := []struct {
 		aabb    AABB
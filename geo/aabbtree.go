@@ -0,0 +1,484 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+)
+
+const (
+	// aabbTreeNull is the index of a missing node.
+	aabbTreeNull = -1
+)
+
+// AABBTreeHandle identifies a leaf of an AABBTree. Handles of removed leaves
+// are reused by the next inserts.
+type AABBTreeHandle int
+
+// AABBTree is a dynamic bounding volume hierarchy of AABBs, each leaf holds
+// the bounds of a user payload. The bounds of the leaves are fattened by
+// Margin so that objects moving a little don't need to be moved in the tree.
+// The tree is kept balanced with rotations as leaves are inserted and removed.
+// [Catto, Box2D b2DynamicTree]
+//
+// The queries report every leaf whose fat bounds pass the test, the caller is
+// expected to test the payload itself if it needs an exact answer. The tree
+// must not be modified from the query callbacks.
+type AABBTree struct {
+	// Margin is added to every side of the bounds of the leaves.
+	Margin float32
+
+	nodes  []aabbTreeNode
+	root   int
+	free   int
+	leaves int
+
+	// scratch memory for the queries.
+	stack     []int
+	pairStack [][2]int
+}
+
+type aabbTreeNode struct {
+	// bounds is the fat bounds of a leaf or the bounds of both children.
+	bounds  AABB
+	payload interface{}
+
+	// parent is the next free node if this node is free.
+	parent      int
+	left, right int
+
+	// height is 0 for leaves and -1 for free nodes.
+	height int
+}
+
+// leaf returns true if this node is a leaf.
+func (n *aabbTreeNode) leaf() bool {
+	return n.left == aabbTreeNull
+}
+
+// NewAABBTree returns an empty tree whose leaves are fattened by margin.
+func NewAABBTree(margin float32) *AABBTree {
+	return &AABBTree{
+		Margin: margin,
+		root:   aabbTreeNull,
+		free:   aabbTreeNull,
+	}
+}
+
+// Insert adds a leaf with the given bounds and payload and returns its handle.
+func (t *AABBTree) Insert(bounds *AABB, payload interface{}) AABBTreeHandle {
+	if t.nodes == nil {
+		t.root, t.free = aabbTreeNull, aabbTreeNull
+	}
+	leaf := t.allocate()
+	t.nodes[leaf] = aabbTreeNode{
+		bounds:  t.fatten(bounds),
+		payload: payload,
+		parent:  aabbTreeNull,
+		left:    aabbTreeNull,
+		right:   aabbTreeNull,
+	}
+	t.insertLeaf(leaf)
+	t.leaves++
+	return AABBTreeHandle(leaf)
+}
+
+// Remove removes the leaf from the tree. Removing a leaf twice does nothing.
+func (t *AABBTree) Remove(h AABBTreeHandle) {
+	if !t.valid(h) {
+		return
+	}
+	t.removeLeaf(int(h))
+	t.release(int(h))
+	t.leaves--
+}
+
+// Update sets the bounds of the leaf. If they are still inside its fat bounds
+// nothing changes and it returns false, otherwise the leaf is moved in the
+// tree and it returns true.
+func (t *AABBTree) Update(h AABBTreeHandle, bounds *AABB) bool {
+	if !t.valid(h) {
+		return false
+	}
+	leaf := int(h)
+	if t.nodes[leaf].bounds.Contains(bounds) {
+		return false
+	}
+	t.removeLeaf(leaf)
+	t.nodes[leaf].bounds = t.fatten(bounds)
+	t.insertLeaf(leaf)
+	return true
+}
+
+// Payload returns the payload of the leaf, or nil if it was removed.
+func (t *AABBTree) Payload(h AABBTreeHandle) interface{} {
+	if !t.valid(h) {
+		return nil
+	}
+	return t.nodes[h].payload
+}
+
+// FatBounds returns the fat bounds of the leaf.
+func (t *AABBTree) FatBounds(h AABBTreeHandle) AABB {
+	if !t.valid(h) {
+		return AABB{}
+	}
+	return t.nodes[h].bounds
+}
+
+// Len returns how many leaves are in the tree.
+func (t *AABBTree) Len() int {
+	return t.leaves
+}
+
+// Height returns the height of the tree, 0 if it's empty or has a single leaf.
+func (t *AABBTree) Height() int {
+	if t.leaves == 0 {
+		return 0
+	}
+	return t.nodes[t.root].height
+}
+
+// QueryAABB calls f with every leaf whose fat bounds overlap b until f returns
+// false.
+func (t *AABBTree) QueryAABB(b *AABB, f func(h AABBTreeHandle) bool) {
+	t.query(func(bounds *AABB) bool { return TestAABBAABB(bounds, b) }, f)
+}
+
+// QuerySphere calls f with every leaf whose fat bounds overlap s until f
+// returns false.
+func (t *AABBTree) QuerySphere(s *Sphere, f func(h AABBTreeHandle) bool) {
+	t.query(func(bounds *AABB) bool { return TestAABBSphere(bounds, s) }, f)
+}
+
+// QueryFrustum calls f with every leaf whose fat bounds may be inside the
+// frustum, seen through the given view matrix, until f returns false.
+func (t *AABBTree) QueryFrustum(frustum *Frustum, view *glm.Mat4, f func(h AABBTreeHandle) bool) {
+	t.query(func(bounds *AABB) bool { return TestAABBFrustum(bounds, frustum, view) }, f)
+}
+
+// CullFrustum calls f with every leaf whose fat bounds may be inside the world
+// space frustum, and where they are relative to it, until f returns false.
+// Nodes entirely inside of some planes of the frustum aren't tested against
+// them again for their children, the leaves of nodes entirely inside the
+// frustum are all reported CullInside without further tests.
+func (t *AABBTree) CullFrustum(frustum *Frustum, f func(h AABBTreeHandle, r CullResult) bool) {
+	if t.leaves == 0 {
+		return
+	}
+	// the stack holds the nodes and the mask of the planes they may cross.
+	stack := append(t.pairStack[:0], [2]int{t.root, int(FrustumAllPlanes)})
+	defer func() { t.pairStack = stack[:0] }()
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n := &t.nodes[top[0]]
+		r, mask := CullFrustumAABB(frustum, &n.bounds, uint8(top[1]))
+		if r == CullOutside {
+			continue
+		}
+		if n.leaf() {
+			if !f(AABBTreeHandle(top[0]), r) {
+				return
+			}
+			continue
+		}
+		stack = append(stack, [2]int{n.left, int(mask)}, [2]int{n.right, int(mask)})
+	}
+}
+
+// RayCast calls f with every leaf whose fat bounds are hit by the ray
+// R(t) = p + t*d with t in [0, maxT]. f returns the new maxT, return a smaller
+// value to only look for closer hits or a negative value to stop.
+func (t *AABBTree) RayCast(p, d *glm.Vec3, maxT float32, f func(h AABBTreeHandle) float32) {
+	t.query(func(bounds *AABB) bool {
+		hit, _, ok := IntersectRayAABB(p, d, bounds)
+		return ok && hit <= maxT
+	}, func(h AABBTreeHandle) bool {
+		maxT = f(h)
+		return maxT >= 0
+	})
+}
+
+// QueryTree calls f with every pair of leaves of t and other whose fat bounds
+// overlap until f returns false, a is a leaf of t and b a leaf of other. If
+// other is t every pair of different leaves is given once.
+func (t *AABBTree) QueryTree(other *AABBTree, f func(a, b AABBTreeHandle) bool) {
+	if t.leaves == 0 || other.leaves == 0 {
+		return
+	}
+	if other == t {
+		t.selfPairs(f)
+		return
+	}
+
+	stack := append(t.pairStack[:0], [2]int{t.root, other.root})
+	defer func() { t.pairStack = stack[:0] }()
+	for len(stack) > 0 {
+		pair := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		a, b := &t.nodes[pair[0]], &other.nodes[pair[1]]
+		if !TestAABBAABB(&a.bounds, &b.bounds) {
+			continue
+		}
+		switch {
+		case a.leaf() && b.leaf():
+			if !f(AABBTreeHandle(pair[0]), AABBTreeHandle(pair[1])) {
+				return
+			}
+		case b.leaf() || !a.leaf() && a.bounds.Volume() > b.bounds.Volume():
+			// descend into the biggest node first.
+			stack = append(stack, [2]int{a.left, pair[1]}, [2]int{a.right, pair[1]})
+		default:
+			stack = append(stack, [2]int{pair[0], b.left}, [2]int{pair[0], b.right})
+		}
+	}
+}
+
+// selfPairs calls f with every pair of different leaves of t whose fat bounds
+// overlap until f returns false.
+func (t *AABBTree) selfPairs(f func(a, b AABBTreeHandle) bool) {
+	for i := range t.nodes {
+		if t.nodes[i].height != 0 {
+			continue
+		}
+		more := true
+		t.QueryAABB(&t.nodes[i].bounds, func(h AABBTreeHandle) bool {
+			if int(h) > i {
+				more = f(AABBTreeHandle(i), h)
+			}
+			return more
+		})
+		if !more {
+			return
+		}
+	}
+}
+
+// query calls f with every leaf whose fat bounds pass the overlap test, the
+// children of a node are only visited if the node passes it.
+func (t *AABBTree) query(overlap func(bounds *AABB) bool, f func(h AABBTreeHandle) bool) {
+	if t.leaves == 0 {
+		return
+	}
+	stack := append(t.stack[:0], t.root)
+	defer func() { t.stack = stack[:0] }()
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n := &t.nodes[i]
+		if !overlap(&n.bounds) {
+			continue
+		}
+		if n.leaf() {
+			if !f(AABBTreeHandle(i)) {
+				return
+			}
+			continue
+		}
+		stack = append(stack, n.left, n.right)
+	}
+}
+
+// valid returns whether h is a leaf of the tree.
+func (t *AABBTree) valid(h AABBTreeHandle) bool {
+	return h >= 0 && int(h) < len(t.nodes) && t.nodes[h].height == 0
+}
+
+// fatten returns b grown by the margin of the tree.
+func (t *AABBTree) fatten(b *AABB) AABB {
+	fat := *b
+	fat.HalfExtend.X += t.Margin
+	fat.HalfExtend.Y += t.Margin
+	fat.HalfExtend.Z += t.Margin
+	return fat
+}
+
+// allocate returns the index of an unused node.
+func (t *AABBTree) allocate() int {
+	if t.free != aabbTreeNull {
+		i := t.free
+		t.free = t.nodes[i].parent
+		return i
+	}
+	t.nodes = append(t.nodes, aabbTreeNode{})
+	return len(t.nodes) - 1
+}
+
+// release puts the node back in the free list.
+func (t *AABBTree) release(i int) {
+	t.nodes[i] = aabbTreeNode{parent: t.free, height: -1}
+	t.free = i
+}
+
+// insertLeaf links the leaf in the tree, next to the sibling that grows the
+// surface area of the tree the least.
+func (t *AABBTree) insertLeaf(leaf int) {
+	if t.root == aabbTreeNull {
+		t.root = leaf
+		t.nodes[leaf].parent = aabbTreeNull
+		return
+	}
+
+	bounds := t.nodes[leaf].bounds
+	index := t.root
+	for !t.nodes[index].leaf() {
+		n := &t.nodes[index]
+		area := n.bounds.SurfaceArea()
+		combined := MergeAABB(&n.bounds, &bounds)
+		combinedArea := combined.SurfaceArea()
+
+		// the cost of creating a new parent for this node and the leaf.
+		cost := 2 * combinedArea
+		// the minimum cost of pushing the leaf further down the tree.
+		inheritance := 2 * (combinedArea - area)
+
+		childCost := func(c int) float32 {
+			child := &t.nodes[c]
+			m := MergeAABB(&bounds, &child.bounds)
+			if child.leaf() {
+				return m.SurfaceArea() + inheritance
+			}
+			return m.SurfaceArea() - child.bounds.SurfaceArea() + inheritance
+		}
+		left, right := n.left, n.right
+		costLeft, costRight := childCost(left), childCost(right)
+		if cost < costLeft && cost < costRight {
+			break
+		}
+		if costLeft < costRight {
+			index = left
+		} else {
+			index = right
+		}
+	}
+
+	sibling := index
+	oldParent := t.nodes[sibling].parent
+	newParent := t.allocate()
+	t.nodes[newParent] = aabbTreeNode{
+		bounds: MergeAABB(&bounds, &t.nodes[sibling].bounds),
+		parent: oldParent,
+		left:   sibling,
+		right:  leaf,
+		height: t.nodes[sibling].height + 1,
+	}
+	if oldParent != aabbTreeNull {
+		t.replaceChild(oldParent, sibling, newParent)
+	} else {
+		t.root = newParent
+	}
+	t.nodes[sibling].parent = newParent
+	t.nodes[leaf].parent = newParent
+
+	t.refit(newParent)
+}
+
+// removeLeaf unlinks the leaf from the tree, its sibling takes the place of
+// their parent.
+func (t *AABBTree) removeLeaf(leaf int) {
+	if leaf == t.root {
+		t.root = aabbTreeNull
+		return
+	}
+	parent := t.nodes[leaf].parent
+	grandParent := t.nodes[parent].parent
+	sibling := t.nodes[parent].left
+	if sibling == leaf {
+		sibling = t.nodes[parent].right
+	}
+
+	if grandParent != aabbTreeNull {
+		t.replaceChild(grandParent, parent, sibling)
+		t.nodes[sibling].parent = grandParent
+		t.release(parent)
+		t.refit(grandParent)
+	} else {
+		t.root = sibling
+		t.nodes[sibling].parent = aabbTreeNull
+		t.release(parent)
+	}
+}
+
+// replaceChild replaces the child old of parent by n.
+func (t *AABBTree) replaceChild(parent, old, n int) {
+	if t.nodes[parent].left == old {
+		t.nodes[parent].left = n
+	} else {
+		t.nodes[parent].right = n
+	}
+}
+
+// refit balances the nodes from index to the root and updates their bounds
+// and height.
+func (t *AABBTree) refit(index int) {
+	for index != aabbTreeNull {
+		index = t.balance(index)
+		n := &t.nodes[index]
+		left, right := &t.nodes[n.left], &t.nodes[n.right]
+		n.height = 1 + maxInt(left.height, right.height)
+		n.bounds = MergeAABB(&left.bounds, &right.bounds)
+		index = n.parent
+	}
+}
+
+// balance rotates the tree at a if one of its children is more than 1 level
+// higher than the other and returns the node that took the place of a.
+func (t *AABBTree) balance(a int) int {
+	A := &t.nodes[a]
+	if A.leaf() || A.height < 2 {
+		return a
+	}
+	b, c := A.left, A.right
+	B, C := &t.nodes[b], &t.nodes[c]
+	switch balance := C.height - B.height; {
+	case balance > 1:
+		t.rotateUp(a, c, b, true)
+		return c
+	case balance < -1:
+		t.rotateUp(a, b, c, false)
+		return b
+	}
+	return a
+}
+
+// rotateUp makes the child up of a take the place of a. other is the other
+// child of a, right is true if up is the right child of a. The highest child
+// of up stays under it, the other one replaces up under a.
+func (t *AABBTree) rotateUp(a, up, other int, right bool) {
+	A, U := &t.nodes[a], &t.nodes[up]
+	f, g := U.left, U.right
+	if t.nodes[f].height < t.nodes[g].height {
+		f, g = g, f
+	}
+
+	// up takes the place of a, a becomes its left child.
+	U.left = a
+	U.parent = A.parent
+	A.parent = up
+	if U.parent != aabbTreeNull {
+		t.replaceChild(U.parent, a, up)
+	} else {
+		t.root = up
+	}
+
+	// f is the highest child of up, it stays. g goes under a in place of up.
+	U.right = f
+	if right {
+		A.right = g
+	} else {
+		A.left = g
+	}
+	t.nodes[g].parent = a
+
+	O, F, G := &t.nodes[other], &t.nodes[f], &t.nodes[g]
+	A.bounds = MergeAABB(&O.bounds, &G.bounds)
+	A.height = 1 + maxInt(O.height, G.height)
+	U.bounds = MergeAABB(&A.bounds, &F.bounds)
+	U.height = 1 + maxInt(A.height, F.height)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,353 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// checkAABBTree verifies the links, heights and bounds of every node of the
+// tree.
+func checkAABBTree(t *testing.T, tree *AABBTree) {
+	if tree.leaves == 0 {
+		if tree.root != aabbTreeNull {
+			t.Errorf("empty tree has root %d", tree.root)
+		}
+		return
+	}
+	var leaves int
+	var check func(i, parent int) int
+	check = func(i, parent int) int {
+		n := &tree.nodes[i]
+		if n.parent != parent {
+			t.Errorf("node %d has parent %d, want %d", i, n.parent, parent)
+		}
+		if n.leaf() {
+			leaves++
+			return 0
+		}
+		hl, hr := check(n.left, i), check(n.right, i)
+		if n.height != 1+maxInt(hl, hr) {
+			t.Errorf("node %d has height %d, want %d", i, n.height, 1+maxInt(hl, hr))
+		}
+		if d := hl - hr; d > 1 || d < -1 {
+			t.Errorf("node %d isn't balanced, %d vs %d", i, hl, hr)
+		}
+		if !containsAABB(&n.bounds, &tree.nodes[n.left].bounds) || !containsAABB(&n.bounds, &tree.nodes[n.right].bounds) {
+			t.Errorf("node %d doesn't contain its children", i)
+		}
+		return n.height
+	}
+	check(tree.root, aabbTreeNull)
+	if leaves != tree.Len() {
+		t.Errorf("tree has %d leaves, Len() = %d", leaves, tree.Len())
+	}
+}
+
+// containsAABB is AABB.Contains with some room for the rounding of MergeAABB.
+func containsAABB(a, b *AABB) bool {
+	grown := *a
+	grown.HalfExtend.AddWith(&glm.Vec3{X: 1e-4, Y: 1e-4, Z: 1e-4})
+	return grown.Contains(b)
+}
+
+func sortedHandles(hs []AABBTreeHandle) []AABBTreeHandle {
+	sort.Slice(hs, func(i, j int) bool { return hs[i] < hs[j] })
+	return hs
+}
+
+func equalHandles(a, b []AABBTreeHandle) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func randomAABB(r *rand.Rand) AABB {
+	return AABB{
+		Center:     glm.Vec3{X: r.Float32() * 100, Y: r.Float32() * 100, Z: r.Float32() * 100},
+		HalfExtend: glm.Vec3{X: r.Float32()*3 + 0.1, Y: r.Float32()*3 + 0.1, Z: r.Float32()*3 + 0.1},
+	}
+}
+
+func TestMergeAABB(t *testing.T) {
+	a := AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}
+	b := AABB{Center: glm.Vec3{X: 3, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 2, Z: 0.5}}
+	m := MergeAABB(&a, &b)
+	want := AABB{Center: glm.Vec3{X: 1.5, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 2.5, Y: 2, Z: 1}}
+	if m != want {
+		t.Errorf("MergeAABB = %v, want %v", m, want)
+	}
+	if !m.Contains(&a) || !m.Contains(&b) || a.Contains(&m) {
+		t.Error("Contains is wrong")
+	}
+	if sa := a.SurfaceArea(); sa != 24 {
+		t.Errorf("SurfaceArea() = %v, want 24", sa)
+	}
+}
+
+func TestAABBTree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tree := NewAABBTree(0.5)
+	var handles []AABBTreeHandle
+	bounds := make(map[AABBTreeHandle]AABB)
+	for i := 0; i < 500; i++ {
+		b := randomAABB(r)
+		h := tree.Insert(&b, i)
+		handles = append(handles, h)
+		bounds[h] = b
+	}
+	checkAABBTree(t, tree)
+	if h := tree.Height(); h > 20 {
+		t.Errorf("Height() = %d for 500 leaves, the tree isn't balanced", h)
+	}
+	if p := tree.Payload(handles[7]); p != 7 {
+		t.Errorf("Payload = %v, want 7", p)
+	}
+
+	// brute force returns the leaves whose fat bounds pass the test.
+	brute := func(test func(fat *AABB) bool) []AABBTreeHandle {
+		var hs []AABBTreeHandle
+		for h := range bounds {
+			fat := tree.FatBounds(h)
+			if test(&fat) {
+				hs = append(hs, h)
+			}
+		}
+		return sortedHandles(hs)
+	}
+	collect := func(query func(f func(h AABBTreeHandle) bool)) []AABBTreeHandle {
+		var hs []AABBTreeHandle
+		query(func(h AABBTreeHandle) bool {
+			hs = append(hs, h)
+			return true
+		})
+		return sortedHandles(hs)
+	}
+
+	check := func(step string) {
+		box := AABB{Center: glm.Vec3{X: 50, Y: 50, Z: 50}, HalfExtend: glm.Vec3{X: 20, Y: 10, Z: 15}}
+		got := collect(func(f func(h AABBTreeHandle) bool) { tree.QueryAABB(&box, f) })
+		if want := brute(func(fat *AABB) bool { return TestAABBAABB(fat, &box) }); !equalHandles(got, want) {
+			t.Errorf("%s: QueryAABB found %d leaves, want %d", step, len(got), len(want))
+		}
+
+		sphere := Sphere{Center: glm.Vec3{X: 30, Y: 60, Z: 40}, Radius: 15}
+		got = collect(func(f func(h AABBTreeHandle) bool) { tree.QuerySphere(&sphere, f) })
+		if want := brute(func(fat *AABB) bool { return TestAABBSphere(fat, &sphere) }); !equalHandles(got, want) {
+			t.Errorf("%s: QuerySphere found %d leaves, want %d", step, len(got), len(want))
+		}
+
+		var frustum Frustum
+		FrustumFromPerspective(math.Pi/4, 1, 1, 100, &frustum)
+		view := glm.LookAtV(&glm.Vec3{X: 50, Y: 50, Z: -20}, &glm.Vec3{X: 50, Y: 50, Z: 50}, &glm.Vec3{X: 0, Y: 1, Z: 0})
+		got = collect(func(f func(h AABBTreeHandle) bool) { tree.QueryFrustum(&frustum, &view, f) })
+		if want := brute(func(fat *AABB) bool { return TestAABBFrustum(fat, &frustum, &view) }); !equalHandles(got, want) || len(got) == 0 {
+			t.Errorf("%s: QueryFrustum found %d leaves, want %d", step, len(got), len(want))
+		}
+
+		// the planes skipped for the children don't change what a leaf tests
+		// alone against every plane.
+		proj := glm.Perspective(math.Pi/4, 1, 1, 100)
+		viewProj := proj.Mul4(&view)
+		world := FrustumFromMatrix(&viewProj)
+		got = collect(func(f func(h AABBTreeHandle) bool) {
+			tree.CullFrustum(&world, func(h AABBTreeHandle, r CullResult) bool {
+				fat := tree.FatBounds(h)
+				if want, _ := CullFrustumAABB(&world, &fat, FrustumAllPlanes); r != want {
+					t.Errorf("%s: CullFrustum result of %d = %d, want %d", step, h, r, want)
+				}
+				return f(h)
+			})
+		})
+		if want := brute(func(fat *AABB) bool {
+			r, _ := CullFrustumAABB(&world, fat, FrustumAllPlanes)
+			return r != CullOutside
+		}); !equalHandles(got, want) || len(got) == 0 {
+			t.Errorf("%s: CullFrustum found %d leaves, want %d", step, len(got), len(want))
+		}
+
+		p, d := glm.Vec3{X: -10, Y: 40, Z: 45}, glm.Vec3{X: 1, Y: 0.1, Z: 0.05}
+		got = collect(func(f func(h AABBTreeHandle) bool) {
+			tree.RayCast(&p, &d, 100, func(h AABBTreeHandle) float32 {
+				f(h)
+				return 100
+			})
+		})
+		want := brute(func(fat *AABB) bool {
+			hit, _, ok := IntersectRayAABB(&p, &d, fat)
+			return ok && hit <= 100
+		})
+		if !equalHandles(got, want) {
+			t.Errorf("%s: RayCast found %d leaves, want %d", step, len(got), len(want))
+		}
+	}
+	check("insert")
+
+	// move everything a little, only the ones leaving their fat bounds move
+	// in the tree.
+	var moved int
+	for _, h := range handles {
+		b := bounds[h]
+		b.Center.X += r.Float32()*2 - 1
+		bounds[h] = b
+		if tree.Update(h, &b) {
+			moved++
+		}
+	}
+	if moved == 0 || moved == len(handles) {
+		t.Errorf("Update moved %d of %d leaves", moved, len(handles))
+	}
+	checkAABBTree(t, tree)
+	check("update")
+
+	for _, h := range handles[:250] {
+		tree.Remove(h)
+		tree.Remove(h)
+		delete(bounds, h)
+	}
+	if tree.Len() != 250 || tree.Payload(handles[0]) != nil {
+		t.Errorf("Len() = %d after removing half, want 250", tree.Len())
+	}
+	checkAABBTree(t, tree)
+	check("remove")
+
+	for _, h := range handles[250:] {
+		tree.Remove(h)
+	}
+	checkAABBTree(t, tree)
+	if tree.Len() != 0 || tree.Height() != 0 {
+		t.Errorf("Len(), Height() = %d, %d, want 0, 0", tree.Len(), tree.Height())
+	}
+	tree.QueryAABB(&AABB{HalfExtend: glm.Vec3{X: 1000, Y: 1000, Z: 1000}}, func(h AABBTreeHandle) bool {
+		t.Errorf("empty tree found %d", h)
+		return true
+	})
+}
+
+func TestAABBTree_ZeroValue(t *testing.T) {
+	var tree AABBTree
+	b := AABB{HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}
+	h := tree.Insert(&b, "a")
+	if tree.Payload(h) != "a" || tree.FatBounds(h) != b {
+		t.Errorf("Payload, FatBounds = %v, %v", tree.Payload(h), tree.FatBounds(h))
+	}
+}
+
+func TestAABBTree_RayCastClosest(t *testing.T) {
+	tree := NewAABBTree(0)
+	for i := 0; i < 10; i++ {
+		b := AABB{Center: glm.Vec3{X: float32(i) * 3, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}
+		tree.Insert(&b, i)
+	}
+	p, d := glm.Vec3{X: 100, Y: 0, Z: 0}, glm.Vec3{X: -1, Y: 0, Z: 0}
+	closest, best := -1, float32(math.MaxFloat32)
+	tree.RayCast(&p, &d, best, func(h AABBTreeHandle) float32 {
+		fat := tree.FatBounds(h)
+		if hit, _, ok := IntersectRayAABB(&p, &d, &fat); ok && hit < best {
+			best, closest = hit, tree.Payload(h).(int)
+		}
+		return best
+	})
+	if closest != 9 || best != 72 {
+		t.Errorf("closest = %d at %v, want 9 at 72", closest, best)
+	}
+
+	var calls int
+	tree.RayCast(&p, &d, 1000, func(h AABBTreeHandle) float32 {
+		calls++
+		return -1
+	})
+	if calls != 1 {
+		t.Errorf("RayCast called f %d times after it returned -1, want 1", calls)
+	}
+}
+
+func TestAABBTree_QueryTree(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	a, b := NewAABBTree(0.1), NewAABBTree(0.1)
+	for i := 0; i < 200; i++ {
+		ba, bb := randomAABB(r), randomAABB(r)
+		a.Insert(&ba, i)
+		b.Insert(&bb, i)
+	}
+	type pair [2]AABBTreeHandle
+	bruteForce := func(t0, t1 *AABBTree) map[pair]bool {
+		pairs := make(map[pair]bool)
+		for i := range t0.nodes {
+			for j := range t1.nodes {
+				if t0.nodes[i].height != 0 || t1.nodes[j].height != 0 || t0 == t1 && j <= i {
+					continue
+				}
+				if TestAABBAABB(&t0.nodes[i].bounds, &t1.nodes[j].bounds) {
+					pairs[pair{AABBTreeHandle(i), AABBTreeHandle(j)}] = true
+				}
+			}
+		}
+		return pairs
+	}
+
+	for _, trees := range [][2]*AABBTree{{a, b}, {a, a}} {
+		want := bruteForce(trees[0], trees[1])
+		got := make(map[pair]bool)
+		trees[0].QueryTree(trees[1], func(h0, h1 AABBTreeHandle) bool {
+			if got[pair{h0, h1}] {
+				t.Errorf("pair %d %d given twice", h0, h1)
+			}
+			got[pair{h0, h1}] = true
+			return true
+		})
+		if len(want) == 0 || len(got) != len(want) {
+			t.Errorf("QueryTree found %d pairs, want %d", len(got), len(want))
+		}
+		for p := range want {
+			if !got[p] {
+				t.Errorf("QueryTree missed %v", p)
+			}
+		}
+	}
+}
+
+func BenchmarkAABBTree_Update(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	tree := NewAABBTree(0.5)
+	var handles []AABBTreeHandle
+	var bounds []AABB
+	for i := 0; i < 1000; i++ {
+		box := randomAABB(r)
+		handles = append(handles, tree.Insert(&box, i))
+		bounds = append(bounds, box)
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i, h := range handles {
+			bounds[i].Center.X += r.Float32()*0.2 - 0.1
+			tree.Update(h, &bounds[i])
+		}
+	}
+}
+
+func BenchmarkAABBTree_QueryAABB(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	tree := NewAABBTree(0.5)
+	for i := 0; i < 1000; i++ {
+		box := randomAABB(r)
+		tree.Insert(&box, i)
+	}
+	box := AABB{Center: glm.Vec3{X: 50, Y: 50, Z: 50}, HalfExtend: glm.Vec3{X: 5, Y: 5, Z: 5}}
+	var found int
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		tree.QueryAABB(&box, func(h AABBTreeHandle) bool {
+			found++
+			return true
+		})
+	}
+}
@@ -1,10 +1,9 @@
 package geo
 
 import (
-//	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/glm"
 )
 
-/*
 // Supporter return the vertex that is the most in the given direction.
 type Supporter interface {
 	Support(*glm.Vec3) glm.Vec3
@@ -13,73 +12,255 @@ type Supporter interface {
 // Broadphase is an spacial sorting algorithm used to improve performance of
 // the narrow phase.
 type Broadphase interface {
-	// Insert the given object in the search space.
-	Insert(Supporter)
+	// Insert the given object in the search space and returns its handle.
+	Insert(Supporter) SAPHandle
+
 	// Remove the object from the search space.
-	Remove()
+	Remove(SAPHandle)
 
 	// Update the search space (called between frames)
 	Update()
 }
 
+// SAPHandle identifies an object inserted in a SAP. Handles of removed objects
+// are reused by the next inserts.
+type SAPHandle int
+
 // SAP (a.k.a. Sweep And Prune) is an algorithm that sorts elements on a set of
 // orthogonal axis
 // [Baraff92], [Cohen95]
+//
+// The bounds of every object are found with their support points along the 3
+// axis. Objects usually move little between frames so Update only does a few
+// swaps to keep the axis sorted.
 type SAP struct {
 	axis [3][]sapNode
+
+	objects []sapObject
+	free    []SAPHandle
+
+	// scratch memory for the sweep.
+	active []SAPHandle
+}
+
+var _ Broadphase = &SAP{}
+
+type sapObject struct {
+	elem     Supporter
+	min, max glm.Vec3
+	alive    bool
 }
 
 type sapNode struct {
-	// elem is a pointer to the object.
-	elem Supporter
+	// handle is the object this node belongs to.
+	handle SAPHandle
+	// pos is the position of this end of the object on the axis.
+	pos float32
 	// start is true if this element is the start of the object or false if it's
 	// the end.
-	pos   float32
 	start bool
 }
 
+// less returns whether n goes before m on its axis. Starts go before ends at
+// the same position so that touching objects overlap.
+func (n *sapNode) less(m *sapNode) bool {
+	return n.pos < m.pos || n.pos == m.pos && n.start && !m.start
+}
+
 var sapAxis = [3][2]glm.Vec3{
-	[2]glm.Vec3{{-1, 0, 0}, {1, 0, 0}},
-	[2]glm.Vec3{{0, -1, 0}, {0, 1, 0}},
-	[2]glm.Vec3{{0, 0, -1}, {0, 0, 1}},
+	{{X: -1, Y: 0, Z: 0}, {X: 1, Y: 0, Z: 0}},
+	{{X: 0, Y: -1, Z: 0}, {X: 0, Y: 1, Z: 0}},
+	{{X: 0, Y: 0, Z: -1}, {X: 0, Y: 0, Z: 1}},
 }
 
-// Insert the given object in the search space.
-func (s *SAP) Insert(sup Supporter) {
-	var supports [3][2]glm.Vec3
-	for n := range supports {
-		for m := range supports[n] {
-			supports[n][m] = sup.Support(&sapAxis[n][m])
+// Insert the given object in the search space and returns its handle.
+func (s *SAP) Insert(sup Supporter) SAPHandle {
+	var h SAPHandle
+	if n := len(s.free); n > 0 {
+		h = s.free[n-1]
+		s.free = s.free[:n-1]
+	} else {
+		h = SAPHandle(len(s.objects))
+		s.objects = append(s.objects, sapObject{})
+	}
+	o := &s.objects[h]
+	*o = sapObject{elem: sup, alive: true}
+	o.updateBounds()
+
+	for n := range s.axis {
+		s.axis[n] = append(s.axis[n],
+			sapNode{handle: h, pos: *o.min.I(n), start: true},
+			sapNode{handle: h, pos: *o.max.I(n), start: false},
+		)
+		// the new nodes are at the end, sink them into place.
+		insertionSort(s.axis[n], len(s.axis[n])-2)
+	}
+	return h
+}
+
+// Remove the object from the search space. Removing an object twice does
+// nothing.
+func (s *SAP) Remove(h SAPHandle) {
+	if !s.valid(h) {
+		return
+	}
+	for n := range s.axis {
+		nodes := s.axis[n][:0]
+		for _, node := range s.axis[n] {
+			if node.handle != h {
+				nodes = append(nodes, node)
+			}
 		}
+		s.axis[n] = nodes
+	}
+	s.objects[h] = sapObject{}
+	s.free = append(s.free, h)
+}
+
+// Supporter returns the object of the given handle, or nil if it was removed.
+func (s *SAP) Supporter(h SAPHandle) Supporter {
+	if !s.valid(h) {
+		return nil
 	}
+	return s.objects[h].elem
 }
 
-// Remove the object from the search space.
-func (s *SAP) Remove() {}
+// Len returns how many objects are in the search space.
+func (s *SAP) Len() int {
+	return len(s.objects) - len(s.free)
+}
 
-// Update the search space (called between frames)
+// Update the search space (called between frames). The bounds of every object
+// are computed again from their support points.
 func (s *SAP) Update() {
-	for n := 0; n < 3; n++ {
-		for m := 0; m < len(s.axis[n])-1; m++ {
-			var i int
-			if !s.axis[n][m].start {
-				i = 1
+	for h := range s.objects {
+		if s.objects[h].alive {
+			s.objects[h].updateBounds()
+		}
+	}
+	for n := range s.axis {
+		nodes := s.axis[n]
+		for m := range nodes {
+			o := &s.objects[nodes[m].handle]
+			if nodes[m].start {
+				nodes[m].pos = *o.min.I(n)
+			} else {
+				nodes[m].pos = *o.max.I(n)
 			}
-			s.axis[n][m].pos = s.axis[n][m].elem.Support(&sapAxis[n][i]).I(n)
 		}
+		// This is *technically O(n2) sorting, but temporal coherence should be
+		// this almost O(n).
+		insertionSort(nodes, 1)
 	}
+}
 
-	// This is *technically O(n2) sorting, but temporal coherence should be this
-	// almost O(n).
-	for n := 0; n < 3; n++ {
-		var m int
-		for m < len(s.axis[n])-1 {
-			if s.axis[n][m].pos < s.axis[n][m+1].pos {
-				m++
-				continue
+// ForEachPair calls f with every pair of objects whose bounds overlap. The
+// sweep goes along the axis on which the objects are the most spread out, a is
+// the object that starts first on that axis.
+func (s *SAP) ForEachPair(f func(a, b SAPHandle)) {
+	active := s.active[:0]
+	for _, node := range s.axis[s.sweepAxis()] {
+		if !node.start {
+			for i, h := range active {
+				if h == node.handle {
+					copy(active[i:], active[i+1:])
+					active = active[:len(active)-1]
+					break
+				}
+			}
+			continue
+		}
+		o := &s.objects[node.handle]
+		for _, h := range active {
+			if s.objects[h].overlaps(o) {
+				f(h, node.handle)
 			}
-			s.axis[n][m], s.axis[n][m+1] = s.axis[n][m+1], s.axis[n][m]
-			m-- // in case this needs to move more then 1 position.
 		}
+		active = append(active, node.handle)
 	}
-}*/
+	s.active = active[:0]
+}
+
+// Pairs appends every pair of objects whose bounds overlap to dst and returns
+// the extended slice.
+func (s *SAP) Pairs(dst [][2]SAPHandle) [][2]SAPHandle {
+	s.ForEachPair(func(a, b SAPHandle) {
+		dst = append(dst, [2]SAPHandle{a, b})
+	})
+	return dst
+}
+
+// Bounds returns the bounds of the object of the given handle as of the last
+// Insert or Update.
+func (s *SAP) Bounds(h SAPHandle) AABB {
+	if !s.valid(h) {
+		return AABB{}
+	}
+	o := &s.objects[h]
+	center := o.min.Add(&o.max)
+	extend := o.max.Sub(&o.min)
+	return AABB{
+		Center:     center.Mul(0.5),
+		HalfExtend: extend.Mul(0.5),
+	}
+}
+
+// sweepAxis returns the axis on which the centers of the objects have the
+// largest variance, it's the one where the fewest objects overlap.
+func (s *SAP) sweepAxis() int {
+	var sum, sum2 glm.Vec3
+	for h := range s.objects {
+		o := &s.objects[h]
+		if !o.alive {
+			continue
+		}
+		c := o.min.Add(&o.max)
+		sum.AddWith(&c)
+		sum2.AddWith(&glm.Vec3{X: c.X * c.X, Y: c.Y * c.Y, Z: c.Z * c.Z})
+	}
+	n := float32(s.Len())
+	var axis int
+	var best float32 = -1
+	for i := 0; i < 3; i++ {
+		// the centers are doubled, it doesn't change which axis wins.
+		mean := *sum.I(i) / n
+		if v := *sum2.I(i)/n - mean*mean; v > best {
+			axis, best = i, v
+		}
+	}
+	return axis
+}
+
+// valid returns whether h is the handle of an object in the search space.
+func (s *SAP) valid(h SAPHandle) bool {
+	return h >= 0 && int(h) < len(s.objects) && s.objects[h].alive
+}
+
+// updateBounds computes the bounds of the object from its support points.
+func (o *sapObject) updateBounds() {
+	for n := range sapAxis {
+		lo := o.elem.Support(&sapAxis[n][0])
+		hi := o.elem.Support(&sapAxis[n][1])
+		*o.min.I(n) = *lo.I(n)
+		*o.max.I(n) = *hi.I(n)
+	}
+}
+
+// overlaps returns whether the bounds of o and p overlap.
+func (o *sapObject) overlaps(p *sapObject) bool {
+	return o.min.X <= p.max.X && p.min.X <= o.max.X &&
+		o.min.Y <= p.max.Y && p.min.Y <= o.max.Y &&
+		o.min.Z <= p.max.Z && p.min.Z <= o.max.Z
+}
+
+// insertionSort sorts nodes assuming nodes[:from] is already sorted.
+func insertionSort(nodes []sapNode, from int) {
+	for i := from; i < len(nodes); i++ {
+		n := nodes[i]
+		j := i - 1
+		for ; j >= 0 && n.less(&nodes[j]); j-- {
+			nodes[j+1] = nodes[j]
+		}
+		nodes[j+1] = n
+	}
+}
@@ -0,0 +1,159 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// bruteForcePairs returns every pair of live objects of s whose bounds
+// overlap, sorted.
+func bruteForcePairs(s *SAP, handles []SAPHandle) [][2]SAPHandle {
+	var pairs [][2]SAPHandle
+	for i, a := range handles {
+		for _, b := range handles[i+1:] {
+			ba, bb := s.Bounds(a), s.Bounds(b)
+			if TestAABBAABB(&ba, &bb) {
+				pairs = append(pairs, sortedPair(a, b))
+			}
+		}
+	}
+	sortPairs(pairs)
+	return pairs
+}
+
+func sortedPair(a, b SAPHandle) [2]SAPHandle {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]SAPHandle{a, b}
+}
+
+func sortPairs(pairs [][2]SAPHandle) {
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i][0] < pairs[j][0] || pairs[i][0] == pairs[j][0] && pairs[i][1] < pairs[j][1]
+	})
+}
+
+// sapPairs returns the pairs of s, sorted.
+func sapPairs(s *SAP) [][2]SAPHandle {
+	pairs := s.Pairs(nil)
+	for i := range pairs {
+		pairs[i] = sortedPair(pairs[i][0], pairs[i][1])
+	}
+	sortPairs(pairs)
+	return pairs
+}
+
+func equalPairs(a, b [][2]SAPHandle) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSAP(t *testing.T) {
+	var s SAP
+	a := &Sphere{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, Radius: 1}
+	b := &AABB{Center: glm.Vec3{X: 1.5, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}
+	c := &Sphere{Center: glm.Vec3{X: 10, Y: 0, Z: 0}, Radius: 1}
+
+	ha, hb, hc := s.Insert(a), s.Insert(b), s.Insert(c)
+	if s.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", s.Len())
+	}
+	if got, want := sapPairs(&s), [][2]SAPHandle{{ha, hb}}; !equalPairs(got, want) {
+		t.Errorf("pairs = %v, want %v", got, want)
+	}
+	if bounds := s.Bounds(hc); bounds != (AABB{Center: c.Center, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}) {
+		t.Errorf("Bounds(c) = %v", bounds)
+	}
+
+	// moving the objects is only seen after Update.
+	c.Center.X = 2.5
+	if got, want := sapPairs(&s), [][2]SAPHandle{{ha, hb}}; !equalPairs(got, want) {
+		t.Errorf("pairs before Update = %v, want %v", got, want)
+	}
+	s.Update()
+	if got, want := sapPairs(&s), [][2]SAPHandle{{ha, hb}, {hb, hc}}; !equalPairs(got, want) {
+		t.Errorf("pairs = %v, want %v", got, want)
+	}
+
+	s.Remove(hb)
+	s.Remove(hb)
+	if s.Len() != 2 || s.Supporter(hb) != nil {
+		t.Errorf("Len(), Supporter(b) = %d, %v, want 2, nil", s.Len(), s.Supporter(hb))
+	}
+	if got := sapPairs(&s); len(got) != 0 {
+		t.Errorf("pairs = %v, want none", got)
+	}
+
+	// the handle of b is reused.
+	d := &AABB{Center: glm.Vec3{X: 1, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 0.1, Y: 0.1, Z: 0.1}}
+	if hd := s.Insert(d); hd != hb || s.Supporter(hd) != d {
+		t.Errorf("Insert(d) = %d, want the handle of b %d", hd, hb)
+	}
+	if got, want := sapPairs(&s), [][2]SAPHandle{{ha, hb}}; !equalPairs(got, want) {
+		t.Errorf("pairs = %v, want %v", got, want)
+	}
+}
+
+func TestSAP_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := func() glm.Vec3 {
+		return glm.Vec3{X: r.Float32() * 20, Y: r.Float32() * 20, Z: r.Float32() * 4}
+	}
+
+	var s SAP
+	var handles []SAPHandle
+	var spheres []*Sphere
+	for i := 0; i < 200; i++ {
+		sphere := &Sphere{Center: random(), Radius: r.Float32()}
+		spheres = append(spheres, sphere)
+		handles = append(handles, s.Insert(sphere))
+	}
+
+	for frame := 0; frame < 10; frame++ {
+		for _, sphere := range spheres {
+			sphere.Center.X += r.Float32() - 0.5
+			sphere.Center.Y += r.Float32() - 0.5
+		}
+		s.Update()
+		for n := range s.axis {
+			for m := 1; m < len(s.axis[n]); m++ {
+				if s.axis[n][m].less(&s.axis[n][m-1]) {
+					t.Fatalf("frame %d: axis %d isn't sorted", frame, n)
+				}
+			}
+		}
+		if got, want := sapPairs(&s), bruteForcePairs(&s, handles); !equalPairs(got, want) {
+			t.Errorf("frame %d: %d pairs, want %d", frame, len(got), len(want))
+		}
+	}
+}
+
+func BenchmarkSAP_Update(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	var s SAP
+	var spheres []*Sphere
+	for i := 0; i < 1000; i++ {
+		sphere := &Sphere{Center: glm.Vec3{X: r.Float32() * 100, Y: r.Float32() * 100, Z: r.Float32() * 100}, Radius: 1}
+		spheres = append(spheres, sphere)
+		s.Insert(sphere)
+	}
+	var pairs [][2]SAPHandle
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, sphere := range spheres {
+			sphere.Center.X += r.Float32()*0.1 - 0.05
+		}
+		s.Update()
+		pairs = s.Pairs(pairs[:0])
+	}
+}
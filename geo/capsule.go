@@ -35,6 +35,21 @@ func (c *Capsule) InertiaTensor(density float32) glm.Mat3 {
 	return tensors.Capsule(c.Mass(density), c.Radius, d.Len())
 }
 
+// Support returns the point of the capsule that is the most in the given
+// direction. The direction doesn't need to be normalized.
+func (c *Capsule) Support(direction *glm.Vec3) glm.Vec3 {
+	end := c.A
+	if c.B.Dot(direction) > c.A.Dot(direction) {
+		end = c.B
+	}
+	l := direction.Len()
+	if l == 0 {
+		return end
+	}
+	end.AddScaledVec(c.Radius/l, direction)
+	return end
+}
+
 // TestCapsuleCapsule returns true if these Capsules overlap.
 func TestCapsuleCapsule(a, b *Capsule) bool {
 	_, _, u, _, _ := ClosestPointSegmentSegment(&a.A, &a.B, &b.A, &b.B)
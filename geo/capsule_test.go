@@ -45,3 +45,20 @@ func TestTestCapsuleSphere(t *testing.T) {
 		}
 	}
 }
+
+func TestCapsule_Support(t *testing.T) {
+	c := Capsule{A: glm.Vec3{X: 0, Y: -1, Z: 0}, B: glm.Vec3{X: 0, Y: 1, Z: 0}, Radius: 0.5}
+	tests := []struct {
+		direction, support glm.Vec3
+	}{
+		{glm.Vec3{X: 0, Y: 2, Z: 0}, glm.Vec3{X: 0, Y: 1.5, Z: 0}},
+		{glm.Vec3{X: 0, Y: -1, Z: 0}, glm.Vec3{X: 0, Y: -1.5, Z: 0}},
+		{glm.Vec3{X: 3, Y: 4, Z: 0}, glm.Vec3{X: 0.3, Y: 1.4, Z: 0}},
+		{glm.Vec3{}, glm.Vec3{X: 0, Y: -1, Z: 0}},
+	}
+	for i, test := range tests {
+		if support := c.Support(&test.direction); !support.EqualThreshold(&test.support, 1e-6) {
+			t.Errorf("[%d] support = %v, want %v", i, support, test.support)
+		}
+	}
+}
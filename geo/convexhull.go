@@ -183,36 +183,79 @@ func (c *Convexhull) supportSlow(axis *glm.Vec3) glm.Vec3 {
 }
 
 // Support returns the vertex that is the most in the direction of the given
-// axis.
+// axis. cache is the triangle returned by the previous call, or nil, the search
+// starts from there.
 func (c *Convexhull) Support(axis *glm.Vec3, cache *HullTriangle) (glm.Vec3, *HullTriangle) {
 	if cache == nil {
 		cache = &c.Triangles[0]
 	}
 	var max float32 = -math.MaxFloat32
-	var vertex glm.Vec3
+	var vertex *glm.Vec3
 	// start with the cache
 	for n := 0; n < 3; n++ {
 		if dist := axis.Dot(cache.Vertices[n]); dist > max {
 			max = dist
-			vertex = *cache.Vertices[n]
+			vertex = cache.Vertices[n]
 		}
 	}
+	// Climb from vertex to vertex. The neighbours of a vertex are the vertices
+	// of the fan of triangles around it, on a convex hull the climb stops at
+	// the support point.
 	for {
-	loop:
-		for n := 0; n < 3; n++ {
-			for m := 0; m < 3; m++ {
-				if dist := axis.Dot(cache.Adjacent[n].Vertices[m]); dist > max {
+		next, nextTriangle := vertex, cache
+		var prev *HullTriangle
+		for t, steps := cache, 0; t != nil && steps < len(c.Triangles); steps++ {
+			for n := 0; n < 3; n++ {
+				if dist := axis.Dot(t.Vertices[n]); dist > max {
 					max = dist
-					vertex = *cache.Adjacent[n].Vertices[m]
-					cache = cache.Adjacent[n]
-					continue loop
+					next, nextTriangle = t.Vertices[n], t
+				}
+			}
+			// go to the next triangle around the vertex.
+			var adjacent *HullTriangle
+			for n := 0; n < 3; n++ {
+				if a := t.Adjacent[n]; a != nil && a != prev && a.hasVertex(vertex) {
+					adjacent = a
+					break
 				}
 			}
+			if adjacent == cache {
+				break
+			}
+			prev, t = t, adjacent
 		}
-		return vertex, cache
+		if next == vertex {
+			return *vertex, cache
+		}
+		vertex, cache = next, nextTriangle
 	}
 }
 
+// hasVertex returns whether v is one of the vertices of the triangle.
+func (t *HullTriangle) hasVertex(v *glm.Vec3) bool {
+	return t.Vertices[0] == v || t.Vertices[1] == v || t.Vertices[2] == v
+}
+
+// Supporter returns a Supporter for this hull. It doesn't allocate and can be
+// shared between goroutines.
+func (c *Convexhull) Supporter() Supporter {
+	return hullSupporter{hull: c}
+}
+
+// hullSupporter adapts the support function of a convex hull to the Supporter
+// interface. It's a single pointer so storing it in an interface doesn't
+// allocate, that's also why it can't keep the triangle of the last search.
+type hullSupporter struct {
+	hull *Convexhull
+}
+
+// Support returns the vertex of the hull that is the most in the given
+// direction.
+func (s hullSupporter) Support(direction *glm.Vec3) glm.Vec3 {
+	v, _ := s.hull.Support(direction, nil)
+	return v
+}
+
 // writeWavefront writes the faces to a writer as the .obj format.
 // Import in blender with -Z forward and Y up.
 func writeWavefront(writer io.Writer, hull *Convexhull) {
@@ -233,69 +276,25 @@ func writeWavefront(writer io.Writer, hull *Convexhull) {
 	fmt.Fprint(writer, facebuf.String())
 }
 
-//	function GJK_intersection(shape p, shape q, vector initial_axis):
-//       vector  A = Support(p, initial_axis) - Support(q, -initial_axis)
-//       simplex s = {A}
-//       vector  D = -A
-//       loop:
-//           A = Support(p, D) - Support(q, -D)
-//           if dot(A, D) < 0:
-//              reject
-//           s = s ∪ A
-//           s, D, contains_origin = NearestSimplex(s)
-//           if contains_origin:
-//              accept
-
 // TestConvexhullConvexhull tests wether 2 convex hull intersects using GJK.
 func TestConvexhullConvexhull(c0, c1 *Convexhull) bool {
-	initial := c1.Center.Sub(&c0.Center)
-	iinitial := initial.Inverse()
-	s0, cache0 := c0.Support(&initial, nil)
-	s1, cache1 := c1.Support(&iinitial, nil)
-	A := s0.Sub(&s1)
-	var s Simplex
-	s.Merge(&A)
-	D := A.Inverse()
-	for {
-		iD := D.Inverse()
-		s0, cache0 = c0.Support(&D, cache0)
-		s1, cache1 = c1.Support(&iD, cache1)
-		A = s0.Sub(&s1)
-		if A.Dot(&D) < 0 {
-			return false
-		}
-		s.Merge(&A)
-		var contain bool
-		D, contain = s.NearestToOrigin()
-		if contain {
-			return true
-		}
-	}
+	return TestGJK(c0.Supporter(), c1.Supporter())
 }
 
-// testConvexhullConvexhullSlow tests wether 2 convex hull intersects using GJK.
+// testConvexhullConvexhullSlow tests wether 2 convex hull intersects using GJK
+// and the brute force support function.
 func testConvexhullConvexhullSlow(c0, c1 *Convexhull) bool {
-	initial := c1.Center.Sub(&c0.Center)
-	iinitial := initial.Inverse()
-	s0 := c0.supportSlow(&initial)
-	s1 := c1.supportSlow(&iinitial)
-	A := s0.Sub(&s1)
-	var s Simplex
-	s.Merge(&A)
-	D := A.Inverse()
-	for {
-		iD := D.Inverse()
-		s0 = c0.supportSlow(&D)
-		s1 = c1.supportSlow(&iD)
-		A = s0.Sub(&s1)
-		if A.Dot(&D) < 0 {
-			return false
-		}
-		s.Merge(&A)
-		var contain bool
-		D, contain = s.NearestToOrigin()
-		if contain {
-			return true
-		}
-	}
+	return TestGJK(slowHullSupporter{hull: c0}, slowHullSupporter{hull: c1})
+}
+
+// slowHullSupporter is a Supporter using the brute force support function of
+// the hull.
+type slowHullSupporter struct {
+	hull *Convexhull
+}
+
+// Support returns the vertex of the hull that is the most in the given
+// direction.
+func (s slowHullSupporter) Support(direction *glm.Vec3) glm.Vec3 {
+	return s.hull.supportSlow(direction)
 }
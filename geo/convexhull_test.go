@@ -158,3 +158,27 @@ func BenchmarkTestConvexhullConvexhullSlow(b *testing.B) {
 		testConvexhullConvexhullSlow(c0, c1)
 	}
 }
+
+func TestConvexhull_SupportCube(t *testing.T) {
+	hull := cubeHull(glm.Vec3{}, 1)
+	for _, d := range []glm.Vec3{
+		{X: 1, Y: 0.1, Z: 0.1}, {X: -1, Y: 0.1, Z: 0.1}, {X: 0.1, Y: 1, Z: -0.1},
+		{X: 0.1, Y: 0.2, Z: 1}, {X: -0.3, Y: -0.2, Z: -1}, {X: 1, Y: 1, Z: 1},
+	} {
+		support, _ := hull.Support(&d, nil)
+		if want := hull.supportSlow(&d); support != want {
+			t.Errorf("Support(%v) = %v, want %v", d, support, want)
+		}
+	}
+}
+
+func TestConvexhull_SupporterAllocs(t *testing.T) {
+	hull := cubeHull(glm.Vec3{}, 1)
+	var s Supporter
+	if allocs := testing.AllocsPerRun(100, func() { s = hull.Supporter() }); allocs != 0 {
+		t.Errorf("hull.Supporter allocated %v times per run, want 0", allocs)
+	}
+	if v := s.Support(&glm.Vec3{X: 1, Y: 1, Z: 1}); v != (glm.Vec3{X: 1, Y: 1, Z: 1}) {
+		t.Errorf("Support = %v, want {1, 1, 1}", v)
+	}
+}
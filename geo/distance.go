@@ -0,0 +1,106 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+)
+
+// DistanceShapeShape returns the distance between 2 shapes and the points of
+// s0 and s1 closest to each other. When the shapes overlap the distance is 0
+// and the points shouldn't be used.
+//
+// Spheres and capsules are a point and a segment grown by their radius, the
+// distance is found between those cores. The pairs of points, segments and
+// AABB that have a closed form use it, the others use GJK.
+func DistanceShapeShape(s0, s1 Shape) (dist float32, p0, p1 glm.Vec3) {
+	c0, r0 := shapeCore(s0)
+	c1, r1 := shapeCore(s1)
+
+	l, p0, p1 := coreDistance(c0, c1)
+	if l <= r0+r1 {
+		return 0, p0, p1
+	}
+	d := p1.Sub(&p0)
+	p0.AddScaledVec(r0/l, &d)
+	p1.AddScaledVec(-r1/l, &d)
+	return l - r0 - r1, p0, p1
+}
+
+// coreDistance returns the distance between 2 shape cores and their closest
+// points.
+func coreDistance(c0, c1 Shape) (dist float32, p0, p1 glm.Vec3) {
+	p0, p1, ok := closestPointsCores(c0, c1)
+	if !ok {
+		p1, p0, ok = closestPointsCores(c1, c0)
+	}
+	if !ok {
+		return GJKDistance(ShapeSupporter(c0), ShapeSupporter(c1))
+	}
+	d := p1.Sub(&p0)
+	return d.Len(), p0, p1
+}
+
+// shapeCore returns the shape without its radius and the radius. The core of a
+// sphere is a point and the core of a capsule is a segment, the other shapes
+// are their own core.
+func shapeCore(s Shape) (Shape, float32) {
+	switch s := s.(type) {
+	case *Sphere:
+		return &Sphere{Center: s.Center}, s.Radius
+	case *Capsule:
+		return &Capsule{A: s.A, B: s.B}, s.Radius
+	}
+	return s, 0
+}
+
+// closestPointsCores returns the closest points of 2 shape cores if the pair
+// has a closed form solution.
+func closestPointsCores(s0, s1 Shape) (p0, p1 glm.Vec3, ok bool) {
+	switch s0 := s0.(type) {
+	case *Sphere:
+		switch s1 := s1.(type) {
+		case *Sphere:
+			return s0.Center, s1.Center, true
+		case *AABB:
+			return s0.Center, ClosestPointPointAABB(&s0.Center, s1), true
+		case *OBB:
+			return s0.Center, ClosestPointOBBPoint(s1, &s0.Center), true
+		case *Capsule:
+			_, p1 = ClosestPointSegmentPoint(&s1.A, &s1.B, &s0.Center)
+			return s0.Center, p1, true
+		}
+	case *Capsule:
+		switch s1 := s1.(type) {
+		case *Capsule:
+			_, _, _, p0, p1 = ClosestPointSegmentSegment(&s0.A, &s0.B, &s1.A, &s1.B)
+			return p0, p1, true
+		}
+	case *AABB:
+		switch s1 := s1.(type) {
+		case *AABB:
+			p0, p1 = closestPointsAABBAABB(s0, s1)
+			return p0, p1, true
+		}
+	}
+	return p0, p1, false
+}
+
+// closestPointsAABBAABB returns the points of a and b closest to each other.
+// Where the boxes overlap on an axis the points are in the middle of the
+// overlap.
+func closestPointsAABBAABB(a, b *AABB) (p0, p1 glm.Vec3) {
+	for i := 0; i < 3; i++ {
+		amin, amax := *a.Center.I(i)-*a.HalfExtend.I(i), *a.Center.I(i)+*a.HalfExtend.I(i)
+		bmin, bmax := *b.Center.I(i)-*b.HalfExtend.I(i), *b.Center.I(i)+*b.HalfExtend.I(i)
+		switch {
+		case amax < bmin:
+			*p0.I(i), *p1.I(i) = amax, bmin
+		case bmax < amin:
+			*p0.I(i), *p1.I(i) = amin, bmax
+		default:
+			m := (math.Max(amin, bmin) + math.Min(amax, bmax)) / 2
+			*p0.I(i), *p1.I(i) = m, m
+		}
+	}
+	return p0, p1
+}
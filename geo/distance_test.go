@@ -0,0 +1,160 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"math/rand"
+	"testing"
+)
+
+func TestDistanceShapeShape(t *testing.T) {
+	ident := glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}
+	// rotated 45 degrees around z.
+	const h = 0.70710678
+	rotz := glm.Mat3{h, h, 0, -h, h, 0, 0, 0, 1}
+	tests := []struct {
+		s0, s1 Shape
+		dist   float32
+		p0, p1 glm.Vec3
+	}{
+		{ // 0
+			&Sphere{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, Radius: 1},
+			&Sphere{Center: glm.Vec3{X: 5, Y: 0, Z: 0}, Radius: 2},
+			2,
+			glm.Vec3{X: 1, Y: 0, Z: 0}, glm.Vec3{X: 3, Y: 0, Z: 0},
+		},
+		{ // 1
+			&Sphere{Center: glm.Vec3{X: 0, Y: 5, Z: 0}, Radius: 1},
+			&AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			3,
+			glm.Vec3{X: 0, Y: 4, Z: 0}, glm.Vec3{X: 0, Y: 1, Z: 0},
+		},
+		{ // 2
+			&AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&AABB{Center: glm.Vec3{X: 4, Y: 0.5, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			2,
+			glm.Vec3{X: 1, Y: 0.25, Z: 0}, glm.Vec3{X: 3, Y: 0.25, Z: 0},
+		},
+		{ // 3
+			&Capsule{A: glm.Vec3{X: -1, Y: 0, Z: 0}, B: glm.Vec3{X: 1, Y: 0, Z: 0}, Radius: 0.5},
+			&Capsule{A: glm.Vec3{X: 0, Y: 3, Z: -1}, B: glm.Vec3{X: 0, Y: 3, Z: 1}, Radius: 0.5},
+			2,
+			glm.Vec3{X: 0, Y: 0.5, Z: 0}, glm.Vec3{X: 0, Y: 2.5, Z: 0},
+		},
+		{ // 4
+			&Capsule{A: glm.Vec3{X: 0, Y: 2, Z: 0}, B: glm.Vec3{X: 0, Y: 4, Z: 0}, Radius: 0.5},
+			&Sphere{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, Radius: 1},
+			0.5,
+			glm.Vec3{X: 0, Y: 1.5, Z: 0}, glm.Vec3{X: 0, Y: 1, Z: 0},
+		},
+		{ // 5
+			&OBB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, Orientation: ident, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&OBB{Center: glm.Vec3{X: 4, Y: 0, Z: 0}, Orientation: rotz, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			3 - math.Sqrt(2),
+			glm.Vec3{X: 1, Y: 0, Z: 0}, glm.Vec3{X: 4 - math.Sqrt(2), Y: 0, Z: 0},
+		},
+		{ // 6
+			cubeHull(glm.Vec3{}, 1),
+			&Capsule{A: glm.Vec3{X: 3, Y: 0, Z: 0}, B: glm.Vec3{X: 6, Y: 0, Z: 0}, Radius: 0.5},
+			1.5,
+			glm.Vec3{X: 1, Y: 0, Z: 0}, glm.Vec3{X: 2.5, Y: 0, Z: 0},
+		},
+		{ // 7
+			&Sphere{Center: glm.Vec3{X: 3, Y: 3, Z: 0}, Radius: math.Sqrt(2)},
+			cubeHull(glm.Vec3{}, 1),
+			math.Sqrt(8) - math.Sqrt(2),
+			glm.Vec3{X: 2, Y: 2, Z: 0}, glm.Vec3{X: 1, Y: 1, Z: 0},
+		},
+		{ // 8
+			&AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&OBB{Center: glm.Vec3{X: 0, Y: 0, Z: 5}, Orientation: rotz, HalfExtend: glm.Vec3{X: 0.5, Y: 0.5, Z: 1}},
+			3,
+			glm.Vec3{X: 0, Y: 0, Z: 1}, glm.Vec3{X: 0, Y: 0, Z: 4},
+		},
+	}
+	for i, test := range tests {
+		dist, p0, p1 := DistanceShapeShape(test.s0, test.s1)
+		if math.Abs(dist-test.dist) > 1e-4 {
+			t.Errorf("[%d] dist = %f, want %f", i, dist, test.dist)
+		}
+		// the points of flat faces aren't unique, only check them when they are.
+		if i != 5 && i != 8 {
+			if !p0.EqualThreshold(&test.p0, 1e-4) || !p1.EqualThreshold(&test.p1, 1e-4) {
+				t.Errorf("[%d] points = %v %v, want %v %v", i, p0, p1, test.p0, test.p1)
+			}
+		}
+		d := p1.Sub(&p0)
+		if math.Abs(d.Len()-dist) > 1e-4 {
+			t.Errorf("[%d] the points are %f apart, want %f", i, d.Len(), dist)
+		}
+
+		// swapping the shapes swaps the points.
+		rdist, r0, r1 := DistanceShapeShape(test.s1, test.s0)
+		if math.Abs(rdist-dist) > 1e-4 || !r0.EqualThreshold(&p1, 1e-4) || !r1.EqualThreshold(&p0, 1e-4) {
+			t.Errorf("[%d] swapped = %f %v %v, want %f %v %v", i, rdist, r0, r1, dist, p1, p0)
+		}
+	}
+}
+
+func TestDistanceShapeShape_Overlap(t *testing.T) {
+	ident := glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}
+	shapes := []Shape{
+		&AABB{Center: glm.Vec3{X: 0.5}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+		&Sphere{Center: glm.Vec3{Y: 0.5}, Radius: 1},
+		&OBB{Center: glm.Vec3{Z: 0.5}, Orientation: ident, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+		&Capsule{A: glm.Vec3{X: 0, Y: -1, Z: 0}, B: glm.Vec3{X: 0, Y: 1, Z: 0}, Radius: 0.5},
+		cubeHull(glm.Vec3{X: -0.5}, 1),
+	}
+	for _, a := range shapes {
+		for _, b := range shapes {
+			if dist, _, _ := DistanceShapeShape(a, b); dist != 0 {
+				t.Errorf("DistanceShapeShape(%T, %T) = %f, want 0", a, b, dist)
+			}
+		}
+	}
+}
+
+func TestGJKDistance(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	vec := func(scale float32) glm.Vec3 {
+		return glm.Vec3{
+			X: (r.Float32()*2 - 1) * scale,
+			Y: (r.Float32()*2 - 1) * scale,
+			Z: (r.Float32()*2 - 1) * scale,
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		a0 := AABB{Center: vec(5), HalfExtend: glm.Vec3{X: r.Float32() + 0.1, Y: r.Float32() + 0.1, Z: r.Float32() + 0.1}}
+		a1 := AABB{Center: vec(5), HalfExtend: glm.Vec3{X: r.Float32() + 0.1, Y: r.Float32() + 0.1, Z: r.Float32() + 0.1}}
+		p0, p1 := closestPointsAABBAABB(&a0, &a1)
+		d := p1.Sub(&p0)
+		want := d.Len()
+
+		got, q0, q1 := GJKDistance(&a0, &a1)
+		if math.Abs(got-want) > 1e-3 {
+			t.Errorf("[%d] GJKDistance(%v, %v) = %f, want %f", i, a0, a1, got, want)
+			continue
+		}
+		if want == 0 {
+			continue
+		}
+		d = q1.Sub(&q0)
+		if math.Abs(d.Len()-want) > 1e-3 {
+			t.Errorf("[%d] the points are %f apart, want %f", i, d.Len(), want)
+		}
+		if sq := SqDistPointAABB(&q0, &a0); sq > 1e-5 {
+			t.Errorf("[%d] %v isn't on %v", i, q0, a0)
+		}
+		if sq := SqDistPointAABB(&q1, &a1); sq > 1e-5 {
+			t.Errorf("[%d] %v isn't on %v", i, q1, a1)
+		}
+	}
+}
+
+func BenchmarkDistanceShapeShape(b *testing.B) {
+	hull := cubeHull(glm.Vec3{}, 1)
+	capsule := Capsule{A: glm.Vec3{X: 3, Y: 0, Z: -5}, B: glm.Vec3{X: 3, Y: 0, Z: 5}, Radius: 0.5}
+	for n := 0; n < b.N; n++ {
+		DistanceShapeShape(hull, &capsule)
+	}
+}
@@ -0,0 +1,228 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+)
+
+const (
+	// epaMaxIterations bounds the number of points added to the polytope.
+	// Curved shapes never converge exactly, they stop there.
+	epaMaxIterations = 64
+
+	// epaEpsilon is how much further than the closest face, relative to the
+	// size of the Minkowski difference, a new support point needs to be for
+	// EPA to keep going.
+	epaEpsilon = 1e-4
+)
+
+// EPA returns the penetration normal and depth of 2 overlapping convex shapes
+// described by these support functions. Moving b by depth along normal makes
+// the shapes touch, the normal points from a to b. It returns false if the
+// shapes don't overlap or if they only touch and no depth can be found.
+// [vandenBergen01]
+func EPA(a, b Supporter) (normal glm.Vec3, depth float32, ok bool) {
+	s, dist := gjk(a, b)
+	if dist != 0 {
+		return glm.Vec3{}, 0, false
+	}
+	var p polytope
+	if !p.init(a, b, &s) {
+		return glm.Vec3{}, 0, false
+	}
+	for i := 0; i < epaMaxIterations; i++ {
+		f := p.closest()
+		w := minkowskiSupport(a, b, &f.normal)
+		if w.Dot(&f.normal)-f.dist <= epaEpsilon*p.scale {
+			break
+		}
+		p.expand(&w)
+	}
+	f := p.closest()
+	return f.normal, math.Max(f.dist, 0), true
+}
+
+// polytope is the convex polytope EPA grows inside the Minkowski difference.
+// Its faces are CCW seen from outside.
+type polytope struct {
+	vertices []glm.Vec3
+	faces    []polytopeFace
+	// scale is the length of the largest vertex.
+	scale float32
+
+	// scratch memory for expand.
+	edges [][2]int
+}
+
+type polytopeFace struct {
+	a, b, c int
+	// the outward normal of the face and the distance from the origin to the
+	// plane of the face.
+	normal glm.Vec3
+	dist   float32
+}
+
+// gjkDirections are the directions searched to grow a simplex that is too
+// small into a tetrahedron.
+var gjkDirections = [6]glm.Vec3{
+	{X: 1, Y: 0, Z: 0}, {X: -1, Y: 0, Z: 0},
+	{X: 0, Y: 1, Z: 0}, {X: 0, Y: -1, Z: 0},
+	{X: 0, Y: 0, Z: 1}, {X: 0, Y: 0, Z: -1},
+}
+
+// init builds the first tetrahedron from the simplex GJK ended with. It returns
+// false if the Minkowski difference is too flat to hold one.
+func (p *polytope) init(a, b Supporter, s *gjkSimplex) bool {
+	p.vertices = p.vertices[:0]
+	for i := 0; i < s.Size; i++ {
+		p.vertices = append(p.vertices, s.Points[i])
+	}
+	if len(p.vertices) == 0 {
+		w := minkowskiSupport(a, b, &gjkDirections[0])
+		p.vertices = append(p.vertices, w)
+	}
+	p.updateScale()
+
+	// grow the simplex into a tetrahedron.
+	for len(p.vertices) < 4 {
+		if !p.grow(a, b) {
+			return false
+		}
+	}
+
+	p.faces = p.faces[:0]
+	for _, f := range [4][4]int{{0, 1, 2, 3}, {0, 3, 1, 2}, {0, 2, 3, 1}, {1, 3, 2, 0}} {
+		// the 4th vertex is behind the face.
+		a, b, c, d := &p.vertices[f[0]], &p.vertices[f[1]], &p.vertices[f[2]], &p.vertices[f[3]]
+		if PointOutsidePlane(d, a, b, c) {
+			f[1], f[2] = f[2], f[1]
+		}
+		p.addFace(f[0], f[1], f[2])
+	}
+	return true
+}
+
+// grow adds a vertex of the Minkowski difference a-b that isn't in the span of
+// the vertices of the polytope. It returns false if there is none.
+func (p *polytope) grow(a, b Supporter) bool {
+	var candidates []glm.Vec3
+	switch len(p.vertices) {
+	case 1:
+		candidates = gjkDirections[:]
+	case 2:
+		// the directions perpendicular to the segment.
+		e := p.vertices[1].Sub(&p.vertices[0])
+		for _, d := range gjkDirections {
+			candidates = append(candidates, e.Cross(&d))
+		}
+	case 3:
+		ab, ac := p.vertices[1].Sub(&p.vertices[0]), p.vertices[2].Sub(&p.vertices[0])
+		candidates = append(candidates, ab.Cross(&ac))
+	}
+	for _, d := range candidates {
+		if d.Len2() == 0 {
+			continue
+		}
+		for _, dir := range [2]glm.Vec3{d, d.Inverse()} {
+			w := minkowskiSupport(a, b, &dir)
+			if p.independent(&w) {
+				p.vertices = append(p.vertices, w)
+				p.updateScale()
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// independent returns whether w is not in the span of the vertices, so that
+// adding it makes the simplex grow.
+func (p *polytope) independent(w *glm.Vec3) bool {
+	eps := gjkEpsilon * p.scale
+	v := p.vertices
+	aw := w.Sub(&v[0])
+	switch len(v) {
+	case 1:
+		return aw.Len() > eps
+	case 2:
+		ab := v[1].Sub(&v[0])
+		c := ab.Cross(&aw)
+		return c.Len() > eps*ab.Len()
+	default:
+		ab, ac := v[1].Sub(&v[0]), v[2].Sub(&v[0])
+		n := ab.Cross(&ac)
+		return math.Abs(n.Dot(&aw)) > eps*n.Len()
+	}
+}
+
+func (p *polytope) updateScale() {
+	for i := range p.vertices {
+		if l := p.vertices[i].Len(); l > p.scale {
+			p.scale = l
+		}
+	}
+}
+
+// addFace adds the face abc to the polytope.
+func (p *polytope) addFace(a, b, c int) {
+	ab, ac := p.vertices[b].Sub(&p.vertices[a]), p.vertices[c].Sub(&p.vertices[a])
+	n := ab.Cross(&ac)
+	f := polytopeFace{a: a, b: b, c: c, dist: math.MaxFloat32}
+	// degenerate faces are never the closest.
+	if l := n.Len(); l > 0 {
+		f.normal = n.Mul(1 / l)
+		f.dist = f.normal.Dot(&p.vertices[a])
+	}
+	p.faces = append(p.faces, f)
+}
+
+// closest returns the face closest to the origin.
+func (p *polytope) closest() *polytopeFace {
+	best := &p.faces[0]
+	for i := range p.faces {
+		if p.faces[i].dist < best.dist {
+			best = &p.faces[i]
+		}
+	}
+	return best
+}
+
+// expand adds w to the polytope, removing the faces it can see and linking it
+// to the horizon they leave.
+func (p *polytope) expand(w *glm.Vec3) {
+	p.vertices = append(p.vertices, *w)
+	n := len(p.vertices) - 1
+	if l := w.Len(); l > p.scale {
+		p.scale = l
+	}
+
+	edges := p.edges[:0]
+	addEdge := func(a, b int) {
+		// an edge shared by 2 removed faces isn't on the horizon.
+		for i, e := range edges {
+			if e[0] == b && e[1] == a {
+				edges[i] = edges[len(edges)-1]
+				edges = edges[:len(edges)-1]
+				return
+			}
+		}
+		edges = append(edges, [2]int{a, b})
+	}
+
+	faces := p.faces[:0]
+	for _, f := range p.faces {
+		// degenerate faces have no normal, they are never visible.
+		if d := w.Sub(&p.vertices[f.a]); f.normal.Dot(&d) <= 0 {
+			faces = append(faces, f)
+			continue
+		}
+		addEdge(f.a, f.b)
+		addEdge(f.b, f.c)
+		addEdge(f.c, f.a)
+	}
+	p.faces = faces
+	for _, e := range edges {
+		p.addFace(e[0], e[1], n)
+	}
+	p.edges = edges
+}
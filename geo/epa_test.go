@@ -0,0 +1,68 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"testing"
+)
+
+func TestEPA(t *testing.T) {
+	// rotated 45 degrees around z.
+	const h = 0.70710678
+	rotz := glm.Mat3{h, h, 0, -h, h, 0, 0, 0, 1}
+	tests := []struct {
+		a, b   Supporter
+		normal glm.Vec3
+		depth  float32
+	}{
+		{
+			&AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&AABB{Center: glm.Vec3{X: 0, Y: 1.5, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glm.Vec3{X: 0, Y: 1, Z: 0}, 0.5,
+		},
+		{
+			&AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 2, Y: 2, Z: 2}},
+			&AABB{Center: glm.Vec3{X: 0, Y: 0, Z: -2.25}, HalfExtend: glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+			glm.Vec3{X: 0, Y: 0, Z: -1}, 0.25,
+		},
+		{
+			&AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&OBB{Center: glm.Vec3{X: 2.3, Y: 0, Z: 0}, Orientation: rotz, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glm.Vec3{X: 1, Y: 0, Z: 0}, math.Sqrt(2) - 1.3,
+		},
+		{
+			&Sphere{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, Radius: 1},
+			&Sphere{Center: glm.Vec3{X: 0, Y: 0, Z: 1.5}, Radius: 1},
+			glm.Vec3{X: 0, Y: 0, Z: 1}, 0.5,
+		},
+		{
+			cubeHull(glm.Vec3{}, 1).Supporter(),
+			&Capsule{A: glm.Vec3{X: -5, Y: 1.25, Z: 0}, B: glm.Vec3{X: 5, Y: 1.25, Z: 0}, Radius: 0.5},
+			glm.Vec3{X: 0, Y: 1, Z: 0}, 0.25,
+		},
+	}
+	for i, test := range tests {
+		normal, depth, ok := EPA(test.a, test.b)
+		if !ok {
+			t.Errorf("[%d] EPA found no penetration", i)
+			continue
+		}
+		if !vec3Near(normal, test.normal, 1e-2) || math.Abs(depth-test.depth) > 1e-2 {
+			t.Errorf("[%d] EPA = %v %f, want %v %f", i, normal, depth, test.normal, test.depth)
+		}
+	}
+
+	a := AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}
+	b := AABB{Center: glm.Vec3{X: 0, Y: 3, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}
+	if _, _, ok := EPA(&a, &b); ok {
+		t.Errorf("EPA found a penetration between separated boxes")
+	}
+}
+
+func BenchmarkEPA(b *testing.B) {
+	a := AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}
+	c := AABB{Center: glm.Vec3{X: 0.3, Y: 1.5, Z: 0.2}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}
+	for n := 0; n < b.N; n++ {
+		EPA(&a, &c)
+	}
+}
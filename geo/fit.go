@@ -0,0 +1,372 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"sort"
+)
+
+// AABBFromPoints returns the smallest aabb enclosing the points. No points
+// give an aabb of size 0 at the origin, like the other fitting functions.
+func AABBFromPoints(points []glm.Vec3) AABB {
+	if len(points) == 0 {
+		return AABB{}
+	}
+	min := glm.Vec3{X: math.MaxFloat32, Y: math.MaxFloat32, Z: math.MaxFloat32}
+	max := glm.Vec3{X: -math.MaxFloat32, Y: -math.MaxFloat32, Z: -math.MaxFloat32}
+	for i := range points {
+		p := &points[i]
+		min = glm.Vec3{X: math.Min(min.X, p.X), Y: math.Min(min.Y, p.Y), Z: math.Min(min.Z, p.Z)}
+		max = glm.Vec3{X: math.Max(max.X, p.X), Y: math.Max(max.Y, p.Y), Z: math.Max(max.Z, p.Z)}
+	}
+	center := min.Add(&max)
+	halfExtend := max.Sub(&min)
+	return AABB{Center: center.Mul(0.5), HalfExtend: halfExtend.Mul(0.5)}
+}
+
+// OBBFromPoints returns an obb enclosing the points aligned with their
+// principal axes, the eigenvectors of their covariance matrix. It's fast but
+// the points on the inside of the shape weigh on the axes as much as the ones
+// on its surface, use OBBFromConvexhull for a tighter box. No points give an
+// obb of size 0 at the origin, aligned with the world axes.
+func OBBFromPoints(points []glm.Vec3) OBB {
+	if len(points) == 0 {
+		return OBB{Orientation: glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}}
+	}
+	var m, v glm.Mat3
+	CovarianceMatrix(&m, points)
+	// the eigenvectors are the columns of v.
+	Jacobi(&m, &v)
+	axes := [3]glm.Vec3{v.Col(0), v.Col(1), v.Col(2)}
+	return obbFromAxes(points, &axes)
+}
+
+// OBBFromConvexhull returns a tight obb enclosing the hull. One face of the
+// smallest box enclosing a hull almost always lies on a face of the hull, for
+// every face the hull is projected on it and the minimum area rectangle of the
+// projection, found by rotating calipers, gives the 2 other axes. The
+// principal axes are tried as well so the result is never worse than
+// OBBFromPoints on the vertices of the hull.
+func OBBFromConvexhull(hull *Convexhull) OBB {
+	best := OBBFromPoints(hull.Vertices)
+	bestVolume := best.Volume()
+
+	projected := make([]glm.Vec2, len(hull.Vertices))
+	for _, tri := range hull.Triangles {
+		e0 := tri.Vertices[1].Sub(tri.Vertices[0])
+		e1 := tri.Vertices[2].Sub(tri.Vertices[0])
+		n := e0.Cross(&e1)
+		if n.Len2() == 0 {
+			continue
+		}
+		n.Normalize()
+		u, v := perpendicularAxes(&n)
+		for i := range hull.Vertices {
+			projected[i] = glm.Vec2{X: hull.Vertices[i].Dot(&u), Y: hull.Vertices[i].Dot(&v)}
+		}
+		polygon := convexhull2(projected)
+		if len(polygon) < 3 {
+			continue
+		}
+		area, _, orientation := rotatingCalipers(polygon)
+		lo, hi := ExtremePointsAlongDirection(&n, hull.Vertices)
+		volume := area * (hull.Vertices[hi].Dot(&n) - hull.Vertices[lo].Dot(&n))
+		if volume >= bestVolume {
+			continue
+		}
+		axes := [3]glm.Vec3{n, u.Mul(orientation[0].X), u.Mul(orientation[1].X)}
+		axes[1].AddScaledVec(orientation[0].Y, &v)
+		axes[2].AddScaledVec(orientation[1].Y, &v)
+		best = obbFromAxes(hull.Vertices, &axes)
+		bestVolume = best.Volume()
+	}
+	return best
+}
+
+// obbFromAxes returns the smallest obb with these orthonormal axes enclosing
+// the points.
+func obbFromAxes(points []glm.Vec3, axes *[3]glm.Vec3) OBB {
+	var obb OBB
+	for i := range axes {
+		lo, hi := ExtremePointsAlongDirection(&axes[i], points)
+		min, max := points[lo].Dot(&axes[i]), points[hi].Dot(&axes[i])
+		*obb.HalfExtend.I(i) = (max - min) / 2
+		obb.Center.AddScaledVec((max+min)/2, &axes[i])
+	}
+	obb.Orientation = glm.Mat3FromRows(&axes[0], &axes[1], &axes[2])
+	return obb
+}
+
+// perpendicularAxes returns 2 unit vectors forming an orthonormal basis with
+// the unit vector n.
+func perpendicularAxes(n *glm.Vec3) (u, v glm.Vec3) {
+	// cross with the world axis the least aligned with n.
+	if math.Abs(n.X) < 0.57735 {
+		u = glm.Vec3{Y: n.Z, Z: -n.Y}
+	} else {
+		u = glm.Vec3{X: n.Y, Y: -n.X}
+	}
+	u.Normalize()
+	v = n.Cross(&u)
+	return u, v
+}
+
+// convexhull2 returns the convex hull of the points in counter clockwise
+// order, with Andrew's monotone chain. The points are sorted in place and the
+// hull reuses their memory.
+func convexhull2(points []glm.Vec2) []glm.Vec2 {
+	if len(points) < 3 {
+		return points
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].X < points[j].X || points[i].X == points[j].X && points[i].Y < points[j].Y
+	})
+	// turn returns true if o, a, b turn counter clockwise.
+	turn := func(o, a, b *glm.Vec2) bool {
+		oa, ob := a.Sub(o), b.Sub(o)
+		return oa.Cross(&ob) > 0
+	}
+	hull := make([]glm.Vec2, 0, len(points)+1)
+	// lower hull, then upper hull.
+	for i := range points {
+		for len(hull) >= 2 && !turn(&hull[len(hull)-2], &hull[len(hull)-1], &points[i]) {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, points[i])
+	}
+	for i, lower := len(points)-2, len(hull)+1; i >= 0; i-- {
+		for len(hull) >= lower && !turn(&hull[len(hull)-2], &hull[len(hull)-1], &points[i]) {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, points[i])
+	}
+	// the first point was added again at the end.
+	return hull[:len(hull)-1]
+}
+
+// rotatingCalipers returns the same rectangle as MinimumAreaRectangle for a
+// convex polygon in counter clockwise order, like the output of convexhull2,
+// in linear time. One side of the smallest rectangle lies on an edge of the
+// polygon, the calipers touching the 3 other sides only move forward as the
+// edges turn around the polygon.
+func rotatingCalipers(polygon []glm.Vec2) (minArea float32, center glm.Vec2, orientation [2]glm.Vec2) {
+	minArea = float32(math.MaxFloat32)
+	n := len(polygon)
+	// the vertices the furthest along the edge, away from the edge and
+	// backward along the edge.
+	right, top, left := 1, 1, 1
+	along := func(i int, d *glm.Vec2) float32 {
+		e := polygon[(i+1)%n].Sub(&polygon[i])
+		return e.Dot(d)
+	}
+	for i := 0; i < n; i++ {
+		e0 := polygon[(i+1)%n].Sub(&polygon[i])
+		e0.Normalize()
+		// the polygon is on the left of its edges.
+		e1 := glm.Vec2{X: -e0.Y, Y: e0.X}
+
+		for along(right, &e0) > 0 {
+			right = (right + 1) % n
+		}
+		if i == 0 {
+			top = right
+		}
+		for along(top, &e1) > 0 {
+			top = (top + 1) % n
+		}
+		if i == 0 {
+			left = top
+		}
+		for along(left, &e0) < 0 {
+			left = (left + 1) % n
+		}
+
+		r, tp, l := polygon[right].Sub(&polygon[i]), polygon[top].Sub(&polygon[i]), polygon[left].Sub(&polygon[i])
+		min0, max0, max1 := l.Dot(&e0), r.Dot(&e0), tp.Dot(&e1)
+		if area := (max0 - min0) * max1; area < minArea {
+			minArea = area
+			orientation = [2]glm.Vec2{e0, e1}
+			t0, t1 := e0.Mul((min0+max0)/2), e1.Mul(max1/2)
+			center = polygon[i].Add(&t0)
+			center.AddWith(&t1)
+		}
+	}
+	return
+}
+
+// CapsuleFromPoints returns a capsule enclosing the points. Its segment runs
+// along the longest principal axis of the points, the radius is the distance
+// of the farthest point from it and the segment is then made as short as the
+// end spheres allow. No points give a capsule of size 0 at the origin.
+func CapsuleFromPoints(points []glm.Vec3) Capsule {
+	if len(points) == 0 {
+		return Capsule{}
+	}
+	obb := OBBFromPoints(points)
+	axis := 0
+	for i := 1; i < 3; i++ {
+		if *obb.HalfExtend.I(i) > *obb.HalfExtend.I(axis) {
+			axis = i
+		}
+	}
+	d := obb.Orientation.Row(axis)
+
+	var r2 float32
+	for i := range points {
+		p := points[i].Sub(&obb.Center)
+		t := p.Dot(&d)
+		r2 = math.Max(r2, p.Len2()-t*t)
+	}
+
+	// A point past an end of the segment is inside its sphere if it's no
+	// farther than h along the axis, where h is how much room the radius
+	// leaves at its distance from the axis.
+	tmin, tmax := float32(math.MaxFloat32), float32(-math.MaxFloat32)
+	for i := range points {
+		p := points[i].Sub(&obb.Center)
+		t := p.Dot(&d)
+		h := math.Sqrt(math.Max(r2-(p.Len2()-t*t), 0))
+		tmin = math.Min(tmin, t+h)
+		tmax = math.Max(tmax, t-h)
+	}
+	// every point is within reach of any point in between, the capsule is a
+	// sphere.
+	if tmin > tmax {
+		tmin = (tmin + tmax) / 2
+		tmax = tmin
+	}
+
+	c := Capsule{A: obb.Center, B: obb.Center, Radius: math.Sqrt(r2)}
+	c.A.AddScaledVec(tmin, &d)
+	c.B.AddScaledVec(tmax, &d)
+	return c
+}
+
+// WelzlSphere returns the smallest sphere enclosing the points. It runs in
+// expected linear time, points found outside the current sphere are moved to
+// the front of a copy of points so the next candidate spheres test them first.
+// No points give a sphere of size 0 at the origin.
+// [Welzl91], [Gartner99]
+func WelzlSphere(points []glm.Vec3) Sphere {
+	if len(points) == 0 {
+		return Sphere{}
+	}
+	buf := make([]glm.Vec3, len(points))
+	copy(buf, points)
+	var support [4]glm.Vec3
+	s := welzl(buf, len(buf), &support, 0)
+
+	// rounding can leave some points a hair outside.
+	r2 := s.Radius * s.Radius
+	for i := range points {
+		d := points[i].Sub(&s.Center)
+		r2 = math.Max(r2, d.Len2())
+	}
+	s.Radius = math.Sqrt(r2)
+	return s
+}
+
+// welzlEpsilon is how far, relative to the radius, a point can be outside of
+// the sphere and still be considered inside.
+const welzlEpsilon = 1e-5
+
+// welzl returns the smallest sphere enclosing the first n points with the ns
+// first points of support on its surface.
+func welzl(points []glm.Vec3, n int, support *[4]glm.Vec3, ns int) Sphere {
+	s := sphereFromSupport(support[:ns])
+	if ns == 4 {
+		return s
+	}
+	for i := 0; i < n; i++ {
+		// there is no sphere yet when nothing is on its surface.
+		if (ns > 0 || i > 0) && sphereContains(&s, points[i:i+1]) {
+			continue
+		}
+		support[ns] = points[i]
+		s = welzl(points, i, support, ns+1)
+		// move to front.
+		p := points[i]
+		copy(points[1:i+1], points[:i])
+		points[0] = p
+	}
+	return s
+}
+
+// sphereContains returns true if all the points are inside the sphere, give or
+// take welzlEpsilon.
+func sphereContains(s *Sphere, points []glm.Vec3) bool {
+	r := s.Radius * (1 + welzlEpsilon)
+	for i := range points {
+		if d := points[i].Sub(&s.Center); d.Len2() > r*r {
+			return false
+		}
+	}
+	return true
+}
+
+// sphereFromSupport returns the smallest sphere with the support points on its
+// surface.
+func sphereFromSupport(support []glm.Vec3) Sphere {
+	switch len(support) {
+	case 1:
+		return Sphere{Center: support[0]}
+	case 2:
+		c := support[0].Add(&support[1])
+		d := support[1].Sub(&support[0])
+		return Sphere{Center: c.Mul(0.5), Radius: d.Len() / 2}
+	case 3:
+		a := &support[0]
+		ab, ac := support[1].Sub(a), support[2].Sub(a)
+		n := ab.Cross(&ac)
+		nn := n.Len2()
+		// collinear, the sphere of the 2 farthest points.
+		if nn <= 1e-10*ab.Len2()*ac.Len2() {
+			bc := support[2].Sub(&support[1])
+			switch {
+			case bc.Len2() >= ab.Len2() && bc.Len2() >= ac.Len2():
+				return sphereFromSupport(support[1:3])
+			case ab.Len2() >= ac.Len2():
+				return sphereFromSupport(support[0:2])
+			}
+			return sphereFromSupport([]glm.Vec3{support[0], support[2]})
+		}
+		// [Ericson05] 4.3.5
+		nab, acn := n.Cross(&ab), ac.Cross(&n)
+		o := nab.Mul(ac.Len2())
+		o.AddScaledVec(ab.Len2(), &acn)
+		o.MulWith(1 / (2 * nn))
+		return Sphere{Center: a.Add(&o), Radius: o.Len()}
+	case 4:
+		a := &support[0]
+		ab, ac, ad := support[1].Sub(a), support[2].Sub(a), support[3].Sub(a)
+		acad, adab, abac := ac.Cross(&ad), ad.Cross(&ab), ab.Cross(&ac)
+		det := ab.Dot(&acad)
+		// coplanar, the smallest sphere through 2 or 3 of them enclosing the
+		// others.
+		if math.Abs(det) <= 1e-5*ab.Len()*ac.Len()*ad.Len() {
+			best := Sphere{Radius: math.MaxFloat32}
+			for subset := 3; subset < 15; subset++ {
+				var sub []glm.Vec3
+				for i := range support {
+					if subset&(1<<uint(i)) != 0 {
+						sub = append(sub, support[i])
+					}
+				}
+				if len(sub) == 4 || len(sub) < 2 {
+					continue
+				}
+				s := sphereFromSupport(sub)
+				if s.Radius < best.Radius && sphereContains(&s, support) {
+					best = s
+				}
+			}
+			return best
+		}
+		o := abac.Mul(ad.Len2())
+		o.AddScaledVec(ac.Len2(), &adab)
+		o.AddScaledVec(ab.Len2(), &acad)
+		o.MulWith(1 / (2 * det))
+		return Sphere{Center: a.Add(&o), Radius: o.Len()}
+	}
+	return Sphere{}
+}
@@ -0,0 +1,386 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"math/rand"
+	"testing"
+)
+
+// boxPoints returns the corners of a box with these half extends rotated by
+// rotation and moved to center, followed by n random points inside the box
+// clustered along its diagonal.
+func boxPoints(center, halfExtend glm.Vec3, rotation *glm.Mat3, n int) []glm.Vec3 {
+	var local []glm.Vec3
+	for _, x := range []float32{-1, 1} {
+		for _, y := range []float32{-1, 1} {
+			for _, z := range []float32{-1, 1} {
+				local = append(local, glm.Vec3{X: x * halfExtend.X, Y: y * halfExtend.Y, Z: z * halfExtend.Z})
+			}
+		}
+	}
+	r := rand.New(rand.NewSource(999))
+	for i := 0; i < n; i++ {
+		t := r.Float32()*1.6 - 0.8
+		local = append(local, glm.Vec3{
+			X: (t + r.Float32()*0.1) * halfExtend.X,
+			Y: (t + r.Float32()*0.1) * halfExtend.Y,
+			Z: (t + r.Float32()*0.1) * halfExtend.Z,
+		})
+	}
+	points := make([]glm.Vec3, len(local))
+	for i := range local {
+		points[i] = rotation.Mul3x1(&local[i])
+		points[i].AddWith(&center)
+	}
+	return points
+}
+
+// randomPoints returns n points in a rotated and stretched cube.
+func randomPoints(seed int64, n int) []glm.Vec3 {
+	r := rand.New(rand.NewSource(seed))
+	axis := glm.Vec3{X: 1, Y: 2, Z: 3}
+	axis.Normalize()
+	q := glm.QuatRotate(0.7, &axis)
+	rotation := q.Mat3()
+	points := make([]glm.Vec3, n)
+	for i := range points {
+		p := glm.Vec3{X: (r.Float32() - 0.5) * 10, Y: (r.Float32() - 0.5) * 4, Z: r.Float32() - 0.5}
+		points[i] = rotation.Mul3x1(&p)
+	}
+	return points
+}
+
+func obbContains(obb *OBB, points []glm.Vec3) bool {
+	for i := range points {
+		if SqDistOBBPoint(obb, &points[i]) > 1e-6 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAABBFromPoints(t *testing.T) {
+	tests := []struct {
+		points []glm.Vec3
+		aabb   AABB
+	}{
+		{ // 0
+			[]glm.Vec3{{X: 1, Y: 2, Z: 3}},
+			AABB{Center: glm.Vec3{X: 1, Y: 2, Z: 3}},
+		},
+		{ // 1
+			[]glm.Vec3{{X: -1, Y: 2, Z: 0}, {X: 3, Y: -2, Z: 1}, {X: 0, Y: 0, Z: 5}},
+			AABB{Center: glm.Vec3{X: 1, Y: 0, Z: 2.5}, HalfExtend: glm.Vec3{X: 2, Y: 2, Z: 2.5}},
+		},
+	}
+	for i, test := range tests {
+		if aabb := AABBFromPoints(test.points); aabb != test.aabb {
+			t.Errorf("[%d] aabb = %v, want %v", i, aabb, test.aabb)
+		}
+	}
+}
+
+func TestFromPoints_Empty(t *testing.T) {
+	for _, points := range [][]glm.Vec3{nil, {}} {
+		if aabb := AABBFromPoints(points); aabb != (AABB{}) {
+			t.Errorf("AABBFromPoints(%v) = %v, want %v", points, aabb, AABB{})
+		}
+		want := OBB{Orientation: glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}}
+		if obb := OBBFromPoints(points); obb != want {
+			t.Errorf("OBBFromPoints(%v) = %v, want %v", points, obb, want)
+		}
+		if c := CapsuleFromPoints(points); c != (Capsule{}) {
+			t.Errorf("CapsuleFromPoints(%v) = %v, want %v", points, c, Capsule{})
+		}
+		if s := WelzlSphere(points); s != (Sphere{}) {
+			t.Errorf("WelzlSphere(%v) = %v, want %v", points, s, Sphere{})
+		}
+		if s := RitterEigenSphere(points); s != (Sphere{}) {
+			t.Errorf("RitterEigenSphere(%v) = %v, want %v", points, s, Sphere{})
+		}
+	}
+}
+
+func TestOBBFromPoints(t *testing.T) {
+	axis := glm.Vec3{X: 1, Y: 1, Z: 0}
+	axis.Normalize()
+	q := glm.QuatRotate(math.Pi/5, &axis)
+	rotation := q.Mat3()
+	tests := []struct {
+		points []glm.Vec3
+		volume float32
+	}{
+		{boxPoints(glm.Vec3{X: 1, Y: 2, Z: 3}, glm.Vec3{X: 3, Y: 2, Z: 1}, &rotation, 0), 48}, // 0
+		{randomPoints(1, 100), 0}, // 1
+	}
+	for i, test := range tests {
+		obb := OBBFromPoints(test.points)
+		if !obbContains(&obb, test.points) {
+			t.Errorf("[%d] %v doesn't contain every point", i, obb)
+		}
+		if test.volume != 0 && math.Abs(obb.Volume()-test.volume) > 1e-3 {
+			t.Errorf("[%d] volume = %f, want %f", i, obb.Volume(), test.volume)
+		}
+	}
+}
+
+func TestOBBFromConvexhull(t *testing.T) {
+	axis := glm.Vec3{X: 1, Y: 1, Z: 0}
+	axis.Normalize()
+	q := glm.QuatRotate(math.Pi/5, &axis)
+	rotation := q.Mat3()
+	tests := []struct {
+		points []glm.Vec3
+		volume float32
+	}{
+		{boxPoints(glm.Vec3{X: 1, Y: 2, Z: 3}, glm.Vec3{X: 3, Y: 2, Z: 1}, &rotation, 0), 48}, // 0
+		// the points inside the box throw the principal axes off.
+		{boxPoints(glm.Vec3{X: 1, Y: 2, Z: 3}, glm.Vec3{X: 3, Y: 2, Z: 1}, &rotation, 200), 48}, // 1
+		{randomPoints(1, 100), 0}, // 2
+	}
+	for i, test := range tests {
+		points := make([]glm.Vec3, len(test.points))
+		copy(points, test.points)
+		hull := Quickhull(points)
+		obb := OBBFromConvexhull(hull)
+		if !obbContains(&obb, test.points) {
+			t.Errorf("[%d] %v doesn't contain every point", i, obb)
+		}
+		if test.volume != 0 && math.Abs(obb.Volume()-test.volume) > 1e-2 {
+			t.Errorf("[%d] volume = %f, want %f", i, obb.Volume(), test.volume)
+		}
+		if pca := OBBFromPoints(test.points); obb.Volume() > pca.Volume()*(1+1e-5) {
+			t.Errorf("[%d] volume = %f, bigger than the principal axes box %f", i, obb.Volume(), pca.Volume())
+		}
+	}
+}
+
+func TestRotatingCalipers(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		points := make([]glm.Vec2, 3+r.Intn(40))
+		for n := range points {
+			points[n] = glm.Vec2{X: r.Float32()*10 - 5, Y: r.Float32()*4 - 2}
+		}
+		polygon := convexhull2(points)
+		area, center, orientation := rotatingCalipers(polygon)
+		want, _, _ := MinimumAreaRectangle(polygon)
+		if math.Abs(area-want) > 1e-4*want {
+			t.Errorf("[%d] area = %f, want %f", i, area, want)
+		}
+
+		// the rectangle contains the polygon.
+		var half [2]float32
+		for _, p := range polygon {
+			d := p.Sub(&center)
+			for a := range orientation {
+				if x := math.Abs(d.Dot(&orientation[a])); x > half[a] {
+					half[a] = x
+				}
+			}
+		}
+		if got := 4 * half[0] * half[1]; math.Abs(got-area) > 1e-4*area {
+			t.Errorf("[%d] rectangle around the center of area %f, want %f", i, got, area)
+		}
+	}
+}
+
+func TestCapsuleFromPoints(t *testing.T) {
+	// points on the surface of a capsule from (0, -2, 0) to (0, 2, 0) of
+	// radius 1, rotated.
+	var surface []glm.Vec3
+	for i := 0; i < 16; i++ {
+		s, c := math.Sincos(float32(i) * math.Pi / 8)
+		for _, y := range []float32{-2, -1, 0, 1, 2} {
+			surface = append(surface, glm.Vec3{X: c, Y: y, Z: s})
+		}
+	}
+	surface = append(surface, glm.Vec3{Y: 3}, glm.Vec3{Y: -3})
+	axis := glm.Vec3{X: 0, Y: 1, Z: 1}
+	axis.Normalize()
+	q := glm.QuatRotate(math.Pi/3, &axis)
+	rotation := q.Mat3()
+	center := glm.Vec3{X: 5, Y: -1, Z: 2}
+	for i := range surface {
+		surface[i] = rotation.Mul3x1(&surface[i])
+		surface[i].AddWith(&center)
+	}
+	a, b := rotation.Mul3x1(&glm.Vec3{Y: -2}), rotation.Mul3x1(&glm.Vec3{Y: 2})
+	a.AddWith(&center)
+	b.AddWith(&center)
+
+	tests := []struct {
+		points  []glm.Vec3
+		capsule Capsule
+	}{
+		{surface, Capsule{A: a, B: b, Radius: 1}}, // 0
+		{ // 1 a sphere.
+			[]glm.Vec3{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}, {Z: 1}, {Z: -1}},
+			Capsule{Radius: 1},
+		},
+		{randomPoints(1, 100), Capsule{}}, // 2
+	}
+	for i, test := range tests {
+		c := CapsuleFromPoints(test.points)
+		for _, p := range test.points {
+			if d := SqDistPointSegment(&c.A, &c.B, &p); d > c.Radius*c.Radius+1e-4 {
+				t.Errorf("[%d] %v doesn't contain %v", i, c, p)
+				break
+			}
+		}
+		if test.capsule.Radius == 0 {
+			continue
+		}
+		if math.Abs(c.Radius-test.capsule.Radius) > 1e-3 {
+			t.Errorf("[%d] radius = %f, want %f", i, c.Radius, test.capsule.Radius)
+		}
+		// the segment may come out reversed.
+		if !(vec3Near(c.A, test.capsule.A, 1e-3) && vec3Near(c.B, test.capsule.B, 1e-3)) &&
+			!(vec3Near(c.A, test.capsule.B, 1e-3) && vec3Near(c.B, test.capsule.A, 1e-3)) {
+			t.Errorf("[%d] segment = %v %v, want %v %v", i, c.A, c.B, test.capsule.A, test.capsule.B)
+		}
+	}
+}
+
+// minimumSphereSlow returns the smallest sphere enclosing the points by trying
+// the spheres of every set of 1 to 4 of them.
+func minimumSphereSlow(points []glm.Vec3) Sphere {
+	best := Sphere{Radius: math.MaxFloat32}
+	n := len(points)
+	try := func(support ...glm.Vec3) {
+		s := sphereFromSupport(support)
+		if s.Radius < best.Radius && sphereContains(&s, points) {
+			best = s
+		}
+	}
+	for i := 0; i < n; i++ {
+		try(points[i])
+		for j := i + 1; j < n; j++ {
+			try(points[i], points[j])
+			for k := j + 1; k < n; k++ {
+				try(points[i], points[j], points[k])
+				for l := k + 1; l < n; l++ {
+					try(points[i], points[j], points[k], points[l])
+				}
+			}
+		}
+	}
+	return best
+}
+
+func TestWelzlSphere(t *testing.T) {
+	tests := []struct {
+		points []glm.Vec3
+		sphere Sphere
+	}{
+		{ // 0
+			[]glm.Vec3{{X: 1, Y: 2, Z: 3}},
+			Sphere{Center: glm.Vec3{X: 1, Y: 2, Z: 3}},
+		},
+		{ // 1
+			[]glm.Vec3{{X: 1}, {X: -3}, {X: -1, Y: 0.5}},
+			Sphere{Center: glm.Vec3{X: -1}, Radius: 2},
+		},
+		{ // 2 an equilateral triangle.
+			[]glm.Vec3{{X: 1}, {X: -0.5, Y: math.Sqrt(3) / 2}, {X: -0.5, Y: -math.Sqrt(3) / 2}},
+			Sphere{Radius: 1},
+		},
+		{ // 3 a regular tetrahedron.
+			[]glm.Vec3{{X: 1, Y: 1, Z: 1}, {X: 1, Y: -1, Z: -1}, {X: -1, Y: 1, Z: -1}, {X: -1, Y: -1, Z: 1}},
+			Sphere{Radius: math.Sqrt(3)},
+		},
+		{ // 4 a square, every 4 points are coplanar.
+			[]glm.Vec3{{X: 1, Y: 1}, {X: 1, Y: -1}, {X: -1, Y: 1}, {X: -1, Y: -1}, {}},
+			Sphere{Radius: math.Sqrt(2)},
+		},
+		{ // 5
+			boxPoints(glm.Vec3{X: 1, Y: 2, Z: 3}, glm.Vec3{X: 3, Y: 2, Z: 1}, &glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}, 50),
+			Sphere{Center: glm.Vec3{X: 1, Y: 2, Z: 3}, Radius: math.Sqrt(14)},
+		},
+	}
+	for i, test := range tests {
+		s := WelzlSphere(test.points)
+		if !vec3Near(s.Center, test.sphere.Center, 1e-4) || math.Abs(s.Radius-test.sphere.Radius) > 1e-4 {
+			t.Errorf("[%d] sphere = %v, want %v", i, s, test.sphere)
+		}
+	}
+}
+
+func TestWelzlSphere_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(999))
+	for i := 0; i < 200; i++ {
+		points := make([]glm.Vec3, 4+r.Intn(8))
+		for n := range points {
+			points[n] = glm.Vec3{X: r.Float32()*10 - 5, Y: r.Float32()*4 - 2, Z: r.Float32()*2 - 1}
+		}
+		s, want := WelzlSphere(points), minimumSphereSlow(points)
+		if !sphereContains(&s, points) {
+			t.Errorf("[%d] %v doesn't contain every point", i, s)
+		}
+		if math.Abs(s.Radius-want.Radius) > 1e-4*want.Radius {
+			t.Errorf("[%d] radius = %f, want %f", i, s.Radius, want.Radius)
+		}
+		if ritter := RitterEigenSphere(points); s.Radius > ritter.Radius*(1+1e-5) {
+			t.Errorf("[%d] radius = %f, bigger than RitterEigenSphere %f", i, s.Radius, ritter.Radius)
+		}
+	}
+}
+
+// The fitting benchmarks also report how big the volumes are, smaller is
+// tighter.
+
+func BenchmarkAABBFromPoints(b *testing.B) {
+	points := randomPoints(999, 200)
+	var aabb AABB
+	for n := 0; n < b.N; n++ {
+		aabb = AABBFromPoints(points)
+	}
+	b.ReportMetric(float64(aabb.Volume()), "volume")
+}
+
+func BenchmarkOBBFromPoints(b *testing.B) {
+	points := randomPoints(999, 200)
+	var obb OBB
+	for n := 0; n < b.N; n++ {
+		obb = OBBFromPoints(points)
+	}
+	b.ReportMetric(float64(obb.Volume()), "volume")
+}
+
+func BenchmarkOBBFromConvexhull(b *testing.B) {
+	hull := Quickhull(randomPoints(999, 200))
+	var obb OBB
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		obb = OBBFromConvexhull(hull)
+	}
+	b.ReportMetric(float64(obb.Volume()), "volume")
+}
+
+func BenchmarkCapsuleFromPoints(b *testing.B) {
+	points := randomPoints(999, 200)
+	var c Capsule
+	for n := 0; n < b.N; n++ {
+		c = CapsuleFromPoints(points)
+	}
+	b.ReportMetric(float64(c.Volume()), "volume")
+}
+
+func BenchmarkRitterEigenSphere(b *testing.B) {
+	points := randomPoints(999, 200)
+	var s Sphere
+	for n := 0; n < b.N; n++ {
+		s = RitterEigenSphere(points)
+	}
+	b.ReportMetric(float64(s.Volume()), "volume")
+}
+
+func BenchmarkWelzlSphere(b *testing.B) {
+	points := randomPoints(999, 200)
+	var s Sphere
+	for n := 0; n < b.N; n++ {
+		s = WelzlSphere(points)
+	}
+	b.ReportMetric(float64(s.Volume()), "volume")
+}
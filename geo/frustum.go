@@ -109,3 +109,197 @@ func TestFrustumSphere(frustum *Frustum, sphere *Sphere, view *glm.Mat4) bool {
 	}
 	return true
 }
+
+// The planes of frustums built by FrustumFromMatrix.
+const (
+	FrustumNear = iota
+	FrustumFar
+	FrustumTop
+	FrustumBottom
+	FrustumRight
+	FrustumLeft
+)
+
+// FrustumAllPlanes is the plane mask that tests every plane of a frustum.
+const FrustumAllPlanes uint8 = 1<<6 - 1
+
+// CullResult is where a shape is relative to a frustum.
+type CullResult int
+
+// The results of the culling tests.
+const (
+	// the shape is entirely outside of the frustum.
+	CullOutside CullResult = iota
+	// the shape may be partly inside the frustum.
+	CullIntersect
+	// the shape is entirely inside the frustum.
+	CullInside
+)
+
+// FrustumFromMatrix returns the frustum of the given view projection matrix in
+// world space, or in the space the matrix takes its points from. Any
+// projection works, off-center and oblique ones included. The planes are
+// normalized and in the order of FrustumNear to FrustumLeft.
+// [GribbHartmann01]
+func FrustumFromMatrix(viewProj *glm.Mat4) Frustum {
+	r0, r1, r2, r3 := viewProj.Row(0), viewProj.Row(1), viewProj.Row(2), viewProj.Row(3)
+	// In clip space the points inside the frustum have -w <= x, y, z <= w.
+	// Each of these is a plane r3 ± ri, facing the inside of the frustum.
+	planes := [6]glm.Vec4{
+		r3.Add(&r2), r3.Sub(&r2),
+		r3.Sub(&r1), r3.Add(&r1),
+		r3.Sub(&r0), r3.Add(&r0),
+	}
+	var frustum Frustum
+	for i, p := range planes {
+		// flip the planes so they face outside like the other frustums.
+		n := glm.Vec3{X: -p.X, Y: -p.Y, Z: -p.Z}
+		l := n.Len()
+		frustum.Planes[i] = Plane{Normal: n.Mul(1 / l), Offset: p.W / l}
+	}
+	return frustum
+}
+
+// Corners returns the 8 corners of a frustum built by FrustumFromMatrix. The
+// bits of the index of a corner tell if it's on the far, bottom and left
+// planes.
+func (frustum *Frustum) Corners() [8]glm.Vec3 {
+	var corners [8]glm.Vec3
+	for i := range corners {
+		corners[i] = intersectPlanes(
+			&frustum.Planes[FrustumNear+(i>>2&1)],
+			&frustum.Planes[FrustumTop+(i>>1&1)],
+			&frustum.Planes[FrustumRight+(i&1)],
+		)
+	}
+	return corners
+}
+
+// intersectPlanes returns the point where the 3 planes meet.
+func intersectPlanes(p0, p1, p2 *Plane) glm.Vec3 {
+	u := p1.Normal.Cross(&p2.Normal)
+	v := p2.Normal.Cross(&p0.Normal)
+	w := p0.Normal.Cross(&p1.Normal)
+	p := u.Mul(p0.Offset)
+	p.AddScaledVec(p1.Offset, &v)
+	p.AddScaledVec(p2.Offset, &w)
+	p.MulWith(1 / p0.Normal.Dot(&u))
+	return p
+}
+
+// pointsSupporter is the support function of the convex hull of its points.
+type pointsSupporter []glm.Vec3
+
+// Support returns the point the most in the given direction.
+func (points pointsSupporter) Support(direction *glm.Vec3) glm.Vec3 {
+	best, max := 0, points[0].Dot(direction)
+	for i := 1; i < len(points); i++ {
+		if d := points[i].Dot(direction); d > max {
+			best, max = i, d
+		}
+	}
+	return points[best]
+}
+
+// cullFrustum culls a shape against the planes of the frustum in mask. project
+// returns the projection of the center of the shape on a plane normal and how
+// far the shape extends on both sides of it.
+//
+// It also returns the mask of the planes the shape crosses, the children of the
+// shape in a hierarchy only need to be tested against those. A shape crossing
+// several planes near a corner of the frustum may be outside of it, culling
+// only reports CullOutside when one plane has the entire shape outside.
+func cullFrustum(frustum *Frustum, mask uint8, project func(n *glm.Vec3) (center, radius float32)) (CullResult, uint8) {
+	result, crossed := CullInside, uint8(0)
+	for i := uint(0); i < 6; i++ {
+		if mask&(1<<i) == 0 {
+			continue
+		}
+		p := &frustum.Planes[i]
+		c, r := project(&p.Normal)
+		s := c - p.Offset
+		// NaNs are outside.
+		if !(s <= r) {
+			return CullOutside, 0
+		}
+		if s > -r {
+			result = CullIntersect
+			crossed |= 1 << i
+		}
+	}
+	return result, crossed
+}
+
+// CullFrustumAABB returns where the aabb is relative to the planes of the
+// frustum in mask and the mask of the planes it crosses.
+func CullFrustumAABB(frustum *Frustum, aabb *AABB, mask uint8) (CullResult, uint8) {
+	return cullFrustum(frustum, mask, func(n *glm.Vec3) (float32, float32) {
+		return n.Dot(&aabb.Center), aabb.HalfExtend.X*math.Abs(n.X) +
+			aabb.HalfExtend.Y*math.Abs(n.Y) +
+			aabb.HalfExtend.Z*math.Abs(n.Z)
+	})
+}
+
+// CullFrustumSphere returns where the sphere is relative to the planes of the
+// frustum in mask and the mask of the planes it crosses.
+func CullFrustumSphere(frustum *Frustum, sphere *Sphere, mask uint8) (CullResult, uint8) {
+	return cullFrustum(frustum, mask, func(n *glm.Vec3) (float32, float32) {
+		return n.Dot(&sphere.Center), sphere.Radius
+	})
+}
+
+// CullFrustumOBB returns where the obb is relative to the planes of the
+// frustum in mask and the mask of the planes it crosses.
+func CullFrustumOBB(frustum *Frustum, obb *OBB, mask uint8) (CullResult, uint8) {
+	r0, r1, r2 := obb.Orientation.Rows()
+	return cullFrustum(frustum, mask, func(n *glm.Vec3) (float32, float32) {
+		return n.Dot(&obb.Center), obb.HalfExtend.X*math.Abs(n.Dot(&r0)) +
+			obb.HalfExtend.Y*math.Abs(n.Dot(&r1)) +
+			obb.HalfExtend.Z*math.Abs(n.Dot(&r2))
+	})
+}
+
+// CullFrustumCapsule returns where the capsule is relative to the planes of
+// the frustum in mask and the mask of the planes it crosses.
+func CullFrustumCapsule(frustum *Frustum, capsule *Capsule, mask uint8) (CullResult, uint8) {
+	center := capsule.A.Add(&capsule.B)
+	center.MulWith(0.5)
+	half := capsule.B.Sub(&center)
+	return cullFrustum(frustum, mask, func(n *glm.Vec3) (float32, float32) {
+		return n.Dot(&center), math.Abs(n.Dot(&half)) + capsule.Radius
+	})
+}
+
+// CullFrustumConvexhull returns where the convex hull is relative to the
+// planes of the frustum in mask and the mask of the planes it crosses.
+func CullFrustumConvexhull(frustum *Frustum, hull *Convexhull, mask uint8) (CullResult, uint8) {
+	s := hull.Supporter()
+	return cullFrustum(frustum, mask, func(n *glm.Vec3) (float32, float32) {
+		in := n.Inverse()
+		max, min := s.Support(n), s.Support(&in)
+		hi, lo := n.Dot(&max), n.Dot(&min)
+		return (hi + lo) / 2, (hi - lo) / 2
+	})
+}
+
+// CullFrustumFrustum returns where frustum f1, built by FrustumFromMatrix, is
+// relative to the planes of frustum f0 in mask and the mask of the planes it
+// crosses.
+func CullFrustumFrustum(f0, f1 *Frustum, mask uint8) (CullResult, uint8) {
+	corners := f1.Corners()
+	return cullFrustum(f0, mask, func(n *glm.Vec3) (float32, float32) {
+		lo, hi := n.Dot(&corners[0]), n.Dot(&corners[0])
+		for i := 1; i < len(corners); i++ {
+			d := n.Dot(&corners[i])
+			lo, hi = math.Min(lo, d), math.Max(hi, d)
+		}
+		return (hi + lo) / 2, (hi - lo) / 2
+	})
+}
+
+// TestFrustumFrustum returns true if the frustums, built by FrustumFromMatrix,
+// intersect. Unlike the culling tests this is exact.
+func TestFrustumFrustum(f0, f1 *Frustum) bool {
+	c0, c1 := f0.Corners(), f1.Corners()
+	return TestGJK(pointsSupporter(c0[:]), pointsSupporter(c1[:]))
+}
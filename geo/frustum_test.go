@@ -0,0 +1,186 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"testing"
+)
+
+func TestFrustumFromMatrix(t *testing.T) {
+	ortho := glm.Ortho(-1, 2, -3, 4, 1, 10)
+	view := glm.Translate3D(0, 0, -5)
+	moved := ortho.Mul4(&view)
+	tests := []struct {
+		viewProj glm.Mat4
+		planes   [6]Plane
+	}{
+		{ // 0
+			ortho,
+			[6]Plane{
+				{glm.Vec3{X: 0, Y: 0, Z: 1}, -1},
+				{glm.Vec3{X: 0, Y: 0, Z: -1}, 10},
+				{glm.Vec3{X: 0, Y: 1, Z: 0}, 4},
+				{glm.Vec3{X: 0, Y: -1, Z: 0}, 3},
+				{glm.Vec3{X: 1, Y: 0, Z: 0}, 2},
+				{glm.Vec3{X: -1, Y: 0, Z: 0}, 1},
+			},
+		},
+		{ // 1 the camera is at z = 5.
+			moved,
+			[6]Plane{
+				{glm.Vec3{X: 0, Y: 0, Z: 1}, 4},
+				{glm.Vec3{X: 0, Y: 0, Z: -1}, 5},
+				{glm.Vec3{X: 0, Y: 1, Z: 0}, 4},
+				{glm.Vec3{X: 0, Y: -1, Z: 0}, 3},
+				{glm.Vec3{X: 1, Y: 0, Z: 0}, 2},
+				{glm.Vec3{X: -1, Y: 0, Z: 0}, 1},
+			},
+		},
+		{ // 2 a 90 degrees field of view.
+			glm.Perspective(math.Pi/2, 1, 1, 100),
+			[6]Plane{
+				{glm.Vec3{X: 0, Y: 0, Z: 1}, -1},
+				{glm.Vec3{X: 0, Y: 0, Z: -1}, 100},
+				{glm.Vec3{X: 0, Y: math.Sqrt(0.5), Z: math.Sqrt(0.5)}, 0},
+				{glm.Vec3{X: 0, Y: -math.Sqrt(0.5), Z: math.Sqrt(0.5)}, 0},
+				{glm.Vec3{X: math.Sqrt(0.5), Y: 0, Z: math.Sqrt(0.5)}, 0},
+				{glm.Vec3{X: -math.Sqrt(0.5), Y: 0, Z: math.Sqrt(0.5)}, 0},
+			},
+		},
+	}
+	for i, test := range tests {
+		frustum := FrustumFromMatrix(&test.viewProj)
+		for n, want := range test.planes {
+			got := frustum.Planes[n]
+			if !vec3Near(got.Normal, want.Normal, 1e-4) || math.Abs(got.Offset-want.Offset) > 1e-3 {
+				t.Errorf("[%d] plane %d = %v, want %v", i, n, got, want)
+			}
+		}
+	}
+}
+
+func TestFrustum_Corners(t *testing.T) {
+	// an off-center projection, the near plane is [0, 2]x[-1, 1] at z = -1.
+	proj := glm.Frustum(0, 2, -1, 1, 1, 10)
+	frustum := FrustumFromMatrix(&proj)
+	corners := frustum.Corners()
+	want := [8]glm.Vec3{
+		{X: 2, Y: 1, Z: -1}, {X: 0, Y: 1, Z: -1}, {X: 2, Y: -1, Z: -1}, {X: 0, Y: -1, Z: -1},
+		{X: 20, Y: 10, Z: -10}, {X: 0, Y: 10, Z: -10}, {X: 20, Y: -10, Z: -10}, {X: 0, Y: -10, Z: -10},
+	}
+	for i := range want {
+		if !vec3Near(corners[i], want[i], 1e-3) {
+			t.Errorf("corner %d = %v, want %v", i, corners[i], want[i])
+		}
+	}
+}
+
+func TestCullFrustum(t *testing.T) {
+	ident := glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}
+	// rotated 45 degrees around z.
+	const h = 0.70710678
+	rotz := glm.Mat3{h, h, 0, -h, h, 0, 0, 0, 1}
+	proj := glm.Perspective(math.Pi/2, 1, 1, 100)
+	frustum := FrustumFromMatrix(&proj)
+	near, right := uint8(1<<FrustumNear), uint8(1<<FrustumRight)
+
+	cull := func(s Shape, mask uint8) (CullResult, uint8) {
+		switch s := s.(type) {
+		case *AABB:
+			return CullFrustumAABB(&frustum, s, mask)
+		case *Sphere:
+			return CullFrustumSphere(&frustum, s, mask)
+		case *OBB:
+			return CullFrustumOBB(&frustum, s, mask)
+		case *Capsule:
+			return CullFrustumCapsule(&frustum, s, mask)
+		case *Convexhull:
+			return CullFrustumConvexhull(&frustum, s, mask)
+		}
+		panic("unknown shape")
+	}
+	tests := []struct {
+		shape  Shape
+		mask   uint8
+		result CullResult
+		out    uint8
+	}{
+		{&AABB{Center: glm.Vec3{Z: -50}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, FrustumAllPlanes, CullInside, 0},                 // 0
+		{&AABB{Center: glm.Vec3{Z: -200}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, FrustumAllPlanes, CullOutside, 0},               // 1
+		{&AABB{Center: glm.Vec3{Z: -1}, HalfExtend: glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}}, FrustumAllPlanes, CullIntersect, near},      // 2
+		{&AABB{Center: glm.Vec3{X: 50, Z: -50}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, FrustumAllPlanes, CullIntersect, right},   // 3
+		{&AABB{Center: glm.Vec3{X: 50, Z: -50}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, FrustumAllPlanes &^ right, CullInside, 0}, // 4
+		{&AABB{Center: glm.Vec3{Z: -200}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, 0, CullInside, 0},                               // 5
+
+		{&Sphere{Center: glm.Vec3{Z: -50}, Radius: 1}, FrustumAllPlanes, CullInside, 0},           // 6
+		{&Sphere{Center: glm.Vec3{X: 60, Z: -50}, Radius: 1}, FrustumAllPlanes, CullOutside, 0},   // 7
+		{&Sphere{Center: glm.Vec3{Z: -1.5}, Radius: 1}, FrustumAllPlanes, CullIntersect, near},    // 8
+		{&Sphere{Center: glm.Vec3{Z: -1.5}, Radius: 1}, FrustumAllPlanes &^ near, CullInside, 0},  // 9
+		{&Sphere{Center: glm.Vec3{Z: -50}, Radius: math.NaN()}, FrustumAllPlanes, CullOutside, 0}, // 10
+
+		{&OBB{Center: glm.Vec3{Z: -50}, Orientation: rotz, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, FrustumAllPlanes, CullInside, 0},                  // 11
+		{&OBB{Center: glm.Vec3{X: 51.2, Z: -50}, Orientation: ident, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, FrustumAllPlanes, CullIntersect, right}, // 12
+		{&OBB{Center: glm.Vec3{X: 51.2, Z: -50}, Orientation: rotz, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, FrustumAllPlanes, CullIntersect, right},  // 13
+		{&OBB{Center: glm.Vec3{X: 53, Z: -50}, Orientation: rotz, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, FrustumAllPlanes, CullOutside, 0},          // 14
+
+		{&Capsule{A: glm.Vec3{X: -5, Z: -50}, B: glm.Vec3{X: 5, Z: -50}, Radius: 1}, FrustumAllPlanes, CullInside, 0},                 // 15
+		{&Capsule{A: glm.Vec3{X: 40, Z: -50}, B: glm.Vec3{X: 50, Z: -50}, Radius: 1}, FrustumAllPlanes, CullIntersect, right},         // 16
+		{&Capsule{A: glm.Vec3{X: 0, Z: 5}, B: glm.Vec3{X: 0, Z: -500}, Radius: 1}, FrustumAllPlanes, CullIntersect, FrustumAllPlanes}, // 17
+		{&Capsule{A: glm.Vec3{X: 0, Z: 5}, B: glm.Vec3{X: 0, Z: 2}, Radius: 1}, FrustumAllPlanes, CullOutside, 0},                     // 18
+
+		{cubeHull(glm.Vec3{Z: -50}, 1), FrustumAllPlanes, CullInside, 0},                 // 19
+		{cubeHull(glm.Vec3{X: 50, Z: -50}, 1), FrustumAllPlanes, CullIntersect, right},   // 20
+		{cubeHull(glm.Vec3{X: 50, Z: -50}, 1), FrustumAllPlanes &^ right, CullInside, 0}, // 21
+		{cubeHull(glm.Vec3{Z: 50}, 1), FrustumAllPlanes, CullOutside, 0},                 // 22
+	}
+	for i, test := range tests {
+		result, out := cull(test.shape, test.mask)
+		if result != test.result || out != test.out {
+			t.Errorf("[%d] cull = %d %06b, want %d %06b", i, result, out, test.result, test.out)
+		}
+	}
+}
+
+func TestTestFrustumFrustum(t *testing.T) {
+	proj := glm.Perspective(math.Pi/2, 1, 1, 10)
+	camera := FrustumFromMatrix(&proj)
+	tests := []struct {
+		view      glm.Mat4
+		result    CullResult
+		intersect bool
+	}{
+		{glm.Translate3D(0, 0, 0), CullIntersect, true},    // 0
+		{glm.Translate3D(0, 0, -50), CullOutside, false},   // 1 the other frustum is behind.
+		{glm.Translate3D(0, 0, -100), CullOutside, false},  // 2
+		{glm.Translate3D(-25, 0, 5), CullIntersect, false}, // 3 only a false positive of the culling test.
+		{glm.Translate3D(-10, 0, 5), CullIntersect, true},  // 4
+	}
+	for i, test := range tests {
+		// the other frustum looks along +x from view^-1.
+		rot := glm.HomogRotate3DY(-math.Pi / 2)
+		view := rot.Mul4(&test.view)
+		viewProj := proj.Mul4(&view)
+		other := FrustumFromMatrix(&viewProj)
+		if got := TestFrustumFrustum(&camera, &other); got != test.intersect {
+			t.Errorf("[%d] TestFrustumFrustum = %t, want %t", i, got, test.intersect)
+		}
+		if got := TestFrustumFrustum(&other, &camera); got != test.intersect {
+			t.Errorf("[%d] swapped TestFrustumFrustum = %t, want %t", i, got, test.intersect)
+		}
+		if got, _ := CullFrustumFrustum(&camera, &other, FrustumAllPlanes); got != test.result {
+			t.Errorf("[%d] CullFrustumFrustum = %d, want %d", i, got, test.result)
+		}
+	}
+
+	// a small frustum entirely inside the camera.
+	small := glm.Perspective(math.Pi/8, 1, 1, 2)
+	view := glm.Translate3D(0, 0, 3)
+	viewProj := small.Mul4(&view)
+	other := FrustumFromMatrix(&viewProj)
+	if got, mask := CullFrustumFrustum(&camera, &other, FrustumAllPlanes); got != CullInside || mask != 0 {
+		t.Errorf("CullFrustumFrustum = %d %06b, want %d 0", got, mask, CullInside)
+	}
+	if !TestFrustumFrustum(&camera, &other) {
+		t.Errorf("TestFrustumFrustum = false, want true")
+	}
+}
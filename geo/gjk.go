@@ -0,0 +1,309 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+)
+
+const (
+	// gjkMaxIterations bounds the number of support points GJK looks for.
+	// When it runs out GJK didn't converge and the shapes are considered
+	// apart.
+	gjkMaxIterations = 64
+
+	// gjkEpsilon is how much closer to the origin, relative to the size of the
+	// Minkowski difference, a new support point needs to be for GJK to keep
+	// going.
+	gjkEpsilon = 1e-6
+
+	// gjkTolerance is how close to the origin, relative to the size of the
+	// Minkowski difference, GJKDistance needs to get to consider the shapes
+	// touching.
+	gjkTolerance = 1e-5
+)
+
+// TestGJK returns true if the convex shapes described by these support
+// functions overlap. Touching shapes overlap, shapes GJK can't tell apart in
+// gjkMaxIterations don't.
+// [Gilbert88], [vandenBergen03]
+func TestGJK(a, b Supporter) bool {
+	_, dist := gjk(a, b)
+	return dist == 0
+}
+
+// minkowskiSupport returns the point of the Minkowski difference a-b that is
+// the most in the given direction.
+func minkowskiSupport(a, b Supporter, direction *glm.Vec3) glm.Vec3 {
+	id := direction.Inverse()
+	sa, sb := a.Support(direction), b.Support(&id)
+	return sa.Sub(&sb)
+}
+
+// ShapeSupporter returns the support function of the given shape, shapes
+// without a Support method of their own, like convex hulls, are wrapped.
+func ShapeSupporter(s Shape) Supporter {
+	switch s := s.(type) {
+	case *Convexhull:
+		return s.Supporter()
+	case Supporter:
+		return s
+	}
+	panic("geo: shape has no support function")
+}
+
+// GJKDistance returns the distance between the convex shapes described by these
+// support functions and the points of a and b closest to each other. When the
+// shapes overlap the distance is 0 and the points shouldn't be used. If GJK
+// runs out of iterations the distance is the closest it got, it's never 0.
+// [Gilbert88], [vandenBergen03]
+func GJKDistance(a, b Supporter) (dist float32, pa, pb glm.Vec3) {
+	s, dist := gjk(a, b)
+	pa, pb = s.witnesses()
+	return dist, pa, pb
+}
+
+// gjk runs GJK on the Minkowski difference a-b and returns the last simplex
+// and the distance between the shapes, 0 if they overlap. The simplex then
+// usually is a tetrahedron containing the origin but it can be smaller for
+// shapes that only touch.
+func gjk(a, b Supporter) (gjkSimplex, float32) {
+	var s gjkSimplex
+	D := glm.Vec3{X: 1}
+	p := gjkSupport(a, b, &D)
+	s.merge(&p)
+	s.weights[0] = 1
+	v := s.Points[0]
+	for i := 0; i < gjkMaxIterations; i++ {
+		vv := v.Len2()
+		if vv <= gjkTolerance*gjkTolerance*s.maxLen2() {
+			return s, 0
+		}
+		D = v.Inverse()
+		p := gjkSupport(a, b, &D)
+		// The new point isn't closer to the origin than v, v is as close as it
+		// gets.
+		if vv-v.Dot(&p.w) <= gjkEpsilon*vv {
+			break
+		}
+		prev := s
+		s.merge(&p)
+		next, contain := s.closest()
+		if contain {
+			return s, 0
+		}
+		if next.Len2() >= vv {
+			s = prev
+			break
+		}
+		v = next
+	}
+	return s, v.Len()
+}
+
+// gjkVertex is a point of the Minkowski difference a-b and the support points
+// of a and b it comes from.
+type gjkVertex struct {
+	w, a, b glm.Vec3
+}
+
+// gjkSupport returns the vertex of the Minkowski difference a-b that is the
+// most in the given direction.
+func gjkSupport(a, b Supporter, direction *glm.Vec3) gjkVertex {
+	id := direction.Inverse()
+	v := gjkVertex{a: a.Support(direction), b: b.Support(&id)}
+	v.w = v.a.Sub(&v.b)
+	return v
+}
+
+// gjkSimplex is a Simplex of the Minkowski difference that remembers the points
+// of a and b its points come from. weights are the barycentric coordinates of
+// the point of the simplex closest to the origin.
+type gjkSimplex struct {
+	Simplex
+	a, b    [4]glm.Vec3
+	weights [4]float32
+}
+
+// merge adds the vertex to the simplex.
+func (s *gjkSimplex) merge(v *gjkVertex) {
+	s.a[s.Size], s.b[s.Size] = v.a, v.b
+	s.Merge(&v.w)
+}
+
+// maxLen2 returns the largest squared length of the vertices of the simplex.
+func (s *gjkSimplex) maxLen2() float32 {
+	var max float32
+	for i := 0; i < s.Size; i++ {
+		if l := s.Points[i].Len2(); l > max {
+			max = l
+		}
+	}
+	return max
+}
+
+// witnesses returns the points of a and b matching the point of the simplex
+// closest to the origin.
+func (s *gjkSimplex) witnesses() (pa, pb glm.Vec3) {
+	for i := 0; i < s.Size; i++ {
+		pa.AddScaledVec(s.weights[i], &s.a[i])
+		pb.AddScaledVec(s.weights[i], &s.b[i])
+	}
+	return pa, pb
+}
+
+// closest reduces the simplex to the smallest one containing the point closest
+// to the origin, sets the weights of that point and returns it. It returns true
+// if the simplex is a tetrahedron containing the origin.
+func (s *gjkSimplex) closest() (glm.Vec3, bool) {
+	switch s.Size {
+	case 4:
+		if s.reduce4() {
+			return glm.Vec3{}, true
+		}
+	case 3:
+		s.reduce3()
+	case 2:
+		s.reduce2()
+	default:
+		s.weights[0] = 1
+	}
+	var v glm.Vec3
+	for i := 0; i < s.Size; i++ {
+		v.AddScaledVec(s.weights[i], &s.Points[i])
+	}
+	return v, false
+}
+
+// keep reduces the simplex to the given vertices with the given weights.
+func (s *gjkSimplex) keep(indices []int, weights ...float32) {
+	r := *s
+	for i, n := range indices {
+		r.Points[i], r.a[i], r.b[i] = s.Points[n], s.a[n], s.b[n]
+		r.weights[i] = weights[i]
+	}
+	r.Size = len(indices)
+	*s = r
+}
+
+func (s *gjkSimplex) reduce2() {
+	a, b := &s.Points[0], &s.Points[1]
+	ab := b.Sub(a)
+	t := -a.Dot(&ab)
+	if t <= 0 {
+		s.keep([]int{0}, 1)
+		return
+	}
+	denom := ab.Dot(&ab)
+	if t >= denom {
+		s.keep([]int{1}, 1)
+		return
+	}
+	t /= denom
+	s.weights[0], s.weights[1] = 1-t, t
+}
+
+// reduce3 finds the feature of the triangle closest to the origin, see
+// ClosestPointPointTriangle.
+func (s *gjkSimplex) reduce3() {
+	a, b, c := &s.Points[0], &s.Points[1], &s.Points[2]
+	ab, ac := b.Sub(a), c.Sub(a)
+
+	// vertex region outside a
+	d1, d2 := -ab.Dot(a), -ac.Dot(a)
+	if d1 <= 0 && d2 <= 0 {
+		s.keep([]int{0}, 1)
+		return
+	}
+
+	// vertex region outside b
+	d3, d4 := -ab.Dot(b), -ac.Dot(b)
+	if d3 >= 0 && d4 <= d3 {
+		s.keep([]int{1}, 1)
+		return
+	}
+
+	// edge region of ab
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		t := d1 / (d1 - d3)
+		s.keep([]int{0, 1}, 1-t, t)
+		return
+	}
+
+	// vertex region outside c
+	d5, d6 := -ab.Dot(c), -ac.Dot(c)
+	if d6 >= 0 && d5 <= d6 {
+		s.keep([]int{2}, 1)
+		return
+	}
+
+	// edge region of ac
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		t := d2 / (d2 - d6)
+		s.keep([]int{0, 2}, 1-t, t)
+		return
+	}
+
+	// edge region of bc
+	va := d3*d6 - d5*d4
+	if va <= 0 && d4-d3 >= 0 && d5-d6 >= 0 {
+		t := (d4 - d3) / ((d4 - d3) + (d5 - d6))
+		s.keep([]int{1, 2}, 1-t, t)
+		return
+	}
+
+	// face region
+	denom := 1 / (va + vb + vc)
+	v, w := vb*denom, vc*denom
+	s.weights[0], s.weights[1], s.weights[2] = 1-v-w, v, w
+}
+
+// gjkFaces are the faces of a tetrahedron simplex, the last index is the
+// vertex opposite of the face.
+var gjkFaces = [4][4]int{
+	{0, 1, 2, 3},
+	{0, 1, 3, 2},
+	{0, 2, 3, 1},
+	{1, 2, 3, 0},
+}
+
+// reduce4 reduces the tetrahedron to the face closest to the origin. It returns
+// true if the origin is inside the tetrahedron.
+func (s *gjkSimplex) reduce4() bool {
+	var zero glm.Vec3
+	// A flat tetrahedron contains nothing, the closest point is on one of its
+	// faces. Otherwise only the faces the origin is in front of are checked, if
+	// there are none the origin is inside.
+	ab, ac, ad := s.Points[1].Sub(&s.Points[0]), s.Points[2].Sub(&s.Points[0]), s.Points[3].Sub(&s.Points[0])
+	abac := ab.Cross(&ac)
+	scale := s.maxLen2()
+	solid := math.Abs(abac.Dot(&ad)) > gjkEpsilon*scale*math.Sqrt(scale)
+	best := gjkSimplex{}
+	bestLen2 := float32(-1)
+	for _, f := range gjkFaces {
+		a, b, c, d := &s.Points[f[0]], &s.Points[f[1]], &s.Points[f[2]], &s.Points[f[3]]
+		if solid && !PointsOnOppositeSideOfPlane(&zero, d, a, b, c) && !pointOnPlane(&zero, a, b, c) {
+			continue
+		}
+		face := *s
+		face.keep(f[:3], 0, 0, 0)
+		v, _ := face.closest()
+		if l := v.Len2(); bestLen2 < 0 || l < bestLen2 {
+			best, bestLen2 = face, l
+		}
+	}
+	if bestLen2 < 0 {
+		return true
+	}
+	*s = best
+	return false
+}
+
+// pointOnPlane returns true if p is on the plane of the triangle abc, or if
+// abc is degenerate.
+func pointOnPlane(p, a, b, c *glm.Vec3) bool {
+	ap, ab, ac := p.Sub(a), b.Sub(a), c.Sub(a)
+	abac := ab.Cross(&ac)
+	return ap.Dot(&abac) == 0
+}
@@ -0,0 +1,199 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"math/rand"
+	"testing"
+)
+
+// cubeHull returns the convex hull of a cube of the given half size centered on
+// center.
+func cubeHull(center glm.Vec3, half float32) *Convexhull {
+	var points []glm.Vec3
+	for _, x := range []float32{-half, half} {
+		for _, y := range []float32{-half, half} {
+			for _, z := range []float32{-half, half} {
+				points = append(points, glm.Vec3{X: center.X + x, Y: center.Y + y, Z: center.Z + z})
+			}
+		}
+	}
+	hull := Quickhull(points)
+	hull.CalculateInternals()
+	return hull
+}
+
+// vec3Near returns whether a and b are less than eps apart.
+func vec3Near(a, b glm.Vec3, eps float32) bool {
+	d := a.Sub(&b)
+	return d.Len() <= eps
+}
+
+func TestTestGJK(t *testing.T) {
+	ident := glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}
+	// rotated 45 degrees around z.
+	const h = 0.70710678
+	rotz := glm.Mat3{h, h, 0, -h, h, 0, 0, 0, 1}
+	tests := []struct {
+		a, b      Supporter
+		intersect bool
+	}{
+		{
+			&Sphere{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, Radius: 1},
+			&Sphere{Center: glm.Vec3{X: 1.5, Y: 0, Z: 0}, Radius: 1},
+			true,
+		},
+		{
+			&Sphere{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, Radius: 1},
+			&Sphere{Center: glm.Vec3{X: 0, Y: 2.5, Z: 0}, Radius: 1},
+			false,
+		},
+		{
+			&AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+			true,
+		},
+		{
+			&AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&AABB{Center: glm.Vec3{X: 3, Y: 3, Z: 3}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			false,
+		},
+		{
+			&OBB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, Orientation: ident, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&OBB{Center: glm.Vec3{X: 2.3, Y: 0, Z: 0}, Orientation: rotz, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			true,
+		},
+		{
+			&OBB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, Orientation: ident, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&OBB{Center: glm.Vec3{X: 2.5, Y: 0, Z: 0}, Orientation: rotz, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			false,
+		},
+		{
+			&Capsule{A: glm.Vec3{X: -1, Y: 0, Z: 0}, B: glm.Vec3{X: 1, Y: 0, Z: 0}, Radius: 0.5},
+			&Capsule{A: glm.Vec3{X: 0, Y: 0.9, Z: -1}, B: glm.Vec3{X: 0, Y: 0.9, Z: 1}, Radius: 0.5},
+			true,
+		},
+		{
+			&Capsule{A: glm.Vec3{X: -1, Y: 0, Z: 0}, B: glm.Vec3{X: 1, Y: 0, Z: 0}, Radius: 0.5},
+			&Capsule{A: glm.Vec3{X: 0, Y: 1.1, Z: -1}, B: glm.Vec3{X: 0, Y: 1.1, Z: 1}, Radius: 0.5},
+			false,
+		},
+		{
+			cubeHull(glm.Vec3{}, 1).Supporter(),
+			&Capsule{A: glm.Vec3{X: 1.4, Y: -5, Z: 0}, B: glm.Vec3{X: 1.4, Y: 5, Z: 0}, Radius: 0.5},
+			true,
+		},
+		{
+			cubeHull(glm.Vec3{}, 1).Supporter(),
+			&Capsule{A: glm.Vec3{X: 1.6, Y: -5, Z: 0}, B: glm.Vec3{X: 1.6, Y: 5, Z: 0}, Radius: 0.5},
+			false,
+		},
+		{
+			cubeHull(glm.Vec3{}, 1).Supporter(),
+			&OBB{Center: glm.Vec3{X: 2.3, Y: 0, Z: 0}, Orientation: rotz, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			true,
+		},
+		{
+			cubeHull(glm.Vec3{}, 1).Supporter(),
+			&OBB{Center: glm.Vec3{X: 2.5, Y: 0.5, Z: 0}, Orientation: rotz, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			false,
+		},
+		{
+			// touching.
+			&AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&AABB{Center: glm.Vec3{X: 2, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			true,
+		},
+	}
+	for i, test := range tests {
+		if intersect := TestGJK(test.a, test.b); intersect != test.intersect {
+			t.Errorf("[%d] TestGJK(a, b) = %t, want %t", i, intersect, test.intersect)
+		}
+		if intersect := TestGJK(test.b, test.a); intersect != test.intersect {
+			t.Errorf("[%d] TestGJK(b, a) = %t, want %t", i, intersect, test.intersect)
+		}
+	}
+}
+
+// creepingSupporter is a point that gets closer to the origin every time its
+// support point is asked, GJK never converges on it.
+type creepingSupporter struct {
+	n int
+}
+
+func (c *creepingSupporter) Support(direction *glm.Vec3) glm.Vec3 {
+	c.n++
+	return glm.Vec3{X: 1 / float32(c.n)}
+}
+
+func TestTestGJK_NotConverged(t *testing.T) {
+	if TestGJK(&creepingSupporter{}, &Sphere{}) {
+		t.Error("TestGJK = true for shapes that never converged, want false")
+	}
+	var c creepingSupporter
+	if dist, _, _ := GJKDistance(&c, &Sphere{}); dist == 0 || c.n <= gjkMaxIterations {
+		t.Errorf("GJKDistance = %f after %d support points, want > 0 after %d", dist, c.n, gjkMaxIterations)
+	}
+}
+
+func TestTestGJK_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	vec := func(scale float32) glm.Vec3 {
+		return glm.Vec3{
+			X: (r.Float32()*2 - 1) * scale,
+			Y: (r.Float32()*2 - 1) * scale,
+			Z: (r.Float32()*2 - 1) * scale,
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		s0 := Sphere{Center: vec(3), Radius: r.Float32() + 0.1}
+		s1 := Sphere{Center: vec(3), Radius: r.Float32() + 0.1}
+		d := s1.Center.Sub(&s0.Center)
+		if gap := d.Len() - s0.Radius - s1.Radius; gap > -1e-3 && gap < 1e-3 {
+			continue
+		}
+		if got, want := TestGJK(&s0, &s1), TestSphereSphere(&s0, &s1); got != want {
+			t.Errorf("[%d] TestGJK(%v, %v) = %t, want %t", i, s0, s1, got, want)
+		}
+
+		a0 := AABB{Center: vec(3), HalfExtend: glm.Vec3{X: r.Float32() + 0.1, Y: r.Float32() + 0.1, Z: r.Float32() + 0.1}}
+		a1 := AABB{Center: vec(3), HalfExtend: glm.Vec3{X: r.Float32() + 0.1, Y: r.Float32() + 0.1, Z: r.Float32() + 0.1}}
+		if got, want := TestGJK(&a0, &a1), TestAABBAABB(&a0, &a1); got != want {
+			t.Errorf("[%d] TestGJK(%v, %v) = %t, want %t", i, a0, a1, got, want)
+		}
+	}
+}
+
+func TestTestShapeShape(t *testing.T) {
+	ident := glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}
+	shapes := func(offset float32) []Shape {
+		c := glm.Vec3{X: offset, Y: 0, Z: 0}
+		return []Shape{
+			&AABB{Center: c, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&Sphere{Center: c, Radius: 1},
+			&OBB{Center: c, Orientation: ident, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&Capsule{A: glm.Vec3{X: offset, Y: -1, Z: 0}, B: glm.Vec3{X: offset, Y: 1, Z: 0}, Radius: 0.5},
+			cubeHull(c, 1),
+		}
+	}
+	near, far := shapes(0), shapes(10)
+	for _, a := range near {
+		for _, b := range near {
+			if !TestShapeShape(a, b) {
+				t.Errorf("TestShapeShape(%T, %T) = false, want true", a, b)
+			}
+		}
+		for _, b := range far {
+			if TestShapeShape(a, b) {
+				t.Errorf("TestShapeShape(%T, far %T) = true, want false", a, b)
+			}
+		}
+	}
+}
+
+func BenchmarkTestGJK(b *testing.B) {
+	hull := cubeHull(glm.Vec3{}, 1).Supporter()
+	capsule := Capsule{A: glm.Vec3{X: 1.4, Y: -5, Z: 0}, B: glm.Vec3{X: 1.4, Y: 5, Z: 0}, Radius: 0.5}
+	for n := 0; n < b.N; n++ {
+		TestGJK(hull, &capsule)
+	}
+}
@@ -27,8 +27,6 @@ type (
 		Conflicts []Conflict
 
 		Plane
-		// tmp variables
-		Visited bool
 	}
 
 	// Conflict is a vertex that isn't inside the convex hull yet
@@ -38,30 +36,6 @@ type (
 	}
 )
 
-// FindHorizon finds the horizon of the conflict
-func FindHorizon(face *Face, edge *Edge, point *glm.Vec3) []*Edge {
-	face.Visited = true
-	var horizon []*Edge
-	for n := 0; n < 3; n++ {
-		if DistToPlane(&edge.Twin.Face.Plane, point) < 0 {
-			horizon = append(horizon, edge)
-			edge = edge.Next
-			continue
-		} else {
-		}
-		// if we can see it
-		if edge.Twin.Face.Visited {
-			edge = edge.Next
-			continue
-		}
-
-		horizon = append(horizon, FindHorizon(edge.Twin.Face, edge.Twin, point)...)
-		edge = edge.Next
-	}
-
-	return horizon
-}
-
 // FindExtremums returns the 6 indices and 6 vec3 of the extremums for each axis
 // fomatted [minx, miny, minz, maxx, maxy, maxz].
 func FindExtremums(points []glm.Vec3) (extremumIndices [6]int, extremums [6]glm.Vec3) {
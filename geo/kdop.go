@@ -0,0 +1,220 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+)
+
+// KDOP is a k-DOP, a discrete oriented polytope bounded by k/2 slabs of fixed
+// directions. More directions bound diagonal and elongated shapes tighter but
+// take longer to test. k-DOPs can only be tested and merged with k-DOPs of the
+// same directions.
+type KDOP struct {
+	Slabs []Slab
+}
+
+// The slab directions of the common k-DOPs. The 14-DOP adds the corners of a
+// cube to the axes, the 18-DOP its edges and the 26-DOP both.
+var (
+	DOP14Directions = normalizedDirections(axesDirections, cornersDirections)
+	DOP18Directions = normalizedDirections(axesDirections, edgesDirections)
+	DOP26Directions = normalizedDirections(axesDirections, cornersDirections, edgesDirections)
+)
+
+var (
+	axesDirections    = []glm.Vec3{{X: 1}, {Y: 1}, {Z: 1}}
+	cornersDirections = []glm.Vec3{{X: 1, Y: 1, Z: 1}, {X: 1, Y: 1, Z: -1}, {X: 1, Y: -1, Z: 1}, {X: -1, Y: 1, Z: 1}}
+	edgesDirections   = []glm.Vec3{{X: 1, Y: 1}, {X: 1, Y: -1}, {X: 1, Z: 1}, {X: 1, Z: -1}, {Y: 1, Z: 1}, {Y: 1, Z: -1}}
+)
+
+// normalizedDirections returns the unit length directions of all the sets.
+func normalizedDirections(sets ...[]glm.Vec3) []glm.Vec3 {
+	var directions []glm.Vec3
+	for _, set := range sets {
+		for i := range set {
+			directions = append(directions, set[i].Normalized())
+		}
+	}
+	return directions
+}
+
+// NewKDOP returns an empty k-DOP with slabs along the unit length directions.
+func NewKDOP(directions []glm.Vec3) KDOP {
+	k := KDOP{Slabs: make([]Slab, len(directions))}
+	for i := range directions {
+		k.Slabs[i] = Slab{Normal: directions[i], Near: math.MaxFloat32, Far: -math.MaxFloat32}
+	}
+	return k
+}
+
+// KDOPFromPoints returns the smallest k-DOP with slabs along the unit length
+// directions enclosing the points.
+func KDOPFromPoints(directions, points []glm.Vec3) KDOP {
+	k := NewKDOP(directions)
+	k.addPoints(points)
+	return k
+}
+
+// addPoints grows the k-DOP to enclose the points.
+func (k *KDOP) addPoints(points []glm.Vec3) {
+	for i := range k.Slabs {
+		s := &k.Slabs[i]
+		for n := range points {
+			d := s.Normal.Dot(&points[n])
+			s.Near = math.Min(s.Near, d)
+			s.Far = math.Max(s.Far, d)
+		}
+	}
+}
+
+// KDOPFromAABB returns the smallest k-DOP with slabs along the unit length
+// directions enclosing the aabb.
+func KDOPFromAABB(directions []glm.Vec3, aabb *AABB) KDOP {
+	k := NewKDOP(directions)
+	for i := range k.Slabs {
+		s := &k.Slabs[i]
+		c := s.Normal.Dot(&aabb.Center)
+		r := aabb.HalfExtend.X*math.Abs(s.Normal.X) +
+			aabb.HalfExtend.Y*math.Abs(s.Normal.Y) +
+			aabb.HalfExtend.Z*math.Abs(s.Normal.Z)
+		s.Near, s.Far = c-r, c+r
+	}
+	return k
+}
+
+// AABBFromKDOP returns the smallest aabb enclosing the k-DOP.
+func AABBFromKDOP(k *KDOP) AABB {
+	return AABBFromPoints(k.Vertices())
+}
+
+// TestKDOPKDOP returns true if the k-DOPs intersect.
+func TestKDOPKDOP(a, b *KDOP) bool {
+	for i := range a.Slabs {
+		if a.Slabs[i].Near > b.Slabs[i].Far || a.Slabs[i].Far < b.Slabs[i].Near {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeKDOP returns the smallest k-DOP enclosing both a and b.
+func MergeKDOP(a, b *KDOP) KDOP {
+	m := KDOP{Slabs: make([]Slab, len(a.Slabs))}
+	for i := range m.Slabs {
+		m.Slabs[i] = Slab{
+			Normal: a.Slabs[i].Normal,
+			Near:   math.Min(a.Slabs[i].Near, b.Slabs[i].Near),
+			Far:    math.Max(a.Slabs[i].Far, b.Slabs[i].Far),
+		}
+	}
+	return m
+}
+
+// UpdateKDOP4 recomputes fill, keeping its directions, to enclose the vertices
+// transformed by t. The k-DOP of a rotating object can't be rotated directly,
+// use the vertices of its hull or of its k-DOP in local space, see Vertices.
+func UpdateKDOP4(fill *KDOP, vertices []glm.Vec3, t *glm.Mat4) {
+	for i := range fill.Slabs {
+		s := &fill.Slabs[i]
+		// project on the direction brought back in local space instead of
+		// transforming every vertex.
+		n := glm.Vec3{
+			X: t[0]*s.Normal.X + t[1]*s.Normal.Y + t[2]*s.Normal.Z,
+			Y: t[4]*s.Normal.X + t[5]*s.Normal.Y + t[6]*s.Normal.Z,
+			Z: t[8]*s.Normal.X + t[9]*s.Normal.Y + t[10]*s.Normal.Z,
+		}
+		offset := t[12]*s.Normal.X + t[13]*s.Normal.Y + t[14]*s.Normal.Z
+		s.Near, s.Far = math.MaxFloat32, -math.MaxFloat32
+		for v := range vertices {
+			d := n.Dot(&vertices[v]) + offset
+			s.Near = math.Min(s.Near, d)
+			s.Far = math.Max(s.Far, d)
+		}
+	}
+}
+
+// Vertices returns the corners of the k-DOP, every point where 3 of its planes
+// meet inside the others. It's slow, compute them once.
+func (k *KDOP) Vertices() []glm.Vec3 {
+	planes := make([]Plane, 0, 2*len(k.Slabs))
+	var scale float32
+	for _, s := range k.Slabs {
+		planes = append(planes, Plane{Normal: s.Normal, Offset: s.Far}, Plane{Normal: s.Normal.Inverse(), Offset: -s.Near})
+		scale = math.Max(scale, math.Max(math.Abs(s.Near), math.Abs(s.Far)))
+	}
+	epsilon := 1e-4 * (1 + scale)
+
+	var vertices []glm.Vec3
+	for i := range planes {
+		for j := i + 1; j < len(planes); j++ {
+			for l := j + 1; l < len(planes); l++ {
+				p0, p1, p2 := &planes[i], &planes[j], &planes[l]
+				u := p1.Normal.Cross(&p2.Normal)
+				if math.Abs(p0.Normal.Dot(&u)) < 1e-4 {
+					continue
+				}
+				v := intersectPlanes(p0, p1, p2)
+				if k.contains(&v, epsilon) && !containsNear(vertices, &v, epsilon) {
+					vertices = append(vertices, v)
+				}
+			}
+		}
+	}
+	return vertices
+}
+
+// contains returns true if p is inside every slab, give or take epsilon.
+func (k *KDOP) contains(p *glm.Vec3, epsilon float32) bool {
+	for i := range k.Slabs {
+		d := k.Slabs[i].Normal.Dot(p)
+		if d < k.Slabs[i].Near-epsilon || d > k.Slabs[i].Far+epsilon {
+			return false
+		}
+	}
+	return true
+}
+
+// containsNear returns true if one of the points is less than epsilon away
+// from p.
+func containsNear(points []glm.Vec3, p *glm.Vec3, epsilon float32) bool {
+	for i := range points {
+		if d := points[i].Sub(p); d.Len2() <= epsilon*epsilon {
+			return true
+		}
+	}
+	return false
+}
+
+// IntersectRayKDOP intersect ray R(t) = p + t*d against k-DOP k. When
+// intersecting, return intersection distance t and point q of intersection.
+func IntersectRayKDOP(p, d *glm.Vec3, k *KDOP) (t float32, q glm.Vec3, intersect bool) {
+	const epsilon = 0.00001
+	tmax := float32(math.MaxFloat32)
+	for i := range k.Slabs {
+		s := &k.Slabs[i]
+		dist, denom := s.Normal.Dot(p), s.Normal.Dot(d)
+		if math.Abs(denom) < epsilon {
+			// Ray is parallel to slab. No hit if origin not within slab
+			if !(dist >= s.Near && dist <= s.Far) {
+				return
+			}
+			continue
+		}
+		ood := 1 / denom
+		t1, t2 := (s.Near-dist)*ood, (s.Far-dist)*ood
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		t = math.Max(t, t1)
+		tmax = math.Min(tmax, t2)
+		// Exit with no collision as soon as slab intersection becomes empty,
+		// or is NaN.
+		if !(t <= tmax) || !(t1 <= t2) {
+			return
+		}
+	}
+	q = *p
+	q.AddScaledVec(t, d)
+	intersect = true
+	return
+}
@@ -0,0 +1,278 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"testing"
+)
+
+// unitSpherePoints returns the points of the unit sphere along the 26-DOP
+// directions, the 26-DOP around them is as big as the one around the sphere.
+func unitSpherePoints() []glm.Vec3 {
+	var points []glm.Vec3
+	for _, d := range DOP26Directions {
+		points = append(points, d, d.Inverse())
+	}
+	return points
+}
+
+// rod returns the ends of a rod from a to b.
+func rod(a, b glm.Vec3) []glm.Vec3 {
+	return []glm.Vec3{a, b}
+}
+
+func slabsNear(a, b *KDOP, eps float32) bool {
+	if len(a.Slabs) != len(b.Slabs) {
+		return false
+	}
+	for i := range a.Slabs {
+		if !vec3Near(a.Slabs[i].Normal, b.Slabs[i].Normal, eps) ||
+			math.Abs(a.Slabs[i].Near-b.Slabs[i].Near) > eps ||
+			math.Abs(a.Slabs[i].Far-b.Slabs[i].Far) > eps {
+			return false
+		}
+	}
+	return true
+}
+
+func TestKDOPFromPoints(t *testing.T) {
+	sqrt2, sqrt3 := math.Sqrt(2), math.Sqrt(3)
+	tests := []struct {
+		directions []glm.Vec3
+		points     []glm.Vec3
+		near, far  []float32
+	}{
+		{ // 0
+			DOP14Directions,
+			[]glm.Vec3{{}, {X: 1, Y: 1, Z: 1}},
+			[]float32{0, 0, 0, 0, 0, 0, 0},
+			[]float32{1, 1, 1, sqrt3, 1 / sqrt3, 1 / sqrt3, 1 / sqrt3},
+		},
+		{ // 1
+			DOP18Directions,
+			rod(glm.Vec3{X: -1, Y: -1, Z: 2}, glm.Vec3{X: 1, Y: 1, Z: 2}),
+			[]float32{-1, -1, 2, -sqrt2, 0, 1 / sqrt2, -3 / sqrt2, 1 / sqrt2, -3 / sqrt2},
+			[]float32{1, 1, 2, sqrt2, 0, 3 / sqrt2, -1 / sqrt2, 3 / sqrt2, -1 / sqrt2},
+		},
+	}
+	for i, test := range tests {
+		k := KDOPFromPoints(test.directions, test.points)
+		if len(k.Slabs) != len(test.near) {
+			t.Errorf("[%d] %d slabs, want %d", i, len(k.Slabs), len(test.near))
+			continue
+		}
+		for n, s := range k.Slabs {
+			if math.Abs(s.Near-test.near[n]) > 1e-5 || math.Abs(s.Far-test.far[n]) > 1e-5 {
+				t.Errorf("[%d] slab %d = [%f, %f], want [%f, %f]", i, n, s.Near, s.Far, test.near[n], test.far[n])
+			}
+		}
+	}
+}
+
+func TestKDOPFromAABB(t *testing.T) {
+	sqrt3 := math.Sqrt(3)
+	aabb := AABB{Center: glm.Vec3{X: 1}, HalfExtend: glm.Vec3{X: 1, Y: 2, Z: 3}}
+	k := KDOPFromAABB(DOP14Directions, &aabb)
+	// the same as the k-DOP of the corners of the box.
+	var corners []glm.Vec3
+	for _, x := range []float32{0, 2} {
+		for _, y := range []float32{-2, 2} {
+			for _, z := range []float32{-3, 3} {
+				corners = append(corners, glm.Vec3{X: x, Y: y, Z: z})
+			}
+		}
+	}
+	want := KDOPFromPoints(DOP14Directions, corners)
+	if !slabsNear(&k, &want, 1e-5) {
+		t.Errorf("k-DOP = %+v, want %+v", k, want)
+	}
+	if s := k.Slabs[3]; math.Abs(s.Near+5/sqrt3) > 1e-5 || math.Abs(s.Far-7/sqrt3) > 1e-5 {
+		t.Errorf("slab 3 = [%f, %f], want [%f, %f]", s.Near, s.Far, -5/sqrt3, 7/sqrt3)
+	}
+
+	for i, d := range [][]glm.Vec3{DOP14Directions, DOP18Directions, DOP26Directions} {
+		k := KDOPFromAABB(d, &aabb)
+		got := AABBFromKDOP(&k)
+		if !vec3Near(got.Center, aabb.Center, 1e-4) || !vec3Near(got.HalfExtend, aabb.HalfExtend, 1e-4) {
+			t.Errorf("[%d] AABBFromKDOP = %v, want %v", i, got, aabb)
+		}
+	}
+}
+
+func TestKDOP_Vertices(t *testing.T) {
+	cube := AABB{HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}
+	tests := []struct {
+		k        KDOP
+		vertices int
+	}{
+		{KDOPFromAABB(DOP14Directions, &cube), 8},                                         // 0
+		{KDOPFromAABB(DOP26Directions, &cube), 8},                                         // 1
+		{KDOPFromPoints(DOP14Directions, unitSpherePoints()), 24},                         // 2 a truncated octahedron.
+		{KDOPFromPoints(DOP26Directions, unitSpherePoints()), 0},                          // 3
+		{KDOPFromPoints(DOP18Directions, rod(glm.Vec3{}, glm.Vec3{X: 1, Y: 1, Z: 1})), 2}, // 4
+	}
+	for i, test := range tests {
+		vertices := test.k.Vertices()
+		if test.vertices != 0 && len(vertices) != test.vertices {
+			t.Errorf("[%d] %d vertices, want %d", i, len(vertices), test.vertices)
+		}
+		// the vertices span the whole k-DOP.
+		directions := make([]glm.Vec3, len(test.k.Slabs))
+		for n := range directions {
+			directions[n] = test.k.Slabs[n].Normal
+		}
+		if got := KDOPFromPoints(directions, vertices); !slabsNear(&got, &test.k, 1e-4) {
+			t.Errorf("[%d] the vertices k-DOP = %+v, want %+v", i, got, test.k)
+		}
+	}
+}
+
+func TestTestKDOPKDOP(t *testing.T) {
+	tests := []struct {
+		directions []glm.Vec3
+		a, b       []glm.Vec3
+		intersect  bool
+	}{
+		{ // 0
+			DOP14Directions,
+			rod(glm.Vec3{}, glm.Vec3{X: 1, Y: 1, Z: 1}),
+			rod(glm.Vec3{X: 1}, glm.Vec3{Y: 1}),
+			true,
+		},
+		{ // 1 the aabbs of these diagonal rods overlap.
+			DOP18Directions,
+			rod(glm.Vec3{}, glm.Vec3{X: 4, Y: 4}),
+			rod(glm.Vec3{X: 2}, glm.Vec3{X: 6, Y: 4}),
+			false,
+		},
+		{ // 2
+			DOP26Directions,
+			unitSpherePoints(),
+			rod(glm.Vec3{X: 0.9, Y: 0.9, Z: 0.9}, glm.Vec3{X: 2, Y: 2, Z: 2}),
+			false,
+		},
+		{ // 3
+			DOP26Directions,
+			unitSpherePoints(),
+			rod(glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}, glm.Vec3{X: 2, Y: 2, Z: 2}),
+			true,
+		},
+	}
+	for i, test := range tests {
+		a, b := KDOPFromPoints(test.directions, test.a), KDOPFromPoints(test.directions, test.b)
+		if intersect := TestKDOPKDOP(&a, &b); intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if intersect := TestKDOPKDOP(&b, &a); intersect != test.intersect {
+			t.Errorf("[%d] swapped intersect = %t, want %t", i, intersect, test.intersect)
+		}
+	}
+
+	a, b := AABBFromPoints(tests[1].a), AABBFromPoints(tests[1].b)
+	if !TestAABBAABB(&a, &b) {
+		t.Errorf("the aabbs of the rods don't overlap")
+	}
+}
+
+func TestMergeKDOP(t *testing.T) {
+	a := rod(glm.Vec3{X: -1, Y: 2}, glm.Vec3{X: 3, Y: 1, Z: 1})
+	b := rod(glm.Vec3{X: 5, Y: -2, Z: 1}, glm.Vec3{X: 2, Y: 2, Z: -3})
+	ka, kb := KDOPFromPoints(DOP26Directions, a), KDOPFromPoints(DOP26Directions, b)
+	got, want := MergeKDOP(&ka, &kb), KDOPFromPoints(DOP26Directions, append(a, b...))
+	if !slabsNear(&got, &want, 1e-6) {
+		t.Errorf("merged k-DOP = %+v, want %+v", got, want)
+	}
+}
+
+func TestUpdateKDOP4(t *testing.T) {
+	axis := glm.Vec3{X: 1, Y: 2, Z: 3}
+	axis.Normalize()
+	rotation := glm.HomogRotate3D(1.1, &axis)
+	translation := glm.Translate3D(1, -2, 3)
+	transform := translation.Mul4(&rotation)
+
+	local := []glm.Vec3{{X: -1, Y: 2}, {X: 3, Y: 1, Z: 1}, {X: 5, Y: -2, Z: 1}, {X: 2, Y: 2, Z: -3}}
+	world := make([]glm.Vec3, len(local))
+	for i := range local {
+		v := transform.Mul4x1(&glm.Vec4{X: local[i].X, Y: local[i].Y, Z: local[i].Z, W: 1})
+		world[i] = glm.Vec3{X: v.X, Y: v.Y, Z: v.Z}
+	}
+
+	for i, d := range [][]glm.Vec3{DOP14Directions, DOP18Directions, DOP26Directions} {
+		k := NewKDOP(d)
+		UpdateKDOP4(&k, local, &transform)
+		if want := KDOPFromPoints(d, world); !slabsNear(&k, &want, 1e-4) {
+			t.Errorf("[%d] k-DOP = %+v, want %+v", i, k, want)
+		}
+	}
+}
+
+func TestIntersectRayKDOP(t *testing.T) {
+	sphere := KDOPFromPoints(DOP26Directions, unitSpherePoints())
+	tests := []struct {
+		p, d      glm.Vec3
+		k         *KDOP
+		t         float32
+		q         glm.Vec3
+		intersect bool
+	}{
+		{ // 0
+			glm.Vec3{X: -5}, glm.Vec3{X: 1},
+			&sphere,
+			4, glm.Vec3{X: -1}, true,
+		},
+		{ // 1
+			glm.Vec3{X: -5, Y: -5}, glm.Vec3{X: 1, Y: 1},
+			&sphere,
+			5 - 1/math.Sqrt(2), glm.Vec3{X: -1 / math.Sqrt(2), Y: -1 / math.Sqrt(2)}, true,
+		},
+		{ // 2 past the edge slab, it would hit the aabb.
+			glm.Vec3{X: -5, Y: 0.95, Z: 0.95}, glm.Vec3{X: 1},
+			&sphere,
+			0, glm.Vec3{}, false,
+		},
+		{ // 3 inside.
+			glm.Vec3{X: 0.1}, glm.Vec3{X: 1, Y: 2},
+			&sphere,
+			0, glm.Vec3{X: 0.1}, true,
+		},
+		{ // 4
+			glm.Vec3{X: -5}, glm.Vec3{X: -1},
+			&sphere,
+			0, glm.Vec3{}, false,
+		},
+	}
+	for i, test := range tests {
+		tt, q, intersect := IntersectRayKDOP(&test.p, &test.d, test.k)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue
+		}
+		if math.Abs(tt-test.t) > 1e-4 || !vec3Near(q, test.q, 1e-4) {
+			t.Errorf("[%d] t, q = %f %v, want %f %v", i, tt, q, test.t, test.q)
+		}
+	}
+}
+
+func BenchmarkTestKDOPKDOP(b *testing.B) {
+	k0 := KDOPFromPoints(DOP26Directions, unitSpherePoints())
+	k1 := KDOPFromPoints(DOP26Directions, rod(glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}, glm.Vec3{X: 2, Y: 2, Z: 2}))
+	for n := 0; n < b.N; n++ {
+		TestKDOPKDOP(&k0, &k1)
+	}
+}
+
+func BenchmarkUpdateKDOP4(b *testing.B) {
+	local := KDOPFromPoints(DOP26Directions, unitSpherePoints())
+	vertices := local.Vertices()
+	axis := glm.Vec3{X: 1, Y: 2, Z: 3}
+	axis.Normalize()
+	transform := glm.HomogRotate3D(1.1, &axis)
+	k := NewKDOP(DOP26Directions)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		UpdateKDOP4(&k, vertices, &transform)
+	}
+}
@@ -0,0 +1,530 @@
+package geo
+
+import (
+	"sort"
+
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+)
+
+const (
+	// featureTolerance is the cosine of the angle under which a segment or a
+	// face is considered perpendicular to a direction.
+	featureTolerance = 0.02
+
+	// coplanarTolerance is how close to 1 the dot product of the normals of 2
+	// hull triangles needs to be for them to be part of the same face.
+	coplanarTolerance = 1e-4
+
+	// crossingTolerance is how close, relative to the sum of their radius,
+	// the cores of 2 shapes need to be to cross. The direction between closer
+	// cores is mostly float error.
+	crossingTolerance = 1e-5
+)
+
+// Manifold is the contact between 2 overlapping shapes.
+type Manifold struct {
+	// Normal points from the first shape to the second one.
+	Normal glm.Vec3
+
+	// Depth is how far the second shape needs to move along Normal to stop
+	// overlapping the first one.
+	Depth float32
+
+	// Points are the contact points, halfway between the surfaces of the
+	// shapes, and Depths how deep each of them is. Only the first Len are
+	// used.
+	Points [4]glm.Vec3
+	Depths [4]float32
+	Len    int
+}
+
+// ContactShapeShape returns the contact manifold of 2 shapes and true if they
+// overlap. Shapes that only touch may not have a contact.
+//
+// Spheres and capsules whose cores don't overlap get their normal and depth
+// from the distance between the cores. 2 spheres or capsules whose cores cross
+// are pushed apart perpendicular to them by the sum of their radius. Only the
+// other pairs use EPA. The contact points come from the features of the shapes
+// the most in the direction of the normal, faces are clipped against each other
+// with Sutherland-Hodgman.
+func ContactShapeShape(s0, s1 Shape) (Manifold, bool) {
+	var m Manifold
+	c0, r0 := shapeCore(s0)
+	c1, r1 := shapeCore(s1)
+	if r0+r1 > 0 {
+		l, p0, p1 := coreDistance(c0, c1)
+		if l > r0+r1 {
+			return m, false
+		}
+		crossing := l <= crossingTolerance*(r0+r1)
+		if !crossing || isPointOrSegment(c0) && isPointOrSegment(c1) {
+			if !crossing {
+				d := p1.Sub(&p0)
+				m.Normal = d.Mul(1 / l)
+			} else {
+				// the cores cross, moving apart along any axis
+				// perpendicular to both of them separates them the
+				// fastest.
+				m.Normal = crossingCoresAxis(c0, c1)
+			}
+			m.Depth = r0 + r1 - l
+			m.addFeatures(c0, c1, r0, r1)
+			return m, true
+		}
+	}
+	normal, depth, ok := EPA(ShapeSupporter(s0), ShapeSupporter(s1))
+	if !ok {
+		return m, false
+	}
+	m.Normal, m.Depth = normal, depth
+	m.addFeatures(c0, c1, r0, r1)
+	return m, true
+}
+
+// isPointOrSegment returns whether the core c is the point of a sphere or the
+// segment of a capsule.
+func isPointOrSegment(c Shape) bool {
+	switch c.(type) {
+	case *Sphere, *Capsule:
+		return true
+	}
+	return false
+}
+
+// crossingCoresAxis returns a unit axis perpendicular to the points or
+// segments c0 and c1. 2 points have no preferred axis and get +Y.
+func crossingCoresAxis(c0, c1 Shape) glm.Vec3 {
+	var e [2]glm.Vec3
+	for i, c := range [2]Shape{c0, c1} {
+		if c, ok := c.(*Capsule); ok {
+			e[i] = c.B.Sub(&c.A)
+		}
+	}
+	n := e[0].Cross(&e[1])
+	if l := n.Len(); l > featureTolerance*e[0].Len()*e[1].Len() {
+		return n.Mul(1 / l)
+	}
+	// the segments are parallel or one of the cores is a point.
+	if e[0].Len2() < e[1].Len2() {
+		e[0] = e[1]
+	}
+	if l := e[0].Len(); l > 0 {
+		e[0].MulWith(1 / l)
+		u, _ := perpendicularAxes(&e[0])
+		return u
+	}
+	return glm.Vec3{Y: 1}
+}
+
+// addFeatures finds the contact points of the cores c0 and c1, whose radius are
+// r0 and r1, once the normal and depth are known.
+func (m *Manifold) addFeatures(c0, c1 Shape, r0, r1 float32) {
+	in := m.Normal.Inverse()
+	f0 := shapeFeature(c0, &m.Normal, nil)
+	f1 := shapeFeature(c1, &in, nil)
+
+	switch {
+	case len(f0) == 1:
+		p := f0[0]
+		p.AddScaledVec(r0-m.Depth/2, &m.Normal)
+		m.add(&p, m.Depth)
+	case len(f1) == 1:
+		p := f1[0]
+		p.AddScaledVec(r1-m.Depth/2, &in)
+		m.add(&p, m.Depth)
+	case len(f0) == 2 && len(f1) == 2:
+		m.addSegments(f0, f1, r0, r1)
+	case len(f1) == 2 || len(f0) > 2 && isReferenceFace(f0, f1, &m.Normal, &in):
+		m.addClipped(f0, f1, &m.Normal, r1)
+	default:
+		m.addClipped(f1, f0, &in, r0)
+	}
+}
+
+// isReferenceFace returns whether the face f0 is better aligned than the face
+// f1 with the normal to be the reference face. d0 and d1 are the directions the
+// faces face.
+func isReferenceFace(f0, f1 []glm.Vec3, d0, d1 *glm.Vec3) bool {
+	n0, n1 := featureNormal(f0, d0), featureNormal(f1, d1)
+	return n0.Dot(d0)+1e-3 >= n1.Dot(d1)
+}
+
+// addSegments adds the contact points of 2 segments. Parallel segments touch
+// where they overlap, the others on their closest points.
+func (m *Manifold) addSegments(f0, f1 []glm.Vec3, r0, r1 float32) {
+	e0, e1 := f0[1].Sub(&f0[0]), f1[1].Sub(&f1[0])
+	if math.Abs(e0.Dot(&e1)) < (1-featureTolerance)*e0.Len()*e1.Len() {
+		_, _, _, p, _ := ClosestPointSegmentSegment(&f0[0], &f0[1], &f1[0], &f1[1])
+		p.AddScaledVec(r0-m.Depth/2, &m.Normal)
+		m.add(&p, m.Depth)
+		return
+	}
+	// clip f1 to the slab of f0.
+	a, b := f1[0], f1[1]
+	l := e0.Len2()
+	for i, n := range [2]glm.Vec3{e0.Inverse(), e0} {
+		// the planes at both ends of f0, facing outside.
+		p := &f0[i]
+		if !clipSegment(&a, &b, &n, p) {
+			return
+		}
+	}
+	for _, q := range [2]glm.Vec3{a, b} {
+		qa := q.Sub(&f0[0])
+		closest := f0[0]
+		closest.AddScaledVec(qa.Dot(&e0)/l, &e0)
+		d := q.Sub(&closest)
+		sep := d.Dot(&m.Normal)
+		p := q
+		p.AddScaledVec(-r1+(r0+r1-sep)/2, &m.Normal)
+		m.add(&p, r0+r1-sep)
+	}
+}
+
+// addClipped clips the incident feature against the side planes of the
+// reference face and adds the points below it. normal points out of the
+// reference face towards the incident shape, rInc is the radius of the incident
+// core.
+func (m *Manifold) addClipped(ref, inc []glm.Vec3, normal *glm.Vec3, rInc float32) {
+	n := featureNormal(ref, normal)
+	center := featureCenter(ref)
+
+	points := append([]glm.Vec3(nil), inc...)
+	var buf []glm.Vec3
+	for i := range ref {
+		a, b := &ref[i], &ref[(i+1)%len(ref)]
+		e := b.Sub(a)
+		side := e.Cross(&n)
+		if c := center.Sub(a); side.Dot(&c) > 0 {
+			side = side.Inverse()
+		}
+		if len(points) == 2 {
+			if !clipSegment(&points[0], &points[1], &side, a) {
+				points = points[:0]
+			}
+		} else {
+			buf = clipPolygon(points, &side, a, buf[:0])
+			points, buf = buf, points
+		}
+		if len(points) == 0 {
+			break
+		}
+	}
+
+	var (
+		candidates []glm.Vec3
+		depths     []float32
+		deepest    = -1
+	)
+	for i := range points {
+		d := points[i].Sub(&ref[0])
+		depth := rInc - d.Dot(&n)
+		// keep the deepest point in case float error leaves none.
+		if deepest < 0 || depth > depths[deepest] {
+			deepest = len(depths)
+		}
+		p := points[i]
+		p.AddScaledVec(-rInc+depth/2, &n)
+		candidates = append(candidates, p)
+		depths = append(depths, depth)
+	}
+	if deepest < 0 {
+		// the incident face is outside of the reference face, the features
+		// touch on their closest points.
+		p := center
+		p.AddScaledVec(-m.Depth/2, normal)
+		m.add(&p, m.Depth)
+		return
+	}
+	kept := 0
+	for i := range candidates {
+		if depths[i] >= 0 {
+			candidates[kept], depths[kept] = candidates[i], depths[i]
+			kept++
+		}
+	}
+	if kept == 0 {
+		candidates[0], depths[0] = candidates[deepest], depths[deepest]
+		kept = 1
+	}
+	m.reduce(candidates[:kept], depths[:kept])
+}
+
+// add adds a contact point to the manifold.
+func (m *Manifold) add(p *glm.Vec3, depth float32) {
+	m.Points[m.Len] = *p
+	m.Depths[m.Len] = depth
+	m.Len++
+}
+
+// reduce adds at most 4 of the given points to the manifold, the deepest one
+// and the ones spanning the largest area with it.
+func (m *Manifold) reduce(points []glm.Vec3, depths []float32) {
+	if len(points) <= 4 {
+		for i := range points {
+			m.add(&points[i], depths[i])
+		}
+		return
+	}
+	var chosen [4]int
+	// the deepest point.
+	for i := range depths {
+		if depths[i] > depths[chosen[0]] {
+			chosen[0] = i
+		}
+	}
+	// the farthest from it.
+	var best float32 = -1
+	for i := range points {
+		d := points[i].Sub(&points[chosen[0]])
+		if l := d.Len2(); l > best {
+			chosen[1], best = i, l
+		}
+	}
+	// the farthest from the line between them.
+	best = -1
+	for i := range points {
+		if l := triangleArea2(&points[chosen[0]], &points[chosen[1]], &points[i]); l > best {
+			chosen[2], best = i, l
+		}
+	}
+	// the one adding the most area to the triangle.
+	best = -1
+	for i := range points {
+		if i == chosen[0] || i == chosen[1] || i == chosen[2] {
+			continue
+		}
+		l := triangleArea2(&points[chosen[0]], &points[chosen[1]], &points[i]) +
+			triangleArea2(&points[chosen[1]], &points[chosen[2]], &points[i]) +
+			triangleArea2(&points[chosen[2]], &points[chosen[0]], &points[i])
+		if l > best {
+			chosen[3], best = i, l
+		}
+	}
+	for _, i := range chosen {
+		m.add(&points[i], depths[i])
+	}
+}
+
+// triangleArea2 returns twice the area of the triangle abc.
+func triangleArea2(a, b, c *glm.Vec3) float32 {
+	ab, ac := b.Sub(a), c.Sub(a)
+	n := ab.Cross(&ac)
+	return n.Len()
+}
+
+// clipSegment clips the segment ab to the back of the plane going through p
+// with normal n. It returns false if the whole segment is in front.
+func clipSegment(a, b, n, p *glm.Vec3) bool {
+	pa, pb := a.Sub(p), b.Sub(p)
+	da, db := n.Dot(&pa), n.Dot(&pb)
+	switch {
+	case da > 0 && db > 0:
+		return false
+	case da > 0:
+		ab := b.Sub(a)
+		a.AddScaledVec(da/(da-db), &ab)
+	case db > 0:
+		ba := a.Sub(b)
+		b.AddScaledVec(db/(db-da), &ba)
+	}
+	return true
+}
+
+// clipPolygon appends to dst the part of the polygon behind the plane going
+// through p with normal n.
+// [Sutherland74]
+func clipPolygon(polygon []glm.Vec3, n, p *glm.Vec3, dst []glm.Vec3) []glm.Vec3 {
+	for i := range polygon {
+		a, b := &polygon[i], &polygon[(i+1)%len(polygon)]
+		pa, pb := a.Sub(p), b.Sub(p)
+		da, db := n.Dot(&pa), n.Dot(&pb)
+		if da <= 0 {
+			dst = append(dst, *a)
+		}
+		if (da < 0 && db > 0) || (da > 0 && db < 0) {
+			q := *a
+			ab := b.Sub(a)
+			q.AddScaledVec(da/(da-db), &ab)
+			dst = append(dst, q)
+		}
+	}
+	return dst
+}
+
+// featureCenter returns the average of the vertices of a feature.
+func featureCenter(f []glm.Vec3) glm.Vec3 {
+	var c glm.Vec3
+	for i := range f {
+		c.AddWith(&f[i])
+	}
+	return c.Mul(1 / float32(len(f)))
+}
+
+// featureNormal returns the unit normal of a face, on the side of d.
+func featureNormal(f []glm.Vec3, d *glm.Vec3) glm.Vec3 {
+	c := featureCenter(f)
+	var n glm.Vec3
+	for i := range f {
+		a, b := f[i].Sub(&c), f[(i+1)%len(f)].Sub(&c)
+		ab := a.Cross(&b)
+		n.AddWith(&ab)
+	}
+	if n.Dot(d) < 0 {
+		n = n.Inverse()
+	}
+	n.Normalize()
+	return n
+}
+
+// shapeFeature appends to dst the vertices of the feature of the core s that is
+// the most in the direction d: a point, a segment or a convex polygon.
+func shapeFeature(s Shape, d *glm.Vec3, dst []glm.Vec3) []glm.Vec3 {
+	switch s := s.(type) {
+	case *Sphere:
+		return append(dst, s.Center)
+	case *Capsule:
+		e := s.B.Sub(&s.A)
+		dot := e.Dot(d)
+		switch {
+		case math.Abs(dot) <= featureTolerance*e.Len()*d.Len():
+			return append(dst, s.A, s.B)
+		case dot > 0:
+			return append(dst, s.B)
+		}
+		return append(dst, s.A)
+	case *AABB:
+		axis := [3]glm.Vec3{{X: 1}, {Y: 1}, {Z: 1}}
+		return boxFeature(&s.Center, &axis, &s.HalfExtend, d, dst)
+	case *OBB:
+		axis := [3]glm.Vec3{s.Orientation.Row(0), s.Orientation.Row(1), s.Orientation.Row(2)}
+		return boxFeature(&s.Center, &axis, &s.HalfExtend, d, dst)
+	case *Convexhull:
+		return s.faceFeature(d, dst)
+	}
+	panic("geo: shape has no support function")
+}
+
+// boxFeature appends to dst the face of the box that is the most in the
+// direction d, its corners are in order.
+func boxFeature(center *glm.Vec3, axis *[3]glm.Vec3, halfExtend, d *glm.Vec3, dst []glm.Vec3) []glm.Vec3 {
+	var best int
+	var bestDot float32 = -1
+	for i := range axis {
+		if dot := math.Abs(axis[i].Dot(d)); dot > bestDot {
+			best, bestDot = i, dot
+		}
+	}
+	n := axis[best]
+	if n.Dot(d) < 0 {
+		n = n.Inverse()
+	}
+	u, v := axis[(best+1)%3], axis[(best+2)%3]
+	hu, hv := *halfExtend.I((best + 1) % 3), *halfExtend.I((best + 2) % 3)
+
+	c := *center
+	c.AddScaledVec(*halfExtend.I(best), &n)
+	for _, s := range [4][2]float32{{1, 1}, {-1, 1}, {-1, -1}, {1, -1}} {
+		p := c
+		p.AddScaledVec(s[0]*hu, &u)
+		p.AddScaledVec(s[1]*hv, &v)
+		dst = append(dst, p)
+	}
+	return dst
+}
+
+// faceFeature appends to dst the vertices of the face of the hull that is the
+// most aligned with d, in order. The coplanar triangles around the support
+// point are merged in a single face.
+func (c *Convexhull) faceFeature(d *glm.Vec3, dst []glm.Vec3) []glm.Vec3 {
+	support, tri := c.Support(d, nil)
+	var vertex *glm.Vec3
+	for _, v := range tri.Vertices {
+		if *v == support {
+			vertex = v
+		}
+	}
+
+	// the triangle around the support point facing d the most.
+	dn := *d
+	dn.Normalize()
+	inside := c.interior()
+	best, bestNormal := tri, tri.normal(&inside)
+	bestDot := bestNormal.Dot(&dn)
+	var prev *HullTriangle
+	for t, steps := tri, 0; t != nil && steps < len(c.Triangles); steps++ {
+		if n := t.normal(&inside); n.Dot(&dn) > bestDot {
+			best, bestNormal, bestDot = t, n, n.Dot(&dn)
+		}
+		var next *HullTriangle
+		for _, a := range t.Adjacent {
+			if a != nil && a != prev && a.hasVertex(vertex) {
+				next = a
+				break
+			}
+		}
+		if next == tri {
+			break
+		}
+		prev, t = t, next
+	}
+
+	// merge the coplanar triangles.
+	vertices := make(map[*glm.Vec3]bool)
+	visited := map[*HullTriangle]bool{best: true}
+	stack := []*HullTriangle{best}
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, v := range t.Vertices {
+			vertices[v] = true
+		}
+		for _, a := range t.Adjacent {
+			if a == nil || visited[a] {
+				continue
+			}
+			if n := a.normal(&inside); n.Dot(&bestNormal) >= 1-coplanarTolerance {
+				visited[a] = true
+				stack = append(stack, a)
+			}
+		}
+	}
+
+	start := len(dst)
+	for v := range vertices {
+		dst = append(dst, *v)
+	}
+	face := dst[start:]
+	center := featureCenter(face)
+	u := face[0].Sub(&center)
+	w := bestNormal.Cross(&u)
+	angle := func(p *glm.Vec3) float32 {
+		cp := p.Sub(&center)
+		return math.Atan2(cp.Dot(&w), cp.Dot(&u))
+	}
+	sort.Slice(face, func(i, j int) bool { return angle(&face[i]) < angle(&face[j]) })
+	return dst
+}
+
+// normal returns the unit normal of the triangle pointing away from inside, a
+// point inside the hull. It doesn't rely on the winding of the triangle.
+func (t *HullTriangle) normal(inside *glm.Vec3) glm.Vec3 {
+	ab, ac, ai := t.Vertices[1].Sub(t.Vertices[0]), t.Vertices[2].Sub(t.Vertices[0]), inside.Sub(t.Vertices[0])
+	n := ab.Cross(&ac)
+	if n.Dot(&ai) > 0 {
+		n.Invert()
+	}
+	n.Normalize()
+	return n
+}
+
+// interior returns the mean of the vertices of the hull, a point inside it.
+func (c *Convexhull) interior() glm.Vec3 {
+	var mean glm.Vec3
+	for i := range c.Vertices {
+		mean.AddWith(&c.Vertices[i])
+	}
+	mean.MulWith(1 / float32(len(c.Vertices)))
+	return mean
+}
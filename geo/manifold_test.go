@@ -0,0 +1,213 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"testing"
+)
+
+func TestContactShapeShape(t *testing.T) {
+	// the top of the floor is at y = 1, every shape sinks 0.1 in it.
+	floor := &AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 5, Y: 1, Z: 5}}
+	// rotated 45 degrees around z.
+	const h = 0.70710678
+	rotz := glm.Mat3{h, h, 0, -h, h, 0, 0, 0, 1}
+	up := glm.Vec3{X: 0, Y: 1, Z: 0}
+	tests := []struct {
+		s0, s1 Shape
+		normal glm.Vec3
+		depth  float32
+		points []glm.Vec3
+	}{
+		{ // 0
+			floor,
+			&AABB{Center: glm.Vec3{X: 0, Y: 1.4, Z: 0}, HalfExtend: glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+			up, 0.1,
+			[]glm.Vec3{{X: 0.5, Y: 0.95, Z: 0.5}, {X: -0.5, Y: 0.95, Z: 0.5}, {X: -0.5, Y: 0.95, Z: -0.5}, {X: 0.5, Y: 0.95, Z: -0.5}},
+		},
+		{ // 1
+			floor,
+			&Sphere{Center: glm.Vec3{X: 1, Y: 1.4, Z: 0}, Radius: 0.5},
+			up, 0.1,
+			[]glm.Vec3{{X: 1, Y: 0.95, Z: 0}},
+		},
+		{ // 2
+			floor,
+			&Capsule{A: glm.Vec3{X: -1, Y: 1.4, Z: 0}, B: glm.Vec3{X: 1, Y: 1.4, Z: 0}, Radius: 0.5},
+			up, 0.1,
+			[]glm.Vec3{{X: -1, Y: 0.95, Z: 0}, {X: 1, Y: 0.95, Z: 0}},
+		},
+		{ // 3
+			&Capsule{A: glm.Vec3{X: -1, Y: 0, Z: 0}, B: glm.Vec3{X: 1, Y: 0, Z: 0}, Radius: 0.5},
+			&Capsule{A: glm.Vec3{X: 0, Y: 0.9, Z: 0}, B: glm.Vec3{X: 2, Y: 0.9, Z: 0}, Radius: 0.5},
+			up, 0.1,
+			[]glm.Vec3{{X: 0, Y: 0.45, Z: 0}, {X: 1, Y: 0.45, Z: 0}},
+		},
+		{ // 4
+			&Capsule{A: glm.Vec3{X: -1, Y: 0, Z: 0}, B: glm.Vec3{X: 1, Y: 0, Z: 0}, Radius: 0.5},
+			&Capsule{A: glm.Vec3{X: 0, Y: 0.9, Z: -1}, B: glm.Vec3{X: 0, Y: 0.9, Z: 1}, Radius: 0.5},
+			up, 0.1,
+			[]glm.Vec3{{X: 0, Y: 0.45, Z: 0}},
+		},
+		{ // 5
+			floor,
+			&OBB{Center: glm.Vec3{X: 0, Y: 0.9 + 0.5*math.Sqrt(2), Z: 0}, Orientation: rotz, HalfExtend: glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+			up, 0.1,
+			[]glm.Vec3{{X: 0, Y: 0.95, Z: 0.5}, {X: 0, Y: 0.95, Z: -0.5}},
+		},
+		{ // 6
+			floor,
+			cubeHull(glm.Vec3{X: 0, Y: 1.4, Z: 0}, 0.5),
+			up, 0.1,
+			[]glm.Vec3{{X: 0.5, Y: 0.95, Z: 0.5}, {X: -0.5, Y: 0.95, Z: 0.5}, {X: -0.5, Y: 0.95, Z: -0.5}, {X: 0.5, Y: 0.95, Z: -0.5}},
+		},
+		{ // 7
+			cubeHull(glm.Vec3{X: 0, Y: 0, Z: 0}, 1),
+			&Sphere{Center: glm.Vec3{X: 0, Y: 0, Z: 1.4}, Radius: 0.5},
+			glm.Vec3{X: 0, Y: 0, Z: 1}, 0.1,
+			[]glm.Vec3{{X: 0, Y: 0, Z: 0.95}},
+		},
+	}
+	for i, test := range tests {
+		for swap := 0; swap < 2; swap++ {
+			s0, s1, normal := test.s0, test.s1, test.normal
+			if swap == 1 {
+				s0, s1, normal = s1, s0, normal.Inverse()
+			}
+			m, ok := ContactShapeShape(s0, s1)
+			if !ok {
+				t.Errorf("[%d:%d] no contact", i, swap)
+				continue
+			}
+			if !vec3Near(m.Normal, normal, 1e-3) || math.Abs(m.Depth-test.depth) > 1e-3 {
+				t.Errorf("[%d:%d] normal, depth = %v %f, want %v %f", i, swap, m.Normal, m.Depth, normal, test.depth)
+			}
+			if m.Len != len(test.points) {
+				t.Errorf("[%d:%d] %d points %v, want %d %v", i, swap, m.Len, m.Points[:m.Len], len(test.points), test.points)
+				continue
+			}
+		points:
+			for _, want := range test.points {
+				for n := 0; n < m.Len; n++ {
+					if vec3Near(m.Points[n], want, 1e-3) {
+						if math.Abs(m.Depths[n]-test.depth) > 1e-3 {
+							t.Errorf("[%d:%d] depth of %v = %f, want %f", i, swap, want, m.Depths[n], test.depth)
+						}
+						continue points
+					}
+				}
+				t.Errorf("[%d:%d] points = %v, want %v", i, swap, m.Points[:m.Len], test.points)
+				break
+			}
+		}
+	}
+}
+
+func TestContactShapeShape_Separated(t *testing.T) {
+	ident := glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}
+	shapes := func(offset float32) []Shape {
+		c := glm.Vec3{X: offset, Y: 0, Z: 0}
+		return []Shape{
+			&AABB{Center: c, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&Sphere{Center: c, Radius: 1},
+			&OBB{Center: c, Orientation: ident, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			&Capsule{A: glm.Vec3{X: offset, Y: -1, Z: 0}, B: glm.Vec3{X: offset, Y: 1, Z: 0}, Radius: 0.5},
+			cubeHull(c, 1),
+		}
+	}
+	near, far := shapes(0), shapes(10)
+	for _, a := range near {
+		for _, b := range far {
+			if _, ok := ContactShapeShape(a, b); ok {
+				t.Errorf("ContactShapeShape(%T, far %T) found a contact", a, b)
+			}
+		}
+		// every pair of overlapping shapes has a contact with at least 1 point.
+		for _, b := range near {
+			if m, ok := ContactShapeShape(a, b); !ok || m.Len == 0 {
+				t.Errorf("ContactShapeShape(%T, %T) = %v, %t", a, b, m, ok)
+			}
+		}
+	}
+}
+
+func TestContactShapeShape_CrossingCores(t *testing.T) {
+	x, z := glm.Vec3{X: 1}, glm.Vec3{Z: 1}
+	tests := []struct {
+		s0, s1 Shape
+		depth  float32
+		// the normal is perpendicular to these.
+		perpendicular []glm.Vec3
+	}{
+		{ // 0
+			&Sphere{Center: glm.Vec3{X: 1, Y: 2, Z: 3}, Radius: 1},
+			&Sphere{Center: glm.Vec3{X: 1, Y: 2, Z: 3}, Radius: 1},
+			2, nil,
+		},
+		{ // 1
+			&Capsule{A: glm.Vec3{X: -1}, B: glm.Vec3{X: 1}, Radius: 0.5},
+			&Sphere{Center: glm.Vec3{X: 0.3}, Radius: 0.5},
+			1, []glm.Vec3{x},
+		},
+		{ // 2
+			&Capsule{A: glm.Vec3{X: -1}, B: glm.Vec3{X: 1}, Radius: 0.5},
+			&Capsule{A: glm.Vec3{Z: -1}, B: glm.Vec3{Z: 1}, Radius: 0.25},
+			0.75, []glm.Vec3{x, z},
+		},
+		{ // 3 parallel.
+			&Capsule{A: glm.Vec3{X: -1}, B: glm.Vec3{X: 1}, Radius: 0.5},
+			&Capsule{A: glm.Vec3{X: 0}, B: glm.Vec3{X: 2}, Radius: 0.5},
+			1, []glm.Vec3{x},
+		},
+	}
+	for i, test := range tests {
+		for swap := 0; swap < 2; swap++ {
+			s0, s1 := test.s0, test.s1
+			if swap == 1 {
+				s0, s1 = s1, s0
+			}
+			m, ok := ContactShapeShape(s0, s1)
+			if !ok || m.Len == 0 {
+				t.Errorf("[%d:%d] no contact", i, swap)
+				continue
+			}
+			if math.Abs(m.Depth-test.depth) > 1e-5 || math.Abs(m.Normal.Len()-1) > 1e-5 {
+				t.Errorf("[%d:%d] normal, depth = %v %f, want a unit normal and %f", i, swap, m.Normal, m.Depth, test.depth)
+			}
+			for _, a := range test.perpendicular {
+				if math.Abs(m.Normal.Dot(&a)) > 1e-5 {
+					t.Errorf("[%d:%d] normal = %v, want perpendicular to %v", i, swap, m.Normal, a)
+				}
+			}
+			for n := 0; n < m.Len; n++ {
+				if math.Abs(m.Depths[n]-test.depth) > 1e-5 {
+					t.Errorf("[%d:%d] depth of %v = %f, want %f", i, swap, m.Points[n], m.Depths[n], test.depth)
+				}
+			}
+		}
+	}
+}
+
+func TestClipPolygon(t *testing.T) {
+	square := []glm.Vec3{{X: -1, Y: -1}, {X: 1, Y: -1}, {X: 1, Y: 1}, {X: -1, Y: 1}}
+	n, p := glm.Vec3{X: 1}, glm.Vec3{X: 0.5}
+	got := clipPolygon(square, &n, &p, nil)
+	want := []glm.Vec3{{X: -1, Y: -1}, {X: 0.5, Y: -1}, {X: 0.5, Y: 1}, {X: -1, Y: 1}}
+	if len(got) != len(want) {
+		t.Fatalf("clipPolygon = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !vec3Near(got[i], want[i], 1e-6) {
+			t.Errorf("clipPolygon = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func BenchmarkContactShapeShape(b *testing.B) {
+	floor := AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 5, Y: 1, Z: 5}}
+	box := OBB{Center: glm.Vec3{X: 0, Y: 1.4, Z: 0}, Orientation: glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}, HalfExtend: glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}}
+	for n := 0; n < b.N; n++ {
+		ContactShapeShape(&floor, &box)
+	}
+}
@@ -87,9 +87,8 @@ var testTable = [shapeTypeLen][shapeTypeLen]func(Shape, Shape) bool{
 		},
 		// OBB Convexhull
 		func(s0, s1 Shape) bool {
-			panic("obb - convexhull test not implemented")
-			//obb, hull := s0.(*OBB), s1.(*Convexhull)
-			//return TestConvexhullOBB(hull, obb)
+			obb, hull := s0.(*OBB), s1.(*Convexhull)
+			return TestConvexhullOBB(hull, obb)
 		},
 	},
 	{ // Capsule test table
@@ -115,9 +114,8 @@ var testTable = [shapeTypeLen][shapeTypeLen]func(Shape, Shape) bool{
 		},
 		// Capsule Convexhull
 		func(s0, s1 Shape) bool {
-			panic("capsule - convexhull test not implemented")
-			//capsule, hull := s0.(*Capsule), s1.(*Convexhull)
-			//return TestCapsuleConvexhull(capsule, hull)
+			capsule, hull := s0.(*Capsule), s1.(*Convexhull)
+			return TestCapsuleConvexhull(capsule, hull)
 		},
 	},
 	{ // Convexhull test table
@@ -133,15 +131,13 @@ var testTable = [shapeTypeLen][shapeTypeLen]func(Shape, Shape) bool{
 		},
 		// Convexhull OBB
 		func(s0, s1 Shape) bool {
-			panic("convexhull - obb not implemented")
-			//hull, obb := s0.(*Convexhull), s1.(*OBB)
-			//return TestConvexhullOBB(hull, obb)
+			hull, obb := s0.(*Convexhull), s1.(*OBB)
+			return TestConvexhullOBB(hull, obb)
 		},
 		// Convexhull Capsule
 		func(s0, s1 Shape) bool {
-			panic("convexhull - capsule not implemented")
-			//hull, capsule := s0.(*Convexhull), s1.(*Capsule)
-			//return TestCapsuleConvexhull(capsule, hull)
+			hull, capsule := s0.(*Convexhull), s1.(*Capsule)
+			return TestCapsuleConvexhull(capsule, hull)
 		},
 		// Convexhull Convexhull
 		func(s0, s1 Shape) bool {
@@ -152,7 +148,8 @@ var testTable = [shapeTypeLen][shapeTypeLen]func(Shape, Shape) bool{
 }
 
 // Shape just typedefs interface{} to differentiate between seemingly any data
-// from collision shapes. Valid Shapes are {*Sphere, *AABB, *OBB, *Capsule}.
+// from collision shapes. Valid Shapes are {*Sphere, *AABB, *OBB, *Capsule,
+// *Convexhull}.
 type Shape interface {
 	ShapeType() int
 }
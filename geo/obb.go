@@ -37,6 +37,21 @@ func (obb *OBB) InertiaTensor(density float32) glm.Mat3 {
 	return tensors.Cuboid(obb.Mass(density), obb.HalfExtend.X*2, obb.HalfExtend.Y*2, obb.HalfExtend.Z*2)
 }
 
+// Support returns the corner of the obb that is the most in the given
+// direction.
+func (obb *OBB) Support(direction *glm.Vec3) glm.Vec3 {
+	p := obb.Center
+	for i := 0; i < 3; i++ {
+		r := obb.Orientation.Row(i)
+		if direction.Dot(&r) < 0 {
+			p.AddScaledVec(-*obb.HalfExtend.I(i), &r)
+		} else {
+			p.AddScaledVec(*obb.HalfExtend.I(i), &r)
+		}
+	}
+	return p
+}
+
 // ClosestPointOBBPoint returns the point in or on the OBB closest to p
 func ClosestPointOBBPoint(o *OBB, p *glm.Vec3) glm.Vec3 {
 	closestPoint := o.Center
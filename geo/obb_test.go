@@ -119,3 +119,24 @@ func TestTestOBBOBB(t *testing.T) {
 		}
 	}
 }
+
+func TestOBB_Support(t *testing.T) {
+	// rotated 90 degrees around z.
+	obb := OBB{
+		Center:      glm.Vec3{X: 1, Y: 2, Z: 3},
+		Orientation: glm.Mat3{0, 1, 0, -1, 0, 0, 0, 0, 1},
+		HalfExtend:  glm.Vec3{X: 1, Y: 2, Z: 3},
+	}
+	tests := []struct {
+		direction, support glm.Vec3
+	}{
+		{glm.Vec3{X: 1, Y: 1, Z: 1}, glm.Vec3{X: 3, Y: 3, Z: 6}},
+		{glm.Vec3{X: -1, Y: -1, Z: -1}, glm.Vec3{X: -1, Y: 1, Z: 0}},
+		{glm.Vec3{X: 1, Y: -1, Z: -1}, glm.Vec3{X: 3, Y: 1, Z: 0}},
+	}
+	for i, test := range tests {
+		if support := obb.Support(&test.direction); !glmtesting.Vec3Equal(support, test.support) {
+			t.Errorf("[%d] support = %v, want %v", i, support, test.support)
+		}
+	}
+}
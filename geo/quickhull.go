@@ -52,19 +52,26 @@ func convexhullFromFaces(points []glm.Vec3, faces []*qhull.Face) *Convexhull {
 		hull.Vertices[index] = vertex
 	}
 
-	// map all face->index in face slice
-	faceMap := make(map[*qhull.Face]int)
-	for i, face := range faces {
-		faceMap[face] = i
-	}
-
-	// link all the triangles
+	// link all the triangles, the triangles adjacent to a triangle are the ones
+	// sharing one of its edges. Adjacent[n] is across the edge from Vertices[n]
+	// to Vertices[n+1]. The faces are CCW seen from outside so the neighbour
+	// has the same edge in the other direction.
 	for i, face := range faces {
 		for n := 0; n < len(face.Vertices); n++ {
 			hull.Triangles[i].Vertices[n] = &hull.Vertices[vertexMap[points[face.Vertices[n]]]]
 		}
-		for n := 0; n < len(hull.Triangles[i].Adjacent); n++ {
-			hull.Triangles[i].Adjacent[n] = &hull.Triangles[faceMap[face.Faces[n]]]
+	}
+	edges := make(map[[2]*glm.Vec3]*HullTriangle, 3*len(faces))
+	for i := range hull.Triangles {
+		t := &hull.Triangles[i]
+		for n := 0; n < 3; n++ {
+			edges[[2]*glm.Vec3{t.Vertices[n], t.Vertices[(n+1)%3]}] = t
+		}
+	}
+	for i := range hull.Triangles {
+		t := &hull.Triangles[i]
+		for n := 0; n < 3; n++ {
+			t.Adjacent[n] = edges[[2]*glm.Vec3{t.Vertices[(n+1)%3], t.Vertices[n]}]
 		}
 	}
 
@@ -136,137 +143,83 @@ func Quickhull(points []glm.Vec3) *Convexhull {
 
 	faces := qhull.BuildInitialTetrahedron(tetraIndex, extremumIndices[triangleIndices[0]], extremumIndices[triangleIndices[1]], extremumIndices[triangleIndices[2]], points, &center)
 
-	// initial partitioning
-	var maxDist float32 = -math.MaxFloat32
-	var maxDistFace, maxDistConflict int = -1, -1
-	for n := 0; n < len(uneaten); n++ {
-		var assigned bool
-		for fn, face := range faces {
-			if dist := qhull.DistToPlane(&face.Plane, &points[uneaten[n]]); dist > epsilon {
-				face.Conflicts = append(face.Conflicts, qhull.Conflict{Distance: dist, Index: uneaten[n]})
-				assigned = true
-				if dist > maxDist {
-					maxDist = dist
-					maxDistFace = fn
-					maxDistConflict = len(face.Conflicts) - 1
+	// initial partitioning, every point outside the hull is a conflict of a
+	// single face it can see.
+	assign(faces, uneaten, points, epsilon)
+
+	for {
+		// find the new king conflict.
+		var maxDist float32 = -math.MaxFloat32
+		eye := -1
+		for _, face := range faces {
+			for _, conflict := range face.Conflicts {
+				if conflict.Distance > maxDist {
+					maxDist = conflict.Distance
+					eye = conflict.Index
 				}
 			}
 		}
-		if !assigned {
-			uneaten = uneaten[:n+copy(uneaten[n:], uneaten[n+1:])]
-			n--
+		if eye == -1 {
+			return convexhullFromFaces(points, faces)
 		}
-	}
-
-eat:
-	if maxDistFace == -1 {
-		return convexhullFromFaces(points, faces)
-	}
-
-	edges := qhull.FindHorizon(faces[maxDistFace], faces[maxDistFace].Edges[0], &points[faces[maxDistFace].Conflicts[maxDistConflict].Index])
 
-	newfaces := make([]*qhull.Face, len(edges))
-	{ // create the new triangles and link edges/faces togheter.
-		for n := range edges {
-			e0, e1, e2 := new(qhull.Edge), new(qhull.Edge), new(qhull.Edge)
-			e0.Tail = edges[n].Tail
-			e0.Next = e1
-			e0.Prev = e2
-			e0.Twin = edges[n]
-
-			e1.Tail = edges[n].Next.Tail
-			e1.Next = e2
-			e1.Prev = e0
-
-			e2.Tail = faces[maxDistFace].Conflicts[maxDistConflict].Index
-			e2.Next = e0
-			e2.Prev = e1
-
-			f := &qhull.Face{
-				Vertices: [3]int{edges[n].Tail, edges[n].Next.Tail, faces[maxDistFace].Conflicts[maxDistConflict].Index},
-				Faces:    [3]*qhull.Face{edges[n].Twin.Face, nil, nil},
-				Edges:    [3]*qhull.Edge{e0, e1, e2},
+		// the faces the eye can see are replaced by a cone from the horizon, the
+		// edges of the visible faces that aren't shared with another visible
+		// face, to the eye. The faces are CCW seen from outside so the edges of
+		// the horizon keep their direction in the new faces.
+		visibleEdges := make(map[[2]int]bool)
+		var visible []*qhull.Face
+		var orphans []int
+		kept := faces[:0]
+		for _, face := range faces {
+			if qhull.DistToPlane(&face.Plane, &points[eye]) <= epsilon {
+				kept = append(kept, face)
+				continue
 			}
-			f.Plane = qhull.ComputePlane(&points[f.Vertices[0]], &points[f.Vertices[1]], &points[f.Vertices[2]])
-			if qhull.DistToPlane(&f.Plane, &center) > 0 {
-				f.Plane.Normal.Invert()
-				f.Plane.Offset = -f.Plane.Offset
-				f.Vertices[0], f.Vertices[1] = f.Vertices[1], f.Vertices[0]
+			visible = append(visible, face)
+			for n := 0; n < 3; n++ {
+				visibleEdges[[2]int{face.Vertices[n], face.Vertices[(n+1)%3]}] = true
 			}
-			e0.Face = f
-			e1.Face = f
-			e2.Face = f
-			twin := edges[n].Twin
-			edges[n].Twin = nil
-			twin.Twin = f.Edges[0]
-			f.Edges[0].Twin = twin
-			edges[n] = twin
-
-			faces = append(faces, f)
-			newfaces[n] = f
-		}
-		for n := range edges {
-			edges[n].Twin.Face.Edges[1].Twin = edges[(n+1)%len(edges)].Twin.Face.Edges[2]
-			edges[n].Twin.Face.Edges[2].Twin = edges[(n-1+len(edges))%len(edges)].Twin.Face.Edges[1]
-			edges[n].Twin.Face.Faces[1] = edges[(n+1)%len(edges)].Twin.Face
-			edges[n].Twin.Face.Faces[2] = edges[(n-1+len(edges))%len(edges)].Twin.Face
-		}
-	}
-	{ // reassign conflicts
-		for _, face := range faces {
-			if face.Visited {
-				for _, conflict := range face.Conflicts {
-					var assigned bool
-					for _, newface := range newfaces {
-						if qhull.DistToPlane(&newface.Plane, &points[conflict.Index]) > epsilon {
-							newface.Conflicts = append(newface.Conflicts, conflict)
-							assigned = true
-						}
-					}
-					if !assigned { // if the conflict was resolved somehow during this iteration we can remove it.
-						for i, uneat := range uneaten {
-							if uneat == conflict.Index {
-								uneaten = uneaten[:i+copy(uneaten[i:], uneaten[i+1:])]
-							}
-						}
-					}
+			for _, conflict := range face.Conflicts {
+				if conflict.Index != eye {
+					orphans = append(orphans, conflict.Index)
 				}
 			}
 		}
-	}
-	{ // clean old faces.
-		for n := 0; n < len(faces); n++ {
-			if faces[n].Visited {
-				faces = faces[:n+copy(faces[n:], faces[n+1:])]
-				n--
-			}
-		}
-	}
-	{ // find the new king conflict.
-		maxDist = -math.MaxFloat32
-		maxDistFace, maxDistConflict = -1, -1
-		for f, face := range faces {
-			for c, conflict := range face.Conflicts {
-				if conflict.Distance > maxDist {
-					maxDist = conflict.Distance
-					maxDistFace = f
-					maxDistConflict = c
+		faces = kept
+
+		var newfaces []*qhull.Face
+		for _, face := range visible {
+			for n := 0; n < 3; n++ {
+				a, b := face.Vertices[n], face.Vertices[(n+1)%3]
+				if visibleEdges[[2]int{b, a}] {
+					continue
 				}
+				f := &qhull.Face{Vertices: [3]int{a, b, eye}}
+				f.Plane = qhull.ComputePlane(&points[a], &points[b], &points[eye])
+				newfaces = append(newfaces, f)
 			}
 		}
+		// the points that were outside the removed faces are either inside the
+		// new hull or outside one of the new faces.
+		assign(newfaces, orphans, points, epsilon)
+		faces = append(faces, newfaces...)
 	}
+}
 
-	//uneaten = make([]int, len(points))
-	//for i := range uneaten {
-	//	uneaten[i] = i
-	//}
-
-	// sanity check
-	for _, face := range faces {
-		if qhull.DistToPlane(&face.Plane, &center) > 0 {
-			panic("bad construction")
+// assign adds each point to the conflicts of the face it is the furthest
+// outside of, the points inside all the faces are dropped.
+func assign(faces []*qhull.Face, indices []int, points []glm.Vec3, epsilon float32) {
+	for _, i := range indices {
+		var best *qhull.Face
+		bestDist := epsilon
+		for _, face := range faces {
+			if dist := qhull.DistToPlane(&face.Plane, &points[i]); dist > bestDist {
+				best, bestDist = face, dist
+			}
+		}
+		if best != nil {
+			best.Conflicts = append(best.Conflicts, qhull.Conflict{Distance: bestDist, Index: i})
 		}
 	}
-
-	goto eat
 }
@@ -1,12 +1,14 @@
 package geo
 
 import (
+	"math/rand"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/luxengine/lux/geo/internal/qhull"
 	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
 )
 
 var _ = qhull.ComputePlane
@@ -585,3 +587,66 @@ func testSuzanneConvexhull(t *testing.T) {
 	_ = os.O_TRUNC
 	writeWavefront(os.Stdout, hull)
 }
+
+func TestQuickhull_Adjacent(t *testing.T) {
+	for _, hull := range []*Convexhull{Quickhull(suzannePointCloud), cubeHull(glm.Vec3{}, 1)} {
+		for i := range hull.Triangles {
+			tri := &hull.Triangles[i]
+			for n := 0; n < 3; n++ {
+				adj := tri.Adjacent[n]
+				if adj == nil || adj == tri {
+					t.Fatalf("triangle %d has no neighbour across edge %d", i, n)
+				}
+				if !adj.hasVertex(tri.Vertices[n]) || !adj.hasVertex(tri.Vertices[(n+1)%3]) {
+					t.Errorf("triangle %d doesn't share edge %d with its neighbour", i, n)
+				}
+			}
+		}
+	}
+}
+
+func TestQuickhull_RandomClouds(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	proj := glm.Perspective(math.Pi/2, 1, 1, 100)
+	frustum := FrustumFromMatrix(&proj)
+	others := []Shape{
+		&Sphere{Center: glm.Vec3{X: 0.5}, Radius: 0.5},
+		&Capsule{A: glm.Vec3{X: -1}, B: glm.Vec3{X: 1, Y: 1}, Radius: 0.3},
+		&AABB{Center: glm.Vec3{Y: 0.8}, HalfExtend: glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+		&OBB{Center: glm.Vec3{Z: 1.2}, Orientation: glm.Ident3(), HalfExtend: glm.Vec3{X: 0.3, Y: 0.6, Z: 0.3}},
+	}
+	for i := 0; i < 1000; i++ {
+		points := make([]glm.Vec3, 12)
+		for n := range points {
+			points[n] = glm.Vec3{X: r.Float32()*2 - 1, Y: r.Float32()*2 - 1, Z: r.Float32()*2 - 1}
+		}
+		hull := Quickhull(points)
+
+		var mean glm.Vec3
+		for n := range hull.Vertices {
+			mean.AddWith(&hull.Vertices[n])
+		}
+		mean.MulWith(1 / float32(len(hull.Vertices)))
+		for tn := range hull.Triangles {
+			tri := &hull.Triangles[tn]
+			ab, ac, out := tri.Vertices[1].Sub(tri.Vertices[0]), tri.Vertices[2].Sub(tri.Vertices[0]), tri.Vertices[0].Sub(&mean)
+			if n := ab.Cross(&ac); n.Dot(&out) <= 0 {
+				t.Errorf("[%d] triangle %d faces the inside of the hull", i, tn)
+			}
+			for n, a := range tri.Adjacent {
+				if a == nil || !a.hasVertex(tri.Vertices[n]) || !a.hasVertex(tri.Vertices[(n+1)%3]) {
+					t.Errorf("[%d] triangle %d has no neighbour across edge %d", i, tn, n)
+				}
+			}
+		}
+
+		// none of these may panic.
+		hull.CalculateInternals()
+		for _, other := range others {
+			TestShapeShape(hull, other)
+			DistanceShapeShape(hull, other)
+			ContactShapeShape(hull, other)
+		}
+		CullFrustumConvexhull(&frustum, hull, 0x3f)
+	}
+}
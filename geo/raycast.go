@@ -0,0 +1,312 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+)
+
+// RaycastHit is where a ray hits a shape.
+type RaycastHit struct {
+	// how far along the ray the hit is, the point hit is p + T*d.
+	T float32
+
+	// the point of the surface that was hit.
+	Point glm.Vec3
+
+	// the outward unit normal of the surface at Point.
+	Normal glm.Vec3
+}
+
+// Raycast intersects the ray R(t) = p + t*d, t in [0, maxT], against the shape
+// and returns the first hit. d doesn't need to be normalized, T is in multiples
+// of d. Rays starting inside the shape hit it at T = 0 and their normal is
+// opposite to d.
+//
+// Only the Shape types are supported: *AABB, *Sphere, *OBB, *Capsule and
+// *Convexhull. Planes, rects and triangles aren't shapes and have their own
+// IntersectRayPlane, IntersectRayRect and IntersectRayTriangle.
+func Raycast(s Shape, p, d *glm.Vec3, maxT float32) (RaycastHit, bool) {
+	var (
+		hit RaycastHit
+		ok  bool
+	)
+	switch s := s.(type) {
+	case *AABB:
+		hit.T, hit.Point, ok = IntersectRayAABB(p, d, s)
+		if ok {
+			local := hit.Point.Sub(&s.Center)
+			hit.Normal = boxNormal(&local, &s.HalfExtend)
+		}
+	case *Sphere:
+		// IntersectRaySphere wants a unit direction.
+		l := d.Len()
+		u := d.Mul(1 / l)
+		hit.T, hit.Point, ok = IntersectRaySphere(p, &u, s)
+		hit.T /= l
+		hit.Normal = hit.Point.Sub(&s.Center)
+		hit.Normal.Normalize()
+	case *OBB:
+		hit.T, hit.Point, ok = IntersectRayOBB(p, d, s)
+		if ok {
+			v := hit.Point.Sub(&s.Center)
+			local := s.Orientation.Mul3x1(&v)
+			n := boxNormal(&local, &s.HalfExtend)
+			hit.Normal = s.Orientation.Mul3x1Transpose(&n)
+		}
+	case *Capsule:
+		hit.T, hit.Point, ok = IntersectRayCapsule(p, d, s)
+		_, c := ClosestPointSegmentPoint(&s.A, &s.B, &hit.Point)
+		hit.Normal = hit.Point.Sub(&c)
+		hit.Normal.Normalize()
+	case *Convexhull:
+		hit.T, hit.Point, ok = IntersectRayConvexhull(p, d, s)
+		if ok {
+			hit.Normal = s.surfaceNormal(&hit.Point)
+		}
+	default:
+		panic("geo: unknown shape type")
+	}
+	if !ok || !(hit.T <= maxT) {
+		return RaycastHit{}, false
+	}
+	if hit.T == 0 {
+		hit.Normal = d.Inverse()
+		hit.Normal.Normalize()
+	}
+	return hit, true
+}
+
+// RaycastShapes intersects the ray R(t) = p + t*d, t in [0, maxT], against
+// every shape and returns the index of the shape hit first and the hit. It
+// returns false if no shape is hit. The shapes are the ones Raycast supports.
+func RaycastShapes(shapes []Shape, p, d *glm.Vec3, maxT float32) (index int, hit RaycastHit, ok bool) {
+	index = -1
+	for i, s := range shapes {
+		// every hit shortens the ray, only closer shapes can be hit after.
+		if h, hit0 := Raycast(s, p, d, maxT); hit0 {
+			index, hit, maxT = i, h, h.T
+		}
+	}
+	return index, hit, index >= 0
+}
+
+// boxNormal returns the normal of the face of the box centered on the origin
+// that p is the closest to.
+func boxNormal(p, halfExtend *glm.Vec3) glm.Vec3 {
+	var n glm.Vec3
+	axis, best := 0, float32(-math.MaxFloat32)
+	for i := 0; i < 3; i++ {
+		if d := math.Abs(*p.I(i)) - *halfExtend.I(i); d > best {
+			axis, best = i, d
+		}
+	}
+	if *p.I(axis) < 0 {
+		*n.I(axis) = -1
+	} else {
+		*n.I(axis) = 1
+	}
+	return n
+}
+
+// surfaceNormal returns the normal of the face of the hull p is the closest to.
+func (c *Convexhull) surfaceNormal(p *glm.Vec3) glm.Vec3 {
+	var best glm.Vec3
+	bestDist := float32(-math.MaxFloat32)
+	inside := c.interior()
+	for i := range c.Triangles {
+		n := c.Triangles[i].normal(&inside)
+		v := p.Sub(c.Triangles[i].Vertices[0])
+		if d := n.Dot(&v); d > bestDist {
+			best, bestDist = n, d
+		}
+	}
+	return best
+}
+
+// IntersectRayOBB intersect ray R(t) = p + t*d against OBB o. When
+// intersecting, return intersection distance t and point q of intersection.
+func IntersectRayOBB(p, d *glm.Vec3, o *OBB) (t float32, q glm.Vec3, intersect bool) {
+	// express the ray in the frame of the box and test it against the AABB it
+	// becomes.
+	v := p.Sub(&o.Center)
+	lp, ld := o.Orientation.Mul3x1(&v), o.Orientation.Mul3x1(d)
+	t, _, intersect = IntersectRayAABB(&lp, &ld, &AABB{HalfExtend: o.HalfExtend})
+	if !intersect {
+		return
+	}
+	q = *p
+	q.AddScaledVec(t, d)
+	return
+}
+
+// IntersectRayCapsule intersect ray R(t) = p + t*d against capsule c. When
+// intersecting, return intersection distance t and point q of intersection.
+// If the ray starts inside the capsule t is 0.
+func IntersectRayCapsule(p, d *glm.Vec3, c *Capsule) (t float32, q glm.Vec3, intersect bool) {
+	r2 := c.Radius * c.Radius
+	if SqDistPointSegment(&c.A, &c.B, p) <= r2 {
+		return 0, *p, true
+	}
+
+	ab, ap := c.B.Sub(&c.A), p.Sub(&c.A)
+	abab, abd, abap := ab.Dot(&ab), ab.Dot(d), ab.Dot(&ap)
+	dd, dap := d.Dot(d), d.Dot(&ap)
+
+	// Intersect the infinite cylinder around ab. If the ray misses it, it
+	// misses the capsule too, if it enters the cylinder between the end caps
+	// that's the hit.
+	a := abab*dd - abd*abd
+	var enter float32 // where the ray enters the cylinder, projected on ab.
+	if a > 0 {
+		b := abab*dap - abap*abd
+		k := abab*ap.Dot(&ap) - abap*abap - r2*abab
+		discr := b*b - a*k
+		if !(discr >= 0) {
+			return
+		}
+		t = (-b - math.Sqrt(discr)) / a
+		enter = abap + t*abd
+		if enter > 0 && enter < abab {
+			if t < 0 {
+				return 0, glm.Vec3{}, false
+			}
+			q = *p
+			q.AddScaledVec(t, d)
+			return t, q, true
+		}
+	} else if abd > 0 {
+		// parallel to the axis, the ray can only hit the cap it's going to.
+		enter = -1
+	} else {
+		enter = abab + 1
+	}
+
+	// The ray enters the cylinder outside of the segment, it hits the sphere of
+	// the closest end cap or nothing.
+	end := &c.B
+	if enter <= 0 {
+		end = &c.A
+	}
+	return intersectRaySphereScaled(p, d, end, c.Radius)
+}
+
+// intersectRaySphereScaled is IntersectRaySphere for directions that aren't
+// normalized, the origin of the ray must be outside the sphere.
+func intersectRaySphereScaled(p, d, center *glm.Vec3, radius float32) (t float32, q glm.Vec3, intersect bool) {
+	m := p.Sub(center)
+	a, b, c := d.Dot(d), m.Dot(d), m.Dot(&m)-radius*radius
+	discr := b*b - a*c
+	if !(discr >= 0) || b >= 0 {
+		return
+	}
+	t = (-b - math.Sqrt(discr)) / a
+	q = *p
+	q.AddScaledVec(t, d)
+	return t, q, true
+}
+
+// IntersectRayConvexhull intersect ray R(t) = p + t*d against convex hull c.
+// When intersecting, return intersection distance t and point q of
+// intersection. If the ray starts inside the hull t is 0.
+func IntersectRayConvexhull(p, d *glm.Vec3, c *Convexhull) (t float32, q glm.Vec3, intersect bool) {
+	// Clip the ray against the plane of every face, what's left is inside the
+	// hull. The planes face away from a point inside the hull.
+	tfirst, tlast := float32(0), float32(math.MaxFloat32)
+	inside := c.interior()
+	for i := range c.Triangles {
+		tri := &c.Triangles[i]
+		n := tri.normal(&inside)
+		pa := tri.Vertices[0].Sub(p)
+		denom, dist := n.Dot(d), n.Dot(&pa)
+		if denom == 0 {
+			// The ray is parallel to the face, it misses if it's outside of it.
+			if dist < 0 {
+				return
+			}
+			continue
+		}
+		// written so that NaNs end up in tfirst or tlast and fail the test below.
+		ft := dist / denom
+		if denom < 0 {
+			// the ray enters the half space of the face.
+			if !(ft <= tfirst) {
+				tfirst = ft
+			}
+		} else if !(ft >= tlast) {
+			// the ray leaves it.
+			tlast = ft
+		}
+		if !(tfirst <= tlast) {
+			return
+		}
+	}
+	q = *p
+	q.AddScaledVec(tfirst, d)
+	return tfirst, q, true
+}
+
+// IntersectRayPlane intersect ray R(t) = p + t*d against plane pl, from either
+// side. When intersecting, return intersection distance t and point q of
+// intersection.
+func IntersectRayPlane(p, d *glm.Vec3, pl *Plane) (t float32, q glm.Vec3, intersect bool) {
+	nd := pl.Normal.Dot(d)
+	// A ray parallel to the plane never hits it.
+	if nd == 0 {
+		return
+	}
+	t = (pl.Offset - pl.Normal.Dot(p)) / nd
+	if !(t >= 0) {
+		return 0, glm.Vec3{}, false
+	}
+	q = *p
+	q.AddScaledVec(t, d)
+	return t, q, true
+}
+
+// IntersectRayTriangle intersect ray R(t) = p + t*d against triangle abc, from
+// either side. When intersecting, return intersection distance t and the
+// barycentric coordinates (u,v,w) of the intersection point.
+// [Moller97]
+func IntersectRayTriangle(p, d, a, b, c *glm.Vec3) (u, v, w, t float32, intersect bool) {
+	ab, ac := b.Sub(a), c.Sub(a)
+	pv := d.Cross(&ac)
+	det := ab.Dot(&pv)
+	// A ray parallel to the triangle never hits it.
+	if det == 0 {
+		return
+	}
+	ood := 1 / det
+	ap := p.Sub(a)
+	v = ap.Dot(&pv) * ood
+	if !(v >= 0 && v <= 1) {
+		return
+	}
+	qv := ap.Cross(&ab)
+	w = d.Dot(&qv) * ood
+	if !(w >= 0 && v+w <= 1) {
+		return
+	}
+	t = ac.Dot(&qv) * ood
+	if !(t >= 0) {
+		return
+	}
+	u = 1 - v - w
+	intersect = true
+	return
+}
+
+// IntersectRayRect intersect ray R(t) = p + t*d against rectangle r, from
+// either side. When intersecting, return intersection distance t and point q
+// of intersection.
+func IntersectRayRect(p, d *glm.Vec3, r *Rect) (t float32, q glm.Vec3, intersect bool) {
+	n := r.Orientation[0].Cross(&r.Orientation[1])
+	t, q, intersect = IntersectRayPlane(p, d, &Plane{Normal: n, Offset: n.Dot(&r.Center)})
+	if !intersect {
+		return
+	}
+	v := q.Sub(&r.Center)
+	if !(math.Abs(v.Dot(&r.Orientation[0])) <= r.HalfExtend.X && math.Abs(v.Dot(&r.Orientation[1])) <= r.HalfExtend.Y) {
+		return 0, glm.Vec3{}, false
+	}
+	return
+}
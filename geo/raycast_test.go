@@ -0,0 +1,492 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/glm/glmtesting"
+	"github.com/luxengine/lux/math"
+	"math/rand"
+	"testing"
+)
+
+func TestIntersectRayOBB(t *testing.T) {
+	ident := glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}
+	// rotated 45 degrees around z.
+	const h = 0.70710678
+	rotz := glm.Mat3{h, h, 0, -h, h, 0, 0, 0, 1}
+	tests := []struct {
+		p, d      glm.Vec3
+		obb       OBB
+		t         float32
+		q         glm.Vec3
+		intersect bool
+	}{
+		{glmtesting.NaN3, glm.Vec3{X: 1},
+			OBB{glm.Vec3{}, ident, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			0, glm.Vec3{}, false}, // 0
+		{glm.Vec3{}, glmtesting.NaN3,
+			OBB{glm.Vec3{}, ident, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			0, glm.Vec3{}, false}, // 1
+		{glm.Vec3{}, glm.Vec3{X: 1},
+			OBB{glmtesting.NaN3, ident, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			0, glm.Vec3{}, false}, // 2
+
+		{glm.Vec3{X: -5, Y: 0, Z: 0}, glm.Vec3{X: 2, Y: 0, Z: 0},
+			OBB{glm.Vec3{X: 2, Y: 0, Z: 0}, ident, glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+			3.25, glm.Vec3{X: 1.5, Y: 0, Z: 0}, true}, // 3
+		{glm.Vec3{X: -5, Y: 0, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			OBB{glm.Vec3{}, rotz, glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+			5 - h, glm.Vec3{X: -h, Y: 0, Z: 0}, true}, // 4
+		{glm.Vec3{X: -5, Y: 0.6, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			OBB{glm.Vec3{}, rotz, glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+			5 - h + 0.6, glm.Vec3{X: 0.6 - h, Y: 0.6, Z: 0}, true}, // 5
+		{glm.Vec3{X: -5, Y: 0.8, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			OBB{glm.Vec3{}, rotz, glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+			0, glm.Vec3{}, false}, // 6
+		{glm.Vec3{X: -5, Y: 0, Z: 0}, glm.Vec3{X: -1, Y: 0, Z: 0},
+			OBB{glm.Vec3{}, rotz, glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+			0, glm.Vec3{}, false}, // 7
+		{glm.Vec3{X: 0.1, Y: 0, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			OBB{glm.Vec3{}, rotz, glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+			0, glm.Vec3{X: 0.1, Y: 0, Z: 0}, true}, // 8
+	}
+	for i, test := range tests {
+		v, q, intersect := IntersectRayOBB(&test.p, &test.d, &test.obb)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue // if they don't overlap then t and q are junk data.
+		}
+
+		if math.Abs(v-test.t) > 1e-5 {
+			t.Errorf("[%d] t = %f, want %f", i, v, test.t)
+		}
+		if !vec3Near(q, test.q, 1e-5) {
+			t.Errorf("[%d] q = %s, want %s", i, q.String(), test.q.String())
+		}
+	}
+}
+
+func TestIntersectRayCapsule(t *testing.T) {
+	capsule := Capsule{A: glm.Vec3{X: 0, Y: -1, Z: 0}, B: glm.Vec3{X: 0, Y: 1, Z: 0}, Radius: 0.5}
+	tests := []struct {
+		p, d      glm.Vec3
+		capsule   Capsule
+		t         float32
+		q         glm.Vec3
+		intersect bool
+	}{
+		{glmtesting.NaN3, glm.Vec3{X: 1},
+			capsule,
+			0, glm.Vec3{}, false}, // 0
+		{glm.Vec3{X: -5}, glmtesting.NaN3,
+			capsule,
+			0, glm.Vec3{}, false}, // 1
+		{glm.Vec3{X: -5}, glm.Vec3{X: 1},
+			Capsule{A: glm.Vec3{}, B: glm.Vec3{}, Radius: math.NaN()},
+			0, glm.Vec3{}, false}, // 2
+
+		{glm.Vec3{X: -5, Y: 0, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			capsule,
+			4.5, glm.Vec3{X: -0.5, Y: 0, Z: 0}, true}, // 3
+		{glm.Vec3{X: -5, Y: 1.2, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			capsule,
+			5 - math.Sqrt(0.21), glm.Vec3{X: -math.Sqrt(0.21), Y: 1.2, Z: 0}, true}, // 4
+		{glm.Vec3{X: 0, Y: 5, Z: 0}, glm.Vec3{X: 0, Y: -1, Z: 0},
+			capsule,
+			3.5, glm.Vec3{X: 0, Y: 1.5, Z: 0}, true}, // 5
+		{glm.Vec3{X: 0, Y: -5, Z: 0}, glm.Vec3{X: 0, Y: 2, Z: 0},
+			capsule,
+			1.75, glm.Vec3{X: 0, Y: -1.5, Z: 0}, true}, // 6
+		{glm.Vec3{X: -5, Y: 0, Z: 1}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			capsule,
+			0, glm.Vec3{}, false}, // 7
+		{glm.Vec3{X: -5, Y: 0, Z: 0}, glm.Vec3{X: -1, Y: 0, Z: 0},
+			capsule,
+			0, glm.Vec3{}, false}, // 8
+		{glm.Vec3{X: 1, Y: 5, Z: 0}, glm.Vec3{X: 0, Y: -1, Z: 0},
+			capsule,
+			0, glm.Vec3{}, false}, // 9
+		{glm.Vec3{X: 0, Y: 0.5, Z: 0.2}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			capsule,
+			0, glm.Vec3{X: 0, Y: 0.5, Z: 0.2}, true}, // 10
+		{glm.Vec3{X: 0, Y: 5, Z: 0}, glm.Vec3{X: 0, Y: 1, Z: 0},
+			capsule,
+			0, glm.Vec3{}, false}, // 11
+	}
+	for i, test := range tests {
+		v, q, intersect := IntersectRayCapsule(&test.p, &test.d, &test.capsule)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue // if they don't overlap then t and q are junk data.
+		}
+
+		if math.Abs(v-test.t) > 1e-5 {
+			t.Errorf("[%d] t = %f, want %f", i, v, test.t)
+		}
+		if !vec3Near(q, test.q, 1e-5) {
+			t.Errorf("[%d] q = %s, want %s", i, q.String(), test.q.String())
+		}
+	}
+}
+
+func TestIntersectRayConvexhull(t *testing.T) {
+	hull := cubeHull(glm.Vec3{}, 1)
+	tests := []struct {
+		p, d      glm.Vec3
+		t         float32
+		q         glm.Vec3
+		intersect bool
+	}{
+		{glmtesting.NaN3, glm.Vec3{X: 1},
+			0, glm.Vec3{}, false}, // 0
+		{glm.Vec3{X: -5}, glmtesting.NaN3,
+			0, glm.Vec3{}, false}, // 1
+
+		{glm.Vec3{X: -5, Y: 0, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			4, glm.Vec3{X: -1, Y: 0, Z: 0}, true}, // 2
+		{glm.Vec3{X: -5, Y: 0, Z: 0}, glm.Vec3{X: 1, Y: 1, Z: 0},
+			0, glm.Vec3{}, false}, // 3
+		{glm.Vec3{X: -5, Y: -4.5, Z: 0}, glm.Vec3{X: 1, Y: 1, Z: 0},
+			4, glm.Vec3{X: -1, Y: -0.5, Z: 0}, true}, // 4
+		{glm.Vec3{X: 0, Y: 0, Z: 7}, glm.Vec3{X: 0, Y: 0, Z: -2},
+			3, glm.Vec3{X: 0, Y: 0, Z: 1}, true}, // 5
+		{glm.Vec3{X: -5, Y: 0, Z: 0}, glm.Vec3{X: -1, Y: 0, Z: 0},
+			0, glm.Vec3{}, false}, // 6
+		{glm.Vec3{X: -5, Y: 2, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			0, glm.Vec3{}, false}, // 7
+		{glm.Vec3{X: 0.5, Y: 0.5, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			0, glm.Vec3{X: 0.5, Y: 0.5, Z: 0}, true}, // 8
+	}
+	for i, test := range tests {
+		v, q, intersect := IntersectRayConvexhull(&test.p, &test.d, hull)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue // if they don't overlap then t and q are junk data.
+		}
+
+		if !glmtesting.FloatEqual(v, test.t) {
+			t.Errorf("[%d] t = %f, want %f", i, v, test.t)
+		}
+		if !glmtesting.Vec3Equal(q, test.q) {
+			t.Errorf("[%d] q = %s, want %s", i, q.String(), test.q.String())
+		}
+	}
+}
+
+func TestRaycast_HullWinding(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := func() glm.Vec3 {
+		return glm.Vec3{X: r.Float32()*2 - 1, Y: r.Float32()*2 - 1, Z: r.Float32()*2 - 1}
+	}
+	for i := 0; i < 1000; i++ {
+		points := make([]glm.Vec3, 12)
+		for n := range points {
+			points[n] = random()
+		}
+		hull := Quickhull(points)
+		// the faces are oriented from the inside of the hull, not from their
+		// winding.
+		for n := 0; n < len(hull.Triangles); n += 2 {
+			v := &hull.Triangles[n].Vertices
+			v[1], v[2] = v[2], v[1]
+		}
+
+		center := hull.interior()
+		d := random()
+		d.Normalize()
+		p := center
+		p.AddScaledVec(-5, &d)
+		hit, ok := Raycast(hull, &p, &d, 10)
+		if !ok {
+			t.Errorf("[%d] the ray towards the centroid missed", i)
+			continue
+		}
+		if !(hit.T > 0) || hit.Normal.Dot(&d) >= 0 {
+			t.Errorf("[%d] T = %f, normal = %s, want T > 0 and a normal facing the ray", i, hit.T, hit.Normal.String())
+		}
+		if _, ok := Raycast(hull, &center, &d, 10); !ok {
+			t.Errorf("[%d] the ray from the centroid missed", i)
+		}
+	}
+}
+
+func TestIntersectRayPlane(t *testing.T) {
+	tests := []struct {
+		p, d      glm.Vec3
+		plane     Plane
+		t         float32
+		q         glm.Vec3
+		intersect bool
+	}{
+		{glmtesting.NaN3, glm.Vec3{Y: -1},
+			Plane{glm.Vec3{Y: 1}, 1},
+			0, glm.Vec3{}, false}, // 0
+		{glm.Vec3{Y: 5}, glmtesting.NaN3,
+			Plane{glm.Vec3{Y: 1}, 1},
+			0, glm.Vec3{}, false}, // 1
+		{glm.Vec3{Y: 5}, glm.Vec3{Y: -1},
+			Plane{glm.Vec3{Y: 1}, math.NaN()},
+			0, glm.Vec3{}, false}, // 2
+
+		{glm.Vec3{X: 0, Y: 5, Z: 0}, glm.Vec3{X: 0, Y: -1, Z: 0},
+			Plane{glm.Vec3{Y: 1}, 1},
+			4, glm.Vec3{X: 0, Y: 1, Z: 0}, true}, // 3
+		{glm.Vec3{X: 3, Y: -5, Z: 0}, glm.Vec3{X: 0, Y: 2, Z: 0},
+			Plane{glm.Vec3{Y: 1}, 1},
+			3, glm.Vec3{X: 3, Y: 1, Z: 0}, true}, // 4
+		{glm.Vec3{X: 0, Y: 5, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			Plane{glm.Vec3{Y: 1}, 1},
+			0, glm.Vec3{}, false}, // 5
+		{glm.Vec3{X: 0, Y: 5, Z: 0}, glm.Vec3{X: 0, Y: 1, Z: 0},
+			Plane{glm.Vec3{Y: 1}, 1},
+			0, glm.Vec3{}, false}, // 6
+	}
+	for i, test := range tests {
+		v, q, intersect := IntersectRayPlane(&test.p, &test.d, &test.plane)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue // if they don't overlap then t and q are junk data.
+		}
+
+		if !glmtesting.FloatEqual(v, test.t) {
+			t.Errorf("[%d] t = %f, want %f", i, v, test.t)
+		}
+		if !glmtesting.Vec3Equal(q, test.q) {
+			t.Errorf("[%d] q = %s, want %s", i, q.String(), test.q.String())
+		}
+	}
+}
+
+func TestIntersectRayTriangle(t *testing.T) {
+	a, b, c := glm.Vec3{X: 0, Y: 0, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0}, glm.Vec3{X: 0, Y: 1, Z: 0}
+	tests := []struct {
+		p, d      glm.Vec3
+		u, v, w   float32
+		t         float32
+		intersect bool
+	}{
+		{glmtesting.NaN3, glm.Vec3{Z: -1},
+			0, 0, 0, 0, false}, // 0
+		{glm.Vec3{Z: 5}, glmtesting.NaN3,
+			0, 0, 0, 0, false}, // 1
+
+		{glm.Vec3{X: 0.25, Y: 0.25, Z: 5}, glm.Vec3{X: 0, Y: 0, Z: -1},
+			0.5, 0.25, 0.25, 5, true}, // 2
+		{glm.Vec3{X: 0.5, Y: 0.25, Z: -5}, glm.Vec3{X: 0, Y: 0, Z: 2},
+			0.25, 0.5, 0.25, 2.5, true}, // 3
+		{glm.Vec3{X: 1, Y: 1, Z: 5}, glm.Vec3{X: 0, Y: 0, Z: -1},
+			0, 0, 0, 0, false}, // 4
+		{glm.Vec3{X: 0.25, Y: 0.25, Z: 5}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			0, 0, 0, 0, false}, // 5
+		{glm.Vec3{X: 0.25, Y: 0.25, Z: 5}, glm.Vec3{X: 0, Y: 0, Z: 1},
+			0, 0, 0, 0, false}, // 6
+		{glm.Vec3{X: -6, Y: 0, Z: 5}, glm.Vec3{X: 1, Y: 0, Z: -1},
+			0, 0, 0, 0, false}, // 7
+		{glm.Vec3{X: -4, Y: 0, Z: 5}, glm.Vec3{X: 1, Y: 0, Z: -1},
+			0, 1, 0, 5, true}, // 8
+	}
+	for i, test := range tests {
+		u, v, w, tt, intersect := IntersectRayTriangle(&test.p, &test.d, &a, &b, &c)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue // if they don't overlap then the other values are junk data.
+		}
+
+		if !glmtesting.FloatEqual(u, test.u) || !glmtesting.FloatEqual(v, test.v) || !glmtesting.FloatEqual(w, test.w) {
+			t.Errorf("[%d] u, v, w = %f, %f, %f, want %f, %f, %f", i, u, v, w, test.u, test.v, test.w)
+		}
+		if !glmtesting.FloatEqual(tt, test.t) {
+			t.Errorf("[%d] t = %f, want %f", i, tt, test.t)
+		}
+	}
+}
+
+func TestIntersectRayRect(t *testing.T) {
+	rect := Rect{
+		Center:      glm.Vec3{X: 0, Y: 0, Z: 0},
+		Orientation: [2]glm.Vec3{{X: 1, Y: 0, Z: 0}, {X: 0, Y: 0, Z: 1}},
+		HalfExtend:  glm.Vec2{X: 1, Y: 2},
+	}
+	tests := []struct {
+		p, d      glm.Vec3
+		rect      Rect
+		t         float32
+		q         glm.Vec3
+		intersect bool
+	}{
+		{glmtesting.NaN3, glm.Vec3{Y: -1},
+			rect,
+			0, glm.Vec3{}, false}, // 0
+		{glm.Vec3{Y: 5}, glmtesting.NaN3,
+			rect,
+			0, glm.Vec3{}, false}, // 1
+
+		{glm.Vec3{X: 0.5, Y: 5, Z: 1.5}, glm.Vec3{X: 0, Y: -1, Z: 0},
+			rect,
+			5, glm.Vec3{X: 0.5, Y: 0, Z: 1.5}, true}, // 2
+		{glm.Vec3{X: 1.5, Y: 5, Z: 0}, glm.Vec3{X: 0, Y: -1, Z: 0},
+			rect,
+			0, glm.Vec3{}, false}, // 3
+		{glm.Vec3{X: 0, Y: -5, Z: -1.9}, glm.Vec3{X: 0, Y: 1, Z: 0},
+			rect,
+			5, glm.Vec3{X: 0, Y: 0, Z: -1.9}, true}, // 4
+		{glm.Vec3{X: 0, Y: -5, Z: -2.1}, glm.Vec3{X: 0, Y: 1, Z: 0},
+			rect,
+			0, glm.Vec3{}, false}, // 5
+		{glm.Vec3{X: 0, Y: 5, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0},
+			rect,
+			0, glm.Vec3{}, false}, // 6
+		{glm.Vec3{X: -3, Y: 3, Z: 0}, glm.Vec3{X: 1, Y: -1, Z: 0},
+			rect,
+			3, glm.Vec3{X: 0, Y: 0, Z: 0}, true}, // 7
+	}
+	for i, test := range tests {
+		v, q, intersect := IntersectRayRect(&test.p, &test.d, &test.rect)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue // if they don't overlap then t and q are junk data.
+		}
+
+		if !glmtesting.FloatEqual(v, test.t) {
+			t.Errorf("[%d] t = %f, want %f", i, v, test.t)
+		}
+		if !glmtesting.Vec3Equal(q, test.q) {
+			t.Errorf("[%d] q = %s, want %s", i, q.String(), test.q.String())
+		}
+	}
+}
+
+func TestRaycast(t *testing.T) {
+	// rotated 45 degrees around z.
+	const h = 0.70710678
+	rotz := glm.Mat3{h, h, 0, -h, h, 0, 0, 0, 1}
+	aabb := &AABB{Center: glm.Vec3{}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}
+	capsule := &Capsule{A: glm.Vec3{X: 0, Y: -1, Z: 0}, B: glm.Vec3{X: 0, Y: 1, Z: 0}, Radius: 0.5}
+	tests := []struct {
+		shape Shape
+		p, d  glm.Vec3
+		maxT  float32
+		hit   RaycastHit
+		ok    bool
+	}{
+		{ // 0
+			aabb,
+			glm.Vec3{X: -5, Y: 0.5, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0}, math.MaxFloat32,
+			RaycastHit{4, glm.Vec3{X: -1, Y: 0.5, Z: 0}, glm.Vec3{X: -1, Y: 0, Z: 0}}, true,
+		},
+		{ // 1
+			aabb,
+			glm.Vec3{X: -5, Y: 0.5, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0}, 3,
+			RaycastHit{}, false,
+		},
+		{ // 2
+			aabb,
+			glm.Vec3{X: 0, Y: 0, Z: 0}, glm.Vec3{X: 2, Y: 0, Z: 0}, math.MaxFloat32,
+			RaycastHit{0, glm.Vec3{}, glm.Vec3{X: -1, Y: 0, Z: 0}}, true,
+		},
+		{ // 3
+			&Sphere{Center: glm.Vec3{}, Radius: 1},
+			glm.Vec3{X: 0, Y: 5, Z: 0}, glm.Vec3{X: 0, Y: -2, Z: 0}, math.MaxFloat32,
+			RaycastHit{2, glm.Vec3{X: 0, Y: 1, Z: 0}, glm.Vec3{X: 0, Y: 1, Z: 0}}, true,
+		},
+		{ // 4
+			&Sphere{Center: glm.Vec3{}, Radius: 1},
+			glm.Vec3{X: 0, Y: 5, Z: 0}, glm.Vec3{X: 0, Y: 2, Z: 0}, math.MaxFloat32,
+			RaycastHit{}, false,
+		},
+		{ // 5
+			&OBB{Center: glm.Vec3{}, Orientation: rotz, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glm.Vec3{X: 5, Y: 5, Z: 0}, glm.Vec3{X: -1, Y: -1, Z: 0}, math.MaxFloat32,
+			RaycastHit{5 - h, glm.Vec3{X: h, Y: h, Z: 0}, glm.Vec3{X: h, Y: h, Z: 0}}, true,
+		},
+		{ // 6
+			capsule,
+			glm.Vec3{X: -5, Y: 0, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 0}, math.MaxFloat32,
+			RaycastHit{4.5, glm.Vec3{X: -0.5, Y: 0, Z: 0}, glm.Vec3{X: -1, Y: 0, Z: 0}}, true,
+		},
+		{ // 7
+			capsule,
+			glm.Vec3{X: 0, Y: 5, Z: 0}, glm.Vec3{X: 0, Y: -1, Z: 0}, math.MaxFloat32,
+			RaycastHit{3.5, glm.Vec3{X: 0, Y: 1.5, Z: 0}, glm.Vec3{X: 0, Y: 1, Z: 0}}, true,
+		},
+		{ // 8
+			cubeHull(glm.Vec3{}, 1),
+			glm.Vec3{X: 0.5, Y: 0, Z: 5}, glm.Vec3{X: 0, Y: 0, Z: -1}, math.MaxFloat32,
+			RaycastHit{4, glm.Vec3{X: 0.5, Y: 0, Z: 1}, glm.Vec3{X: 0, Y: 0, Z: 1}}, true,
+		},
+		{ // 9
+			cubeHull(glm.Vec3{}, 1),
+			glm.Vec3{X: 0.5, Y: 0, Z: 5}, glm.Vec3{X: 0, Y: 1, Z: -1}, math.MaxFloat32,
+			RaycastHit{}, false,
+		},
+	}
+	for i, test := range tests {
+		hit, ok := Raycast(test.shape, &test.p, &test.d, test.maxT)
+		if ok != test.ok {
+			t.Errorf("[%d] ok = %t, want %t", i, ok, test.ok)
+		}
+		if !test.ok {
+			continue
+		}
+		if math.Abs(hit.T-test.hit.T) > 1e-5 || !vec3Near(hit.Point, test.hit.Point, 1e-5) || !vec3Near(hit.Normal, test.hit.Normal, 1e-5) {
+			t.Errorf("[%d] hit = %v, want %v", i, hit, test.hit)
+		}
+	}
+}
+
+func TestRaycastShapes(t *testing.T) {
+	shapes := []Shape{
+		&Sphere{Center: glm.Vec3{X: 10, Y: 0, Z: 0}, Radius: 1},
+		&AABB{Center: glm.Vec3{X: 5, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+		cubeHull(glm.Vec3{X: 20, Y: 0, Z: 0}, 1),
+		&Capsule{A: glm.Vec3{X: 3, Y: 2, Z: 0}, B: glm.Vec3{X: 3, Y: 4, Z: 0}, Radius: 0.5},
+	}
+	tests := []struct {
+		shapes []Shape
+		maxT   float32
+		index  int
+		t      float32
+		ok     bool
+	}{
+		{shapes, math.MaxFloat32, 1, 4, true},
+		{shapes, 3, -1, 0, false},
+		{shapes[2:], math.MaxFloat32, 0, 19, true},
+		{nil, math.MaxFloat32, -1, 0, false},
+	}
+	p, d := glm.Vec3{}, glm.Vec3{X: 1, Y: 0, Z: 0}
+	for i, test := range tests {
+		index, hit, ok := RaycastShapes(test.shapes, &p, &d, test.maxT)
+		if index != test.index || ok != test.ok {
+			t.Errorf("[%d] index, ok = %d, %t, want %d, %t", i, index, ok, test.index, test.ok)
+			continue
+		}
+		if ok && !glmtesting.FloatEqual(hit.T, test.t) {
+			t.Errorf("[%d] t = %f, want %f", i, hit.T, test.t)
+		}
+	}
+}
+
+func BenchmarkRaycast(b *testing.B) {
+	shapes := []Shape{
+		&Sphere{Center: glm.Vec3{X: 10, Y: 0, Z: 0}, Radius: 1},
+		&AABB{Center: glm.Vec3{X: 5, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+		&OBB{Center: glm.Vec3{X: 15, Y: 0, Z: 0}, Orientation: glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+		&Capsule{A: glm.Vec3{X: 3, Y: -1, Z: 0}, B: glm.Vec3{X: 3, Y: 1, Z: 0}, Radius: 0.5},
+		cubeHull(glm.Vec3{X: 20, Y: 0, Z: 0}, 1),
+	}
+	p, d := glm.Vec3{}, glm.Vec3{X: 1, Y: 0, Z: 0}
+	for n := 0; n < b.N; n++ {
+		RaycastShapes(shapes, &p, &d, math.MaxFloat32)
+	}
+}
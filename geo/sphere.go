@@ -34,6 +34,20 @@ func (sphere *Sphere) InertiaTensor(density float32) glm.Mat3 {
 	return tensors.Sphere(sphere.Mass(density), sphere.Radius)
 }
 
+// Support returns the point of the sphere that is the most in the given
+// direction. The direction doesn't need to be normalized.
+func (sphere *Sphere) Support(direction *glm.Vec3) glm.Vec3 {
+	l := direction.Len()
+	if l == 0 {
+		return sphere.Center
+	}
+	return sphere.Center.Add(&glm.Vec3{
+		X: direction.X * sphere.Radius / l,
+		Y: direction.Y * sphere.Radius / l,
+		Z: direction.Z * sphere.Radius / l,
+	})
+}
+
 // TestSphereSphere return true if the spheres overlap.
 func TestSphereSphere(a, b *Sphere) bool {
 	d := b.Center.Sub(&a.Center)
@@ -118,8 +132,11 @@ func eigenSphere(points []glm.Vec3) Sphere {
 }
 
 // RitterEigenSphere sets this sphere to wrap all the given points using eigen
-// values as base.
+// values as base. No points give a sphere of size 0 at the origin.
 func RitterEigenSphere(points []glm.Vec3) Sphere {
+	if len(points) == 0 {
+		return Sphere{}
+	}
 	// Start with sphere from maximum spread
 	s := eigenSphere(points)
 	// Grow sphere to include all points
@@ -154,6 +154,23 @@ func TestRitterEigenSphere(t *testing.T) {
 	}
 }
 
+func TestSphere_Support(t *testing.T) {
+	s := Sphere{Center: glm.Vec3{X: 1, Y: 2, Z: 3}, Radius: 2}
+	tests := []struct {
+		direction, support glm.Vec3
+	}{
+		{glm.Vec3{X: 1, Y: 0, Z: 0}, glm.Vec3{X: 3, Y: 2, Z: 3}},
+		{glm.Vec3{X: 0, Y: -5, Z: 0}, glm.Vec3{X: 1, Y: 0, Z: 3}},
+		{glm.Vec3{X: 0, Y: 3, Z: 4}, glm.Vec3{X: 1, Y: 3.2, Z: 4.6}},
+		{glm.Vec3{}, glm.Vec3{X: 1, Y: 2, Z: 3}},
+	}
+	for i, test := range tests {
+		if support := s.Support(&test.direction); !support.EqualThreshold(&test.support, 1e-6) {
+			t.Errorf("[%d] support = %v, want %v", i, support, test.support)
+		}
+	}
+}
+
 func BenchmarkTestSphereSphere(tb *testing.B) {
 	a := Sphere{
 		Center: glm.Vec3{},
@@ -0,0 +1,224 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+)
+
+// IntersectMovingSpherePlane intersects sphere s, moving by v between t = 0
+// and t = 1, against plane p. When intersecting, return the time t of the
+// first contact and the contact point q. If the sphere starts touching the
+// plane t is 0.
+func IntersectMovingSpherePlane(s *Sphere, v *glm.Vec3, p *Plane) (t float32, q glm.Vec3, intersect bool) {
+	// Compute distance of sphere center to plane
+	dist := DistanceToPlane(p, &s.Center)
+	if math.Abs(dist) <= s.Radius {
+		// The sphere is already overlapping the plane. Set time of
+		// intersection to zero and q to sphere center projected on the plane
+		q = s.Center
+		q.AddScaledVec(-dist, &p.Normal)
+		return 0, q, true
+	}
+	denom := p.Normal.Dot(v)
+	// No intersection as sphere moving parallel to or away from plane
+	if !(denom*dist < 0) {
+		return
+	}
+	// Sphere is moving towards the plane, use +r in computations if sphere in
+	// front of plane, else -r
+	r := s.Radius
+	if dist < 0 {
+		r = -r
+	}
+	t = (r - dist) / denom
+	if t > 1 {
+		return 0, glm.Vec3{}, false
+	}
+	q = s.Center
+	q.AddScaledVec(t, v)
+	q.AddScaledVec(-r, &p.Normal)
+	return t, q, true
+}
+
+// IntersectMovingSphereSphere intersects sphere s0, moving by v0, against
+// sphere s1, moving by v1, between t = 0 and t = 1. When intersecting, return
+// the time t of the first contact and the contact point q. If the spheres
+// start overlapping t is 0.
+func IntersectMovingSphereSphere(s0, s1 *Sphere, v0, v1 *glm.Vec3) (t float32, q glm.Vec3, intersect bool) {
+	// Move s0 relative to s1 and grow s1 by the radius of s0, the moving
+	// sphere becomes a ray.
+	r := s0.Radius + s1.Radius
+	d := s0.Center.Sub(&s1.Center)
+	if !(d.Len2() <= r*r) {
+		v := v0.Sub(v1)
+		t, _, intersect = intersectRaySphereScaled(&s0.Center, &v, &s1.Center, r)
+		if !intersect || t > 1 {
+			return 0, glm.Vec3{}, false
+		}
+	}
+	c0, c1 := s0.Center, s1.Center
+	c0.AddScaledVec(t, v0)
+	c1.AddScaledVec(t, v1)
+	n := c1.Sub(&c0)
+	n.Normalize()
+	q = c0
+	q.AddScaledVec(s0.Radius, &n)
+	return t, q, true
+}
+
+// IntersectMovingSphereAABB intersects sphere s, moving by v between t = 0 and
+// t = 1, against AABB b. When intersecting, return the time t of the first
+// contact and the contact point q. If the sphere starts overlapping the box t
+// is 0.
+func IntersectMovingSphereAABB(s *Sphere, v *glm.Vec3, b *AABB) (t float32, q glm.Vec3, intersect bool) {
+	// Compute the AABB resulting from expanding b by sphere radius r
+	e := AABB{
+		Center:     b.Center,
+		HalfExtend: b.HalfExtend.Add(&glm.Vec3{X: s.Radius, Y: s.Radius, Z: s.Radius}),
+	}
+	// Intersect ray against expanded AABB e. Exit with no intersection if ray
+	// misses e, else get intersection point p and time t as result
+	t, p, intersect := IntersectRayAABB(&s.Center, v, &e)
+	if !intersect || t > 1 {
+		return 0, glm.Vec3{}, false
+	}
+	// Compute which min and max faces of b the intersection point p lies
+	// outside of. Note, u and w cannot have the same bits set and
+	// they must have at least one bit set among them
+	min, max := b.Center.Sub(&b.HalfExtend), b.Center.Add(&b.HalfExtend)
+	var u, w uint
+	for i := 0; i < 3; i++ {
+		if *p.I(i) < *min.I(i) {
+			u |= 1 << uint(i)
+		}
+		if *p.I(i) > *max.I(i) {
+			w |= 1 << uint(i)
+		}
+	}
+	corner := func(n uint) glm.Vec3 {
+		c := min
+		for i := 0; i < 3; i++ {
+			if n&(1<<uint(i)) != 0 {
+				*c.I(i) = *max.I(i)
+			}
+		}
+		return c
+	}
+	m := u + w
+	switch {
+	case m == 7:
+		// p is in a vertex region, the sphere hits one of the 3 edges meeting
+		// at the vertex first or it doesn't hit the box at all.
+		t = math.MaxFloat32
+		for _, k := range [3]uint{1, 2, 4} {
+			c := Capsule{A: corner(w), B: corner(w ^ k), Radius: s.Radius}
+			if tc, _, ok := IntersectRayCapsule(&s.Center, v, &c); ok && tc < t {
+				t = tc
+			}
+		}
+		if t > 1 {
+			return 0, glm.Vec3{}, false
+		}
+	case m&(m-1) == 0:
+		// p is in a face region, or inside the box, t is right.
+	default:
+		// p is in an edge region. Intersect against the capsule at the edge
+		c := Capsule{A: corner(u ^ 7), B: corner(w), Radius: s.Radius}
+		t, _, intersect = IntersectRayCapsule(&s.Center, v, &c)
+		if !intersect || t > 1 {
+			return 0, glm.Vec3{}, false
+		}
+	}
+	c := s.Center
+	c.AddScaledVec(t, v)
+	return t, ClosestPointPointAABB(&c, b), true
+}
+
+// IntersectMovingSphereOBB intersects sphere s, moving by v between t = 0 and
+// t = 1, against OBB o. When intersecting, return the time t of the first
+// contact and the contact point q. If the sphere starts overlapping the box t
+// is 0.
+func IntersectMovingSphereOBB(s *Sphere, v *glm.Vec3, o *OBB) (t float32, q glm.Vec3, intersect bool) {
+	// express the sphere in the frame of the box, the box becomes an AABB.
+	d := s.Center.Sub(&o.Center)
+	local := Sphere{Center: o.Orientation.Mul3x1(&d), Radius: s.Radius}
+	lv := o.Orientation.Mul3x1(v)
+	t, lq, intersect := IntersectMovingSphereAABB(&local, &lv, &AABB{HalfExtend: o.HalfExtend})
+	if !intersect {
+		return
+	}
+	q = o.Orientation.Mul3x1Transpose(&lq)
+	q.AddWith(&o.Center)
+	return t, q, true
+}
+
+// IntersectMovingSphereTriangle intersects sphere s, moving by v between t = 0
+// and t = 1, against triangle abc. When intersecting, return the time t of the
+// first contact and the contact point q. If the sphere starts overlapping the
+// triangle t is 0.
+func IntersectMovingSphereTriangle(s *Sphere, v *glm.Vec3, a, b, c *glm.Vec3) (t float32, q glm.Vec3, intersect bool) {
+	q = ClosestPointPointTriangle(&s.Center, a, b, c)
+	if d := q.Sub(&s.Center); d.Len2() <= s.Radius*s.Radius {
+		return 0, q, true
+	}
+
+	// If the sphere touches the plane of the triangle inside of it, that's the
+	// first contact.
+	p := PlaneFromPoints(a, b, c)
+	if t, q, intersect = IntersectMovingSpherePlane(s, v, &p); intersect && IsPointInTriangle(&q, a, b, c) {
+		return t, q, true
+	}
+
+	// Otherwise the sphere hits an edge or a vertex first, the moving sphere
+	// is a ray against the capsules around the edges.
+	t = math.MaxFloat32
+	for _, e := range [3][2]*glm.Vec3{{a, b}, {b, c}, {c, a}} {
+		capsule := Capsule{A: *e[0], B: *e[1], Radius: s.Radius}
+		if te, _, ok := IntersectRayCapsule(&s.Center, v, &capsule); ok && te < t {
+			t = te
+		}
+	}
+	if t > 1 {
+		return 0, glm.Vec3{}, false
+	}
+	center := s.Center
+	center.AddScaledVec(t, v)
+	return t, ClosestPointPointTriangle(&center, a, b, c), true
+}
+
+// IntersectMovingAABBAABB intersects AABB a, moving by va, against AABB b,
+// moving by vb, between t = 0 and t = 1. When intersecting, return the times
+// of first and last contact, tfirst is 0 if the boxes start overlapping.
+func IntersectMovingAABBAABB(a, b *AABB, va, vb *glm.Vec3) (tfirst, tlast float32, intersect bool) {
+	// Use the motion of b relative to a, on each axis the boxes overlap while
+	// lo <= v*t <= hi.
+	v := vb.Sub(va)
+	tfirst, tlast = 0, 1
+	for i := 0; i < 3; i++ {
+		d := *a.Center.I(i) - *b.Center.I(i)
+		h := *a.HalfExtend.I(i) + *b.HalfExtend.I(i)
+		lo, hi, vi := d-h, d+h, *v.I(i)
+		if vi == 0 {
+			// not moving on this axis, the boxes overlap on it or never touch.
+			if !(lo <= 0 && hi >= 0) {
+				return 0, 0, false
+			}
+			continue
+		}
+		t0, t1 := lo/vi, hi/vi
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		// written so that NaNs end up in tfirst or tlast and fail the test below.
+		if !(t0 <= tfirst) {
+			tfirst = t0
+		}
+		if !(t1 >= tlast) {
+			tlast = t1
+		}
+		if !(tfirst <= tlast) {
+			return 0, 0, false
+		}
+	}
+	return tfirst, tlast, true
+}
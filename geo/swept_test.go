@@ -0,0 +1,317 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/glm/glmtesting"
+	"github.com/luxengine/lux/math"
+	"testing"
+)
+
+func TestIntersectMovingSpherePlane(t *testing.T) {
+	plane := Plane{Normal: glm.Vec3{X: 0, Y: 1, Z: 0}, Offset: 0}
+	tests := []struct {
+		sphere    Sphere
+		v         glm.Vec3
+		plane     Plane
+		t         float32
+		q         glm.Vec3
+		intersect bool
+	}{
+		{Sphere{glmtesting.NaN3, 1}, glm.Vec3{Y: -10},
+			plane,
+			0, glm.Vec3{}, false}, // 0
+		{Sphere{glm.Vec3{Y: 5}, 1}, glmtesting.NaN3,
+			plane,
+			0, glm.Vec3{}, false}, // 1
+		{Sphere{glm.Vec3{Y: 5}, 1}, glm.Vec3{Y: -10},
+			Plane{glm.Vec3{Y: 1}, math.NaN()},
+			0, glm.Vec3{}, false}, // 2
+
+		{Sphere{glm.Vec3{X: 0, Y: 5, Z: 0}, 1}, glm.Vec3{X: 0, Y: -10, Z: 0},
+			plane,
+			0.4, glm.Vec3{X: 0, Y: 0, Z: 0}, true}, // 3
+		{Sphere{glm.Vec3{X: 0, Y: -5, Z: 0}, 1}, glm.Vec3{X: 10, Y: 10, Z: 0},
+			plane,
+			0.4, glm.Vec3{X: 4, Y: 0, Z: 0}, true}, // 4
+		{Sphere{glm.Vec3{X: 0, Y: 5, Z: 0}, 1}, glm.Vec3{X: 0, Y: -3, Z: 0},
+			plane,
+			0, glm.Vec3{}, false}, // 5
+		{Sphere{glm.Vec3{X: 0, Y: 5, Z: 0}, 1}, glm.Vec3{X: 0, Y: 10, Z: 0},
+			plane,
+			0, glm.Vec3{}, false}, // 6
+		{Sphere{glm.Vec3{X: 0, Y: 5, Z: 0}, 1}, glm.Vec3{X: 10, Y: 0, Z: 0},
+			plane,
+			0, glm.Vec3{}, false}, // 7
+		{Sphere{glm.Vec3{X: 1, Y: 0.5, Z: 0}, 1}, glm.Vec3{X: 10, Y: 0, Z: 0},
+			plane,
+			0, glm.Vec3{X: 1, Y: 0, Z: 0}, true}, // 8
+	}
+	for i, test := range tests {
+		v, q, intersect := IntersectMovingSpherePlane(&test.sphere, &test.v, &test.plane)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue // if they don't overlap then t and q are junk data.
+		}
+
+		if !glmtesting.FloatEqual(v, test.t) {
+			t.Errorf("[%d] t = %f, want %f", i, v, test.t)
+		}
+		if !glmtesting.Vec3Equal(q, test.q) {
+			t.Errorf("[%d] q = %s, want %s", i, q.String(), test.q.String())
+		}
+	}
+}
+
+func TestIntersectMovingSphereSphere(t *testing.T) {
+	tests := []struct {
+		s0, s1    Sphere
+		v0, v1    glm.Vec3
+		t         float32
+		q         glm.Vec3
+		intersect bool
+	}{
+		{Sphere{glmtesting.NaN3, 1}, Sphere{glm.Vec3{X: 5}, 1},
+			glm.Vec3{X: 10}, glm.Vec3{},
+			0, glm.Vec3{}, false}, // 0
+		{Sphere{glm.Vec3{}, 1}, Sphere{glm.Vec3{X: 5}, 1},
+			glmtesting.NaN3, glm.Vec3{},
+			0, glm.Vec3{}, false}, // 1
+
+		{Sphere{glm.Vec3{}, 1}, Sphere{glm.Vec3{X: 5}, 1},
+			glm.Vec3{X: 10}, glm.Vec3{},
+			0.3, glm.Vec3{X: 4, Y: 0, Z: 0}, true}, // 2
+		{Sphere{glm.Vec3{}, 1}, Sphere{glm.Vec3{X: 5}, 1},
+			glm.Vec3{X: 5}, glm.Vec3{X: -5},
+			0.3, glm.Vec3{X: 2.5, Y: 0, Z: 0}, true}, // 3
+		{Sphere{glm.Vec3{}, 1}, Sphere{glm.Vec3{X: 5, Y: 3}, 1},
+			glm.Vec3{X: 10}, glm.Vec3{},
+			0, glm.Vec3{}, false}, // 4
+		{Sphere{glm.Vec3{}, 1}, Sphere{glm.Vec3{X: 5}, 1},
+			glm.Vec3{X: 2}, glm.Vec3{},
+			0, glm.Vec3{}, false}, // 5
+		{Sphere{glm.Vec3{}, 1}, Sphere{glm.Vec3{X: 5}, 1},
+			glm.Vec3{}, glm.Vec3{},
+			0, glm.Vec3{}, false}, // 6
+		{Sphere{glm.Vec3{}, 1}, Sphere{glm.Vec3{X: 1.5}, 1},
+			glm.Vec3{X: 10}, glm.Vec3{},
+			0, glm.Vec3{X: 1, Y: 0, Z: 0}, true}, // 7
+		{Sphere{glm.Vec3{}, 1}, Sphere{glm.Vec3{X: 5}, 2},
+			glm.Vec3{X: -10}, glm.Vec3{X: -20},
+			0.2, glm.Vec3{X: -1, Y: 0, Z: 0}, true}, // 8
+	}
+	for i, test := range tests {
+		v, q, intersect := IntersectMovingSphereSphere(&test.s0, &test.s1, &test.v0, &test.v1)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue // if they don't overlap then t and q are junk data.
+		}
+
+		if !glmtesting.FloatEqual(v, test.t) {
+			t.Errorf("[%d] t = %f, want %f", i, v, test.t)
+		}
+		if !glmtesting.Vec3Equal(q, test.q) {
+			t.Errorf("[%d] q = %s, want %s", i, q.String(), test.q.String())
+		}
+	}
+}
+
+func TestIntersectMovingSphereAABB(t *testing.T) {
+	aabb := AABB{Center: glm.Vec3{}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}
+	tests := []struct {
+		sphere    Sphere
+		v         glm.Vec3
+		t         float32
+		q         glm.Vec3
+		intersect bool
+	}{
+		{Sphere{glmtesting.NaN3, 0.5}, glm.Vec3{X: 10},
+			0, glm.Vec3{}, false}, // 0
+		{Sphere{glm.Vec3{X: -5}, 0.5}, glmtesting.NaN3,
+			0, glm.Vec3{}, false}, // 1
+
+		{Sphere{glm.Vec3{X: -5, Y: 0, Z: 0}, 0.5}, glm.Vec3{X: 10},
+			0.35, glm.Vec3{X: -1, Y: 0, Z: 0}, true}, // 2 face
+		{Sphere{glm.Vec3{X: -5, Y: 1.3, Z: 0}, 0.5}, glm.Vec3{X: 10},
+			0.36, glm.Vec3{X: -1, Y: 1, Z: 0}, true}, // 3 edge
+		{Sphere{glm.Vec3{X: -5, Y: 1.3, Z: 1.3}, 0.5}, glm.Vec3{X: 10},
+			(5 - 1 - math.Sqrt(0.07)) / 10, glm.Vec3{X: -1, Y: 1, Z: 1}, true}, // 4 vertex
+		{Sphere{glm.Vec3{X: -5, Y: 1.45, Z: 1.45}, 0.5}, glm.Vec3{X: 10},
+			0, glm.Vec3{}, false}, // 5 vertex region, misses the corner
+		{Sphere{glm.Vec3{X: -5, Y: 1.6, Z: 0}, 0.5}, glm.Vec3{X: 10},
+			0, glm.Vec3{}, false}, // 6
+		{Sphere{glm.Vec3{X: -5, Y: 0, Z: 0}, 0.5}, glm.Vec3{X: 2},
+			0, glm.Vec3{}, false}, // 7
+		{Sphere{glm.Vec3{X: 0, Y: 1.2, Z: 0}, 0.5}, glm.Vec3{X: 2},
+			0, glm.Vec3{X: 0, Y: 1, Z: 0}, true}, // 8
+		{Sphere{glm.Vec3{X: 5, Y: 5, Z: 0}, 0.5}, glm.Vec3{X: -10, Y: -10},
+			(4 - 0.5*math.Sqrt(0.5)) / 10, glm.Vec3{X: 1, Y: 1, Z: 0}, true}, // 9 edge
+	}
+	for i, test := range tests {
+		v, q, intersect := IntersectMovingSphereAABB(&test.sphere, &test.v, &aabb)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue // if they don't overlap then t and q are junk data.
+		}
+
+		if math.Abs(v-test.t) > 1e-5 {
+			t.Errorf("[%d] t = %f, want %f", i, v, test.t)
+		}
+		if !vec3Near(q, test.q, 1e-5) {
+			t.Errorf("[%d] q = %s, want %s", i, q.String(), test.q.String())
+		}
+	}
+}
+
+func TestIntersectMovingSphereOBB(t *testing.T) {
+	ident := glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}
+	// rotated 45 degrees around z.
+	const h = 0.70710678
+	rotz := glm.Mat3{h, h, 0, -h, h, 0, 0, 0, 1}
+	tests := []struct {
+		sphere    Sphere
+		v         glm.Vec3
+		obb       OBB
+		t         float32
+		q         glm.Vec3
+		intersect bool
+	}{
+		{Sphere{glm.Vec3{X: -5}, 0.5}, glm.Vec3{X: 10},
+			OBB{glm.Vec3{X: 3}, ident, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			0.65, glm.Vec3{X: 2, Y: 0, Z: 0}, true}, // 0
+		{Sphere{glm.Vec3{X: -5}, 0.5}, glm.Vec3{X: 10},
+			OBB{glm.Vec3{}, rotz, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			(4.5 - math.Sqrt(2)) / 10, glm.Vec3{X: -math.Sqrt(2), Y: 0, Z: 0}, true}, // 1
+		{Sphere{glm.Vec3{X: -5, Y: 2}, 0.5}, glm.Vec3{X: 10},
+			OBB{glm.Vec3{}, rotz, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			0, glm.Vec3{}, false}, // 2
+		{Sphere{glm.Vec3{X: 0.5}, 0.5}, glm.Vec3{X: 10},
+			OBB{glm.Vec3{}, rotz, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			0, glm.Vec3{X: 0.5}, true}, // 3
+	}
+	for i, test := range tests {
+		v, q, intersect := IntersectMovingSphereOBB(&test.sphere, &test.v, &test.obb)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue // if they don't overlap then t and q are junk data.
+		}
+
+		if math.Abs(v-test.t) > 1e-5 {
+			t.Errorf("[%d] t = %f, want %f", i, v, test.t)
+		}
+		if !vec3Near(q, test.q, 1e-5) {
+			t.Errorf("[%d] q = %s, want %s", i, q.String(), test.q.String())
+		}
+	}
+}
+
+func TestIntersectMovingSphereTriangle(t *testing.T) {
+	a, b, c := glm.Vec3{X: 0, Y: 0, Z: 0}, glm.Vec3{X: 2, Y: 0, Z: 0}, glm.Vec3{X: 0, Y: 2, Z: 0}
+	tests := []struct {
+		sphere    Sphere
+		v         glm.Vec3
+		t         float32
+		q         glm.Vec3
+		intersect bool
+	}{
+		{Sphere{glmtesting.NaN3, 0.5}, glm.Vec3{Z: -10},
+			0, glm.Vec3{}, false}, // 0
+		{Sphere{glm.Vec3{X: 0.5, Y: 0.5, Z: 5}, 0.5}, glmtesting.NaN3,
+			0, glm.Vec3{}, false}, // 1
+
+		{Sphere{glm.Vec3{X: 0.5, Y: 0.5, Z: 5}, 0.5}, glm.Vec3{Z: -10},
+			0.45, glm.Vec3{X: 0.5, Y: 0.5, Z: 0}, true}, // 2 face
+		{Sphere{glm.Vec3{X: 0.5, Y: 0.5, Z: -5}, 0.5}, glm.Vec3{Z: 10},
+			0.45, glm.Vec3{X: 0.5, Y: 0.5, Z: 0}, true}, // 3 back face
+		{Sphere{glm.Vec3{X: 1, Y: -0.3, Z: 5}, 0.5}, glm.Vec3{Z: -10},
+			0.46, glm.Vec3{X: 1, Y: 0, Z: 0}, true}, // 4 edge
+		{Sphere{glm.Vec3{X: -0.3, Y: -0.3, Z: 5}, 0.5}, glm.Vec3{Z: -10},
+			(5 - math.Sqrt(0.07)) / 10, glm.Vec3{X: 0, Y: 0, Z: 0}, true}, // 5 vertex
+		{Sphere{glm.Vec3{X: -1, Y: -1, Z: 5}, 0.5}, glm.Vec3{Z: -10},
+			0, glm.Vec3{}, false}, // 6
+		{Sphere{glm.Vec3{X: 0.5, Y: 0.5, Z: 0.3}, 0.5}, glm.Vec3{Z: -10},
+			0, glm.Vec3{X: 0.5, Y: 0.5, Z: 0}, true}, // 7
+		{Sphere{glm.Vec3{X: 0.5, Y: 0.5, Z: 5}, 0.5}, glm.Vec3{Z: -4},
+			0, glm.Vec3{}, false}, // 8
+		{Sphere{glm.Vec3{X: -5, Y: 1, Z: 0}, 0.5}, glm.Vec3{X: 10},
+			0.45, glm.Vec3{X: 0, Y: 1, Z: 0}, true}, // 9 in the plane
+	}
+	for i, test := range tests {
+		v, q, intersect := IntersectMovingSphereTriangle(&test.sphere, &test.v, &a, &b, &c)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue // if they don't overlap then t and q are junk data.
+		}
+
+		if math.Abs(v-test.t) > 1e-5 {
+			t.Errorf("[%d] t = %f, want %f", i, v, test.t)
+		}
+		if !vec3Near(q, test.q, 1e-5) {
+			t.Errorf("[%d] q = %s, want %s", i, q.String(), test.q.String())
+		}
+	}
+}
+
+func TestIntersectMovingAABBAABB(t *testing.T) {
+	a := AABB{Center: glm.Vec3{}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}
+	tests := []struct {
+		b             AABB
+		va, vb        glm.Vec3
+		tfirst, tlast float32
+		intersect     bool
+	}{
+		{AABB{glmtesting.NaN3, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glm.Vec3{X: 10}, glm.Vec3{},
+			0, 0, false}, // 0
+		{AABB{glm.Vec3{X: 5}, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glmtesting.NaN3, glm.Vec3{},
+			0, 0, false}, // 1
+
+		{AABB{glm.Vec3{X: 5}, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glm.Vec3{X: 10}, glm.Vec3{},
+			0.3, 0.7, true}, // 2
+		{AABB{glm.Vec3{X: 5}, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glm.Vec3{X: 5}, glm.Vec3{X: -5},
+			0.3, 0.7, true}, // 3
+		{AABB{glm.Vec3{X: 5, Y: 3}, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glm.Vec3{X: 10}, glm.Vec3{},
+			0, 0, false}, // 4
+		{AABB{glm.Vec3{X: 1}, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glm.Vec3{}, glm.Vec3{},
+			0, 1, true}, // 5
+		{AABB{glm.Vec3{X: 5}, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glm.Vec3{X: 2}, glm.Vec3{},
+			0, 0, false}, // 6
+		{AABB{glm.Vec3{X: 5, Y: 5}, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glm.Vec3{X: 10, Y: 10}, glm.Vec3{},
+			0.3, 0.7, true}, // 7
+		{AABB{glm.Vec3{X: 5, Y: 5}, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glm.Vec3{X: 10, Y: 5}, glm.Vec3{},
+			0.6, 0.7, true}, // 8
+		{AABB{glm.Vec3{X: 5}, glm.Vec3{X: 1, Y: 1, Z: 1}},
+			glm.Vec3{X: -10}, glm.Vec3{},
+			0, 0, false}, // 9
+	}
+	for i, test := range tests {
+		tfirst, tlast, intersect := IntersectMovingAABBAABB(&a, &test.b, &test.va, &test.vb)
+		if intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+		if !test.intersect {
+			continue // if they don't overlap then the times are junk data.
+		}
+
+		if !glmtesting.FloatEqual(tfirst, test.tfirst) || !glmtesting.FloatEqual(tlast, test.tlast) {
+			t.Errorf("[%d] tfirst, tlast = %f, %f, want %f, %f", i, tfirst, tlast, test.tfirst, test.tlast)
+		}
+	}
+}
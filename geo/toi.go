@@ -0,0 +1,143 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+)
+
+const (
+	// toiMaxIterations bounds the number of times TimeOfImpact advances the
+	// shapes, fast spinning shapes close to each other advance very slowly.
+	toiMaxIterations = 64
+
+	// toiTolerance is how close, in distance units, the shapes need to be for
+	// TimeOfImpact to consider them touching.
+	toiTolerance = 1e-3
+)
+
+// Motion is how a shape moves between t = 0 and t = 1. The shape rotates
+// around Center while Center moves along Linear.
+type Motion struct {
+	// how far Center moves.
+	Linear glm.Vec3
+
+	// the rotation axis scaled by the angle turned, in radians.
+	Angular glm.Vec3
+
+	// the point the shape rotates around, usually its center of mass.
+	Center glm.Vec3
+}
+
+// at returns the support function of s moved to time t.
+func (m *Motion) at(s Supporter, t float32) movingSupporter {
+	ms := movingSupporter{s: s, center: m.Center, rotation: glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}}
+	ms.offset = m.Linear.Mul(t)
+	if angle := m.Angular.Len(); angle > 0 {
+		axis := m.Angular.Mul(1 / angle)
+		q := glm.QuatRotate(angle*t, &axis)
+		ms.rotation = q.Mat3()
+	}
+	return ms
+}
+
+// movingSupporter is the support function of a shape rotated around center
+// and then moved by offset.
+type movingSupporter struct {
+	s              Supporter
+	rotation       glm.Mat3
+	center, offset glm.Vec3
+}
+
+// Support returns the support point of the moved shape.
+func (m *movingSupporter) Support(direction *glm.Vec3) glm.Vec3 {
+	// rotate the direction back into the frame of the shape, and the support
+	// point out of it.
+	d := m.rotation.Mul3x1Transpose(direction)
+	p := m.s.Support(&d)
+	p.SubWith(&m.center)
+	p = m.rotation.Mul3x1(&p)
+	p.AddWith(&m.center)
+	p.AddWith(&m.offset)
+	return p
+}
+
+// TimeOfImpact returns the time of the first contact between 2 convex shapes
+// moving with these motions, and the contact normal pointing from s0 to s1. It
+// returns false if they don't touch before t = 1, or if they are still apart
+// after toiMaxIterations advancements. If they start overlapping t is 0.
+//
+// The shapes are advanced by conservative advancement, they never get closer
+// than what the distance between them allows, so fast and thin shapes don't
+// tunnel through each other. [Mirtich96]
+func TimeOfImpact(s0, s1 Shape, m0, m1 *Motion) (t float32, normal glm.Vec3, hit bool) {
+	// Like DistanceShapeShape, spheres and capsules are moved as their cores
+	// and their radius is removed from the distance.
+	c0, r0 := shapeCore(s0)
+	c1, r1 := shapeCore(s1)
+	sup0, sup1 := ShapeSupporter(c0), ShapeSupporter(c1)
+
+	// How fast the shapes can get closer along any direction is bound by
+	// their relative velocity and how fast their points turn around their
+	// centers.
+	v := m0.Linear.Sub(&m1.Linear)
+	w := m0.Angular.Len()*boundingRadius(s0, &m0.Center) + m1.Angular.Len()*boundingRadius(s1, &m1.Center)
+
+	for i := 0; i < toiMaxIterations; i++ {
+		a, b := m0.at(sup0, t), m1.at(sup1, t)
+		l, p0, p1 := GJKDistance(&a, &b)
+		if l == 0 {
+			// The cores overlap, only the full shapes have a normal. Shapes
+			// that barely touch have no depth, keep the last normal.
+			a, b = m0.at(ShapeSupporter(s0), t), m1.at(ShapeSupporter(s1), t)
+			if n, _, ok := EPA(&a, &b); ok {
+				normal = n
+			}
+			return t, normal, true
+		}
+		normal = p1.Sub(&p0)
+		normal.MulWith(1 / l)
+		dist := l - r0 - r1
+		if dist <= toiTolerance {
+			return t, normal, true
+		}
+		speed := v.Dot(&normal) + w
+		if speed <= 0 {
+			return 0, glm.Vec3{}, false
+		}
+		// aim for half the tolerance so the shapes stop a bit before touching.
+		t += (dist - toiTolerance/2) / speed
+		if t > 1 {
+			return 0, glm.Vec3{}, false
+		}
+	}
+	// the shapes are still apart at t and might never touch, reporting a
+	// contact would stop them short of where they could go.
+	return 0, glm.Vec3{}, false
+}
+
+// boundingRadius returns the distance from center to the point of the shape
+// farthest from it.
+func boundingRadius(s Shape, center *glm.Vec3) float32 {
+	switch s := s.(type) {
+	case *AABB:
+		d := s.Center.Sub(center)
+		return d.Len() + s.HalfExtend.Len()
+	case *Sphere:
+		d := s.Center.Sub(center)
+		return d.Len() + s.Radius
+	case *OBB:
+		d := s.Center.Sub(center)
+		return d.Len() + s.HalfExtend.Len()
+	case *Capsule:
+		a, b := s.A.Sub(center), s.B.Sub(center)
+		return math.Max(a.Len(), b.Len()) + s.Radius
+	case *Convexhull:
+		var r float32
+		for i := range s.Vertices {
+			d := s.Vertices[i].Sub(center)
+			r = math.Max(r, d.Len())
+		}
+		return r
+	}
+	panic("geo: unknown shape type")
+}
@@ -0,0 +1,93 @@
+package geo
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"testing"
+)
+
+func TestTimeOfImpact(t *testing.T) {
+	ident := glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}
+	// the rod touches the sphere when it's 0.3 away from its center.
+	rodAngle := math.Acos(0.2)
+	tests := []struct {
+		s0, s1 Shape
+		m0, m1 Motion
+		t      float32
+		normal glm.Vec3
+		hit    bool
+	}{
+		{ // 0
+			&Sphere{Center: glm.Vec3{}, Radius: 1},
+			&AABB{Center: glm.Vec3{X: 5}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			Motion{Linear: glm.Vec3{X: 10}}, Motion{},
+			0.3, glm.Vec3{X: 1}, true,
+		},
+		{ // 1 a thin wall a discrete test would miss.
+			&Sphere{Center: glm.Vec3{X: -5}, Radius: 0.1},
+			&OBB{Center: glm.Vec3{}, Orientation: ident, HalfExtend: glm.Vec3{X: 0.01, Y: 1, Z: 1}},
+			Motion{Linear: glm.Vec3{X: 10}, Center: glm.Vec3{X: -5}}, Motion{},
+			0.489, glm.Vec3{X: 1}, true,
+		},
+		{ // 2 a spinning rod hitting a sphere.
+			&OBB{Center: glm.Vec3{}, Orientation: ident, HalfExtend: glm.Vec3{X: 2, Y: 0.05, Z: 0.05}},
+			&Sphere{Center: glm.Vec3{Y: 1.5}, Radius: 0.25},
+			Motion{Angular: glm.Vec3{Z: math.Pi / 2}}, Motion{},
+			rodAngle / (math.Pi / 2), glm.Vec3{X: -math.Sin(rodAngle), Y: 0.2}, true,
+		},
+		{ // 3
+			&Sphere{Center: glm.Vec3{}, Radius: 1},
+			&AABB{Center: glm.Vec3{X: 5}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			Motion{Linear: glm.Vec3{X: -10}}, Motion{},
+			0, glm.Vec3{}, false,
+		},
+		{ // 4
+			&Sphere{Center: glm.Vec3{}, Radius: 1},
+			&AABB{Center: glm.Vec3{X: 5}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			Motion{Linear: glm.Vec3{X: 2}}, Motion{},
+			0, glm.Vec3{}, false,
+		},
+		{ // 5
+			&Sphere{Center: glm.Vec3{}, Radius: 1},
+			&AABB{Center: glm.Vec3{X: 1.5}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}},
+			Motion{Linear: glm.Vec3{X: 2}}, Motion{},
+			0, glm.Vec3{X: 1}, true,
+		},
+		{ // 6
+			cubeHull(glm.Vec3{}, 1),
+			&Capsule{A: glm.Vec3{X: -1, Y: 5}, B: glm.Vec3{X: 1, Y: 5}, Radius: 0.5},
+			Motion{Linear: glm.Vec3{Y: 2}}, Motion{Linear: glm.Vec3{Y: -5}, Center: glm.Vec3{Y: 5}},
+			3.5 / 7, glm.Vec3{Y: 1}, true,
+		},
+		{ // 7 a spinning rod barely missing a sphere runs out of iterations.
+			&OBB{Center: glm.Vec3{}, Orientation: ident, HalfExtend: glm.Vec3{X: 2, Y: 0.05, Z: 0.05}},
+			&Sphere{Center: glm.Vec3{Y: 2.252}, Radius: 0.25},
+			Motion{Angular: glm.Vec3{Z: math.Pi}}, Motion{},
+			0, glm.Vec3{}, false,
+		},
+	}
+	for i, test := range tests {
+		toi, normal, hit := TimeOfImpact(test.s0, test.s1, &test.m0, &test.m1)
+		if hit != test.hit {
+			t.Errorf("[%d] hit = %t, want %t", i, hit, test.hit)
+		}
+		if !test.hit {
+			continue
+		}
+		if math.Abs(toi-test.t) > 1e-3 || toi > test.t {
+			t.Errorf("[%d] t = %f, want %f", i, toi, test.t)
+		}
+		if !vec3Near(normal, test.normal, 1e-3) {
+			t.Errorf("[%d] normal = %v, want %v", i, normal, test.normal)
+		}
+	}
+}
+
+func BenchmarkTimeOfImpact(b *testing.B) {
+	rod := OBB{Center: glm.Vec3{}, Orientation: glm.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}, HalfExtend: glm.Vec3{X: 2, Y: 0.05, Z: 0.05}}
+	sphere := Sphere{Center: glm.Vec3{Y: 1.5}, Radius: 0.25}
+	m0, m1 := Motion{Angular: glm.Vec3{Z: math.Pi / 2}}, Motion{}
+	for n := 0; n < b.N; n++ {
+		TimeOfImpact(&rod, &sphere, &m0, &m1)
+	}
+}
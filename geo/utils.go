@@ -837,11 +837,12 @@ func IntersectRayAABB(p, d *glm.Vec3, a *AABB) (t float32, q glm.Vec3, intersect
 			if t1 > t {
 				t = t1
 			}
-			if t2 > tmax {
+			if t2 < tmax {
 				tmax = t2
 			}
-			// Exit with no collision as soon as slab intersection becomes empty
-			if !(t1 <= tmax) {
+			// Exit with no collision as soon as slab intersection becomes empty,
+			// or is NaN.
+			if !(t <= tmax) || !(t1 <= t2) {
 				return
 			}
 		}
@@ -1107,34 +1108,7 @@ func IsPointInTriangle(p, a, b, c *glm.Vec3) bool {
 
 // TestConvexhullSphere returns true if the convex hull intersects the sphere.
 func TestConvexhullSphere(hull *Convexhull, sphere *Sphere) bool {
-	// from hull center of mass to sphere center of mass
-	a := sphere.Center.Sub(&hull.Center)
-
-	// check if the center of mass of the hull is inside the sphere
-	if a.Len2() < sphere.Radius*sphere.Radius {
-		return true
-	}
-
-	// find triangle closest to sphere
-	_, triangle := hull.Support(&a, nil)
-
-	// sphere farthest point in direction of hull center of mass (may go past
-	// hull)
-	a.Normalize()
-	a.MulWith(sphere.Radius)
-	s := sphere.Center.Sub(&a)
-
-	// if THEY ARE on opposite sides of plane then its outside
-	if PointsOnOppositeSideOfPlane(&s, &hull.Center, triangle.Vertices[0], triangle.Vertices[1], triangle.Vertices[2]) {
-		return false
-	}
-
-	for n := 0; n < 3; n++ {
-		if PointsOnOppositeSideOfPlane(&s, &hull.Center, triangle.Adjacent[n].Vertices[0], triangle.Adjacent[n].Vertices[1], triangle.Adjacent[n].Vertices[2]) {
-			return false
-		}
-	}
-	return true
+	return TestGJK(hull.Supporter(), sphere)
 }
 
 // TestAABBOBB returns true if the aabb and obb intersects.
@@ -1240,20 +1214,7 @@ func TestAABBHalfspace(b *AABB, p *Plane) bool {
 
 // TestAABBConvexhull returns true if the aabb and the convex hull intersects.
 func TestAABBConvexhull(aabb *AABB, hull *Convexhull) bool {
-	d := aabb.Center.Sub(&hull.Center)
-	_, t := hull.Support(&d, nil)
-	p := PlaneFromPoints(t.Vertices[0], t.Vertices[1], t.Vertices[2])
-	if !TestAABBHalfspace(aabb, &p) {
-		return false
-	}
-
-	for n := 0; n < 3; n++ {
-		p = PlaneFromPoints(t.Adjacent[n].Vertices[0], t.Adjacent[n].Vertices[1], t.Adjacent[n].Vertices[2])
-		if !TestAABBHalfspace(aabb, &p) {
-			return false
-		}
-	}
-	return true
+	return TestGJK(aabb, hull.Supporter())
 }
 
 // TestCapsuleOBB returns true if the capsule and the obb intersects.
@@ -1278,6 +1239,13 @@ func ClosestPointPlanePoint(plane *Plane, point *glm.Vec3) (float32, glm.Vec3) {
 	return dist, point.Sub(&v)
 }
 
-/*func TestCapsuleConvexhull(capsule *Capsule, hull *Convexhull) bool {
+// TestCapsuleConvexhull returns true if the capsule and the convex hull
+// intersects.
+func TestCapsuleConvexhull(capsule *Capsule, hull *Convexhull) bool {
+	return TestGJK(capsule, hull.Supporter())
+}
 
-}*/
+// TestConvexhullOBB returns true if the convex hull and the obb intersects.
+func TestConvexhullOBB(hull *Convexhull, obb *OBB) bool {
+	return TestGJK(hull.Supporter(), obb)
+}
@@ -1186,6 +1186,12 @@ func TestIntersectRayAABB(t *testing.T) {
 		{glm.Vec3{X: 0, Y: 0, Z: -5}, glm.Vec3{X: 0, Y: 0, Z: 1},
 			AABB{glm.Vec3{}, glm.Vec3{X: 0.5, Y: 0.5, Z: math.Inf(1)}},
 			0, glm.Vec3{X: 0, Y: 0, Z: -5}, true},
+		{glm.Vec3{X: -5, Y: 0, Z: 0}, glm.Vec3{X: 1, Y: 1, Z: 0},
+			AABB{glm.Vec3{}, glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+			0, glm.Vec3{}, false},
+		{glm.Vec3{X: -5, Y: -4.7, Z: 0}, glm.Vec3{X: 1, Y: 1, Z: 0},
+			AABB{glm.Vec3{}, glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+			4.5, glm.Vec3{X: -0.5, Y: -0.2, Z: 0}, true},
 	}
 	for i, test := range tests {
 		v, q, intersect := IntersectRayAABB(&test.p, &test.d, &test.aabb)
@@ -1475,3 +1481,63 @@ func TestIsPointInTriangle(t *testing.T) {
 		}
 	}
 }
+
+// hullTestOffsets moves the shapes of the hull tests around. The hull tests
+// used to only look at the triangle of the support point towards the other
+// shape and its neighbours, which missed the shapes on the -X side of the
+// hull, see TestQuickhull_Adjacent for the neighbours they followed.
+var hullTestOffsets = []glm.Vec3{
+	{X: 0, Y: 0, Z: 0},
+	{X: 10, Y: 0, Z: 0},
+	{X: 10, Y: 20, Z: 30},
+}
+
+func TestTestConvexhullSphere(t *testing.T) {
+	tests := []struct {
+		sphere    Sphere
+		intersect bool
+	}{
+		{Sphere{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, Radius: 0.5}, true},     // 0 inside.
+		{Sphere{Center: glm.Vec3{X: 1.5, Y: 0, Z: 0}, Radius: 1}, true},     // 1
+		{Sphere{Center: glm.Vec3{X: 10, Y: 0, Z: 0}, Radius: 1}, false},     // 2
+		{Sphere{Center: glm.Vec3{X: -10, Y: 0, Z: 0}, Radius: 1}, false},    // 3
+		{Sphere{Center: glm.Vec3{X: 0, Y: -10, Z: 3}, Radius: 2}, false},    // 4
+		{Sphere{Center: glm.Vec3{X: 1.6, Y: 1.6, Z: 0}, Radius: 1}, true},   // 5 on an edge.
+		{Sphere{Center: glm.Vec3{X: 1.8, Y: 1.8, Z: 0}, Radius: 1}, false},  // 6 next to an edge.
+		{Sphere{Center: glm.Vec3{X: 1.5, Y: 1.5, Z: 1.5}, Radius: 1}, true}, // 7 on a corner.
+	}
+	for i, test := range tests {
+		for _, offset := range hullTestOffsets {
+			hull := cubeHull(offset, 1)
+			sphere := test.sphere
+			sphere.Center.AddWith(&offset)
+			if got := TestConvexhullSphere(hull, &sphere); got != test.intersect {
+				t.Errorf("[%d] TestConvexhullSphere(%v) = %t, want %t", i, offset, got, test.intersect)
+			}
+		}
+	}
+}
+
+func TestTestAABBConvexhull(t *testing.T) {
+	tests := []struct {
+		aabb      AABB
+		intersect bool
+	}{
+		{AABB{Center: glm.Vec3{X: 0, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 3, Y: 3, Z: 3}}, true},      // 0 around.
+		{AABB{Center: glm.Vec3{X: 1.5, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, true},    // 1
+		{AABB{Center: glm.Vec3{X: 10, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, false},    // 2
+		{AABB{Center: glm.Vec3{X: -10, Y: 0, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, false},   // 3
+		{AABB{Center: glm.Vec3{X: 0, Y: 0, Z: -10}, HalfExtend: glm.Vec3{X: 5, Y: 5, Z: 1}}, false},   // 4
+		{AABB{Center: glm.Vec3{X: 2.5, Y: 2.5, Z: 0}, HalfExtend: glm.Vec3{X: 1, Y: 1, Z: 1}}, false}, // 5 next to an edge.
+	}
+	for i, test := range tests {
+		for _, offset := range hullTestOffsets {
+			hull := cubeHull(offset, 1)
+			aabb := test.aabb
+			aabb.Center.AddWith(&offset)
+			if got := TestAABBConvexhull(&aabb, hull); got != test.intersect {
+				t.Errorf("[%d] TestAABBConvexhull(%v) = %t, want %t", i, offset, got, test.intersect)
+			}
+		}
+	}
+}
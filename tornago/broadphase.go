@@ -19,3 +19,13 @@ type Broadphase interface {
 	// collision between them). returns how many contacts we're actually generated.
 	GeneratePotentialContacts(contacts []potentialContact) int
 }
+
+// MovableBroadphase is a Broadphase that can update the volume of its bodies in
+// place. The world moves the bodies of a MovableBroadphase every step instead
+// of rebuilding a naive broadphase.
+type MovableBroadphase interface {
+	Broadphase
+
+	// Move updates the bounding volume of a body that was inserted.
+	Move(b *RigidBody, volume *BoundingSphere)
+}
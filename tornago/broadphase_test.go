@@ -60,6 +60,8 @@ func TestBroadphases(t *testing.T) {
 		//{&bvh, "BVH"},
 		{&NaiveBroadphase{}, "Naive"},
 		{&SAP{}, "Non-Persistent sweep and prune"},
+		{&SAP3{}, "Persistent sweep and prune"},
+		{NewOctree(&glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}, 0.5, 4), "Loose octree"},
 	}
 
 	// we hope this is enough
@@ -157,7 +159,7 @@ func BenchmarkBroadphaseBVH(b *testing.B) {
 			wg.Done()
 		}()
 
-		broadphase.GeneratePotentialContacts(contactchan)
+		broadphase.StreamPotentialContacts(contactchan)
 		close(contactchan)
 		wg.Wait()
 	}
@@ -284,6 +286,88 @@ func BenchmarkBroadphase_Fake_NonPersistentSAP(b *testing.B) {
 	}
 }
 
+func BenchmarkBroadphaseOctree(b *testing.B) {
+	rand.Seed(9999)
+	const (
+		numObjects = benchmarkNumObjects
+		worldsize  = benchmarkWorldSize
+	)
+	type Object struct {
+		body   *RigidBody
+		volume *BoundingSphere
+	}
+	objects := make([]Object, 0, numObjects)
+
+	// just make up a bunch of objects.
+	for x := 0; x < cap(objects); x++ {
+		var b RigidBody
+		volume := BoundingSphere{
+			center: glm.Vec3{X: rand.Float32() * worldsize, Y: rand.Float32() * worldsize, Z: rand.Float32() * worldsize},
+			radius: rand.Float32(),
+		}
+		objects = append(objects, Object{
+			body:   &b,
+			volume: &volume,
+		})
+	}
+
+	contacts := make([]potentialContact, len(objects)*100)
+	center := glm.Vec3{X: worldsize / 2, Y: worldsize / 2, Z: worldsize / 2}
+
+	for x := 0; x < b.N; x++ {
+		broadphase := NewOctree(&center, worldsize/2, 8)
+
+		for _, object := range objects {
+			broadphase.Insert(object.body, object.volume)
+		}
+
+		broadphase.GeneratePotentialContacts(contacts)
+	}
+}
+
+func BenchmarkBroadphase_Fake_Octree(b *testing.B) {
+	rand.Seed(9999)
+	const (
+		numObjects = benchmarkNumObjects
+		worldsize  = benchmarkWorldSize
+	)
+	type Object struct {
+		body   *RigidBody
+		volume *BoundingSphere
+	}
+	objects := make([]Object, 0, numObjects)
+
+	// just make up a bunch of objects, some of them very large.
+	for x := 0; x < cap(objects); x++ {
+		var b RigidBody
+		radius := rand.Float32()
+		if x%100 == 0 {
+			radius *= worldsize / 10
+		}
+		volume := BoundingSphere{
+			center: glm.Vec3{X: rand.Float32() * worldsize, Y: rand.Float32() * worldsize, Z: rand.Float32() * worldsize},
+			radius: radius,
+		}
+		objects = append(objects, Object{
+			body:   &b,
+			volume: &volume,
+		})
+	}
+
+	center := glm.Vec3{X: worldsize / 2, Y: worldsize / 2, Z: worldsize / 2}
+	broadphase := NewOctree(&center, worldsize/2, 8)
+
+	for _, object := range objects {
+		broadphase.Insert(object.body, object.volume)
+	}
+
+	contacts := make([]potentialContact, len(objects)*100)
+
+	for x := 0; x < b.N; x++ {
+		broadphase.GeneratePotentialContacts(contacts)
+	}
+}
+
 //use this as template for testing new broadphases.
 func TestSAP(t *testing.T) {
 	rand.Seed(9999)
@@ -366,7 +450,7 @@ func TestSAP(t *testing.T) {
 		wg.Done()
 	}()
 
-	broadphase.GeneratePotentialContacts(contactchan)
+	broadphase.StreamPotentialContacts(contactchan)
 	close(contactchan)
 	wg.Wait()
 
@@ -414,7 +498,7 @@ func TestBroadphaseBug(t *testing.T) {
 	d := ContactResolver{}
 
 	contacts := make([]Contact, gen)
-	gen = resolvePotentialContacts(pcontacts[:gen], contacts)
+	gen = resolvePotentialContacts(pcontacts[:gen], contacts, nil, nil)
 	t.Log(gen)
 	t.Logf("contacts %+v", contacts[:gen])
 
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	cfg := config{steps: 4, dt: 1.0 / 60, broadphase: "naive", workers: 1}
+
+	var out bytes.Buffer
+	if err := run(&out, &cfg, "rope, dominoes", "json"); err != nil {
+		t.Fatalf("run = %v", err)
+	}
+	dec := json.NewDecoder(&out)
+	for _, want := range []string{"rope", "dominoes"} {
+		var r result
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("Decode = %v", err)
+		}
+		if r.Scenario != want {
+			t.Errorf("scenario = %q, want %q", r.Scenario, want)
+		}
+	}
+
+	out.Reset()
+	if err := run(&out, &cfg, "all", "csv"); err != nil {
+		t.Fatalf("run = %v", err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll = %v", err)
+	}
+	if len(records) != len(scenarios)+1 {
+		t.Errorf("len(records) = %d, want a header and %d results", len(records), len(scenarios))
+	}
+
+	errors := []struct {
+		names, format string
+		cfg           config
+	}{
+		{"pyramid", "xml", cfg},
+		{"jenga", "json", cfg},
+		{"pyramid", "json", config{steps: 0, dt: 1.0 / 60, broadphase: "naive"}},
+		{"pyramid", "json", config{steps: 1, dt: 1.0 / 60, broadphase: "bvh"}},
+	}
+	for _, e := range errors {
+		if err := run(&out, &e.cfg, e.names, e.format); err == nil {
+			t.Errorf("run(%q, %q, %+v) = nil, want an error", e.names, e.format, e.cfg)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	gomath "math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// result is what a run of a scenario measured. Times are in microseconds,
+// distances in meters and speeds in meters per second. The energy drift and
+// gain are relative to the energy at the start.
+type result struct {
+	Scenario   string  `json:"scenario"`
+	Broadphase string  `json:"broadphase"`
+	Workers    int     `json:"workers"`
+	Bodies     int     `json:"bodies"`
+	Steps      int     `json:"steps"`
+	Dt         float64 `json:"dt"`
+
+	StepMean float64 `json:"stepMeanUs"`
+	StepP95  float64 `json:"stepP95Us"`
+	StepMax  float64 `json:"stepMaxUs"`
+
+	EnergyStart   float64 `json:"energyStart"`
+	EnergyEnd     float64 `json:"energyEnd"`
+	EnergyDrift   float64 `json:"energyDrift"`
+	MaxEnergyGain float64 `json:"maxEnergyGain"`
+
+	MeanContacts    float64 `json:"meanContacts"`
+	MeanPenetration float64 `json:"meanPenetration"`
+	MaxPenetration  float64 `json:"maxPenetration"`
+
+	// The speed of the bodies during the last quarter of the run.
+	JitterRMS float64 `json:"jitterRms"`
+	JitterMax float64 `json:"jitterMax"`
+
+	// Whether a body reached an infinite or NaN position or velocity, the run
+	// stops there.
+	Exploded bool `json:"exploded"`
+}
+
+// csvHeader is the first line of the CSV output, in the order of csvRecord.
+var csvHeader = []string{
+	"scenario", "broadphase", "workers", "bodies", "steps", "dt",
+	"stepMeanUs", "stepP95Us", "stepMaxUs",
+	"energyStart", "energyEnd", "energyDrift", "maxEnergyGain",
+	"meanContacts", "meanPenetration", "maxPenetration",
+	"jitterRms", "jitterMax", "exploded",
+}
+
+// csvRecord returns the fields of this result in the order of csvHeader.
+func (r *result) csvRecord() []string {
+	f := func(x float64) string { return strconv.FormatFloat(x, 'g', -1, 64) }
+	return []string{
+		r.Scenario, r.Broadphase, strconv.Itoa(r.Workers), strconv.Itoa(r.Bodies), strconv.Itoa(r.Steps), f(r.Dt),
+		f(r.StepMean), f(r.StepP95), f(r.StepMax),
+		f(r.EnergyStart), f(r.EnergyEnd), f(r.EnergyDrift), f(r.MaxEnergyGain),
+		f(r.MeanContacts), f(r.MeanPenetration), f(r.MaxPenetration),
+		f(r.JitterRMS), f(r.JitterMax), strconv.FormatBool(r.Exploded),
+	}
+}
+
+// runScenario builds the scenario and steps it cfg.steps times.
+func runScenario(sc scenario, cfg *config) (*result, error) {
+	s, err := newScene(cfg)
+	if err != nil {
+		return nil, err
+	}
+	sc.build(s)
+	defer s.world.Close()
+
+	r := result{
+		Scenario:   sc.name,
+		Broadphase: cfg.broadphase,
+		Workers:    s.world.NarrowphaseWorkers(),
+		Bodies:     len(s.bodies),
+		Dt:         cfg.dt,
+	}
+	r.EnergyStart = s.energy()
+	r.EnergyEnd = r.EnergyStart
+
+	var (
+		times          = make([]time.Duration, 0, cfg.steps)
+		rest           = cfg.steps - cfg.steps/4
+		maxEnergy      = r.EnergyStart
+		contacts       int
+		penetration    float64
+		speeds, speed2 float64
+	)
+	for i := 0; i < cfg.steps; i++ {
+		start := time.Now()
+		s.world.Step(float32(cfg.dt))
+		times = append(times, time.Since(start))
+
+		stats := s.world.Stats().Last
+		contacts += stats.Contacts
+		penetration += float64(stats.MaxPenetration)
+		if p := float64(stats.MaxPenetration); p > r.MaxPenetration {
+			r.MaxPenetration = p
+		}
+
+		e := s.energy()
+		if !s.finite() || gomath.IsNaN(e) || gomath.IsInf(e, 0) {
+			r.Exploded = true
+			break
+		}
+		r.EnergyEnd = e
+		if e > maxEnergy {
+			maxEnergy = e
+		}
+
+		if i >= rest {
+			for _, b := range s.bodies {
+				v := b.Velocity()
+				v2 := dot64(&v, &v)
+				speed2 += v2
+				speeds++
+				if v := gomath.Sqrt(v2); v > r.JitterMax {
+					r.JitterMax = v
+				}
+			}
+		}
+	}
+
+	r.Steps = len(times)
+	if r.Steps > 0 {
+		n := float64(r.Steps)
+		r.MeanContacts = float64(contacts) / n
+		r.MeanPenetration = penetration / n
+		r.StepMean, r.StepP95, r.StepMax = stepTimes(times)
+	}
+	if speeds > 0 {
+		r.JitterRMS = gomath.Sqrt(speed2 / speeds)
+	}
+	scale := gomath.Abs(r.EnergyStart)
+	if scale < 1e-9 {
+		scale = 1
+	}
+	r.EnergyDrift = (r.EnergyEnd - r.EnergyStart) / scale
+	r.MaxEnergyGain = (maxEnergy - r.EnergyStart) / scale
+	return &r, nil
+}
+
+// stepTimes returns the mean, 95th percentile and max of times, in
+// microseconds. times is sorted.
+func stepTimes(times []time.Duration) (mean, p95, max float64) {
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	var total time.Duration
+	for _, t := range times {
+		total += t
+	}
+	us := func(d time.Duration) float64 { return float64(d) / float64(time.Microsecond) }
+	mean = us(total) / float64(len(times))
+	p95 = us(times[(len(times)-1)*95/100])
+	max = us(times[len(times)-1])
+	return mean, p95, max
+}
+
+// energy returns the kinetic and potential energy of the dynamic bodies of the
+// scene. The potential energy comes from their constant acceleration, it's 0 at
+// the origin.
+func (s *scene) energy() float64 {
+	var e float64
+	for _, b := range s.bodies {
+		m := float64(b.Mass())
+		v, w, p, a := b.Velocity(), b.Rotation(), b.Position(), b.Acceleration()
+
+		// the rotation is in world space, the inertia tensor in body space.
+		q := b.Orientation()
+		qi := q.Conjugated()
+		lw := qi.Rotate(&w)
+		it := b.InertiaTensor()
+		iw := it.Mul3x1(&lw)
+
+		e += 0.5*m*dot64(&v, &v) + 0.5*dot64(&lw, &iw) - m*dot64(&a, &p)
+	}
+	return e
+}
+
+// dot64 returns the dot product of a and b computed with float64, the speeds
+// of exploding bodies overflow float32.
+func dot64(a, b *glm.Vec3) float64 {
+	return float64(a.X)*float64(b.X) + float64(a.Y)*float64(b.Y) + float64(a.Z)*float64(b.Z)
+}
+
+// finite returns whether every dynamic body of the scene has a finite position
+// and velocity.
+func (s *scene) finite() bool {
+	for _, b := range s.bodies {
+		p, v, w := b.Position(), b.Velocity(), b.Rotation()
+		if !isFinite(&p) || !isFinite(&v) || !isFinite(&w) {
+			return false
+		}
+	}
+	return true
+}
+
+func isFinite(v *glm.Vec3) bool {
+	for _, x := range [3]float32{v.X, v.Y, v.Z} {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return false
+		}
+	}
+	return true
+}
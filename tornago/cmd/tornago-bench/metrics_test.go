@@ -0,0 +1,77 @@
+package main
+
+import (
+	"github.com/luxengine/lux/glm"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestScene_Energy(t *testing.T) {
+	s, err := newScene(&config{broadphase: "naive"})
+	if err != nil {
+		t.Fatalf("newScene = %v", err)
+	}
+	b := s.addSphere(&glm.Vec3{X: 0, Y: 10, Z: 0}, 1, 2)
+	b.SetLinearDamping(1)
+	b.SetVelocity3f(3, 0, 0)
+	b.SetRotation3f(0, 1, 0)
+
+	// 1/2 m v^2 + 1/2 I w^2 + m g h, the inertia of a solid sphere is 2/5 m r^2.
+	want := 0.5*2*9 + 0.5*0.4*2 + 2*10*10.0
+	if got := s.energy(); math.Abs(got-want) > 1e-3 {
+		t.Errorf("energy() = %v, want %v", got, want)
+	}
+
+	// a falling body keeps its energy.
+	for i := 0; i < 30; i++ {
+		s.world.Step(1.0 / 60)
+	}
+	if got := s.energy(); math.Abs(got-want)/want > 0.01 {
+		t.Errorf("energy() after falling = %v, want %v", got, want)
+	}
+	if !s.finite() {
+		t.Error("finite() = false, want true")
+	}
+	b.SetVelocity3f(float32(math.NaN()), 0, 0)
+	if s.finite() {
+		t.Error("finite() = true with a NaN velocity, want false")
+	}
+}
+
+func TestStepTimes(t *testing.T) {
+	var times []time.Duration
+	for i := 100; i > 0; i-- {
+		times = append(times, time.Duration(i)*time.Microsecond)
+	}
+	mean, p95, max := stepTimes(times)
+	if mean != 50.5 || p95 != 95 || max != 100 {
+		t.Errorf("stepTimes = %v, %v, %v, want 50.5, 95, 100", mean, p95, max)
+	}
+}
+
+func TestRunScenario(t *testing.T) {
+	sc, _ := findScenario("dominoes")
+	r, err := runScenario(sc, &config{steps: 120, dt: 1.0 / 60, broadphase: "sap3", workers: 2})
+	if err != nil {
+		t.Fatalf("runScenario = %v", err)
+	}
+	if r.Steps != 120 || r.Bodies != 30 || r.Workers != 2 {
+		t.Errorf("steps, bodies, workers = %d, %d, %d, want 120, 30, 2", r.Steps, r.Bodies, r.Workers)
+	}
+	if r.Exploded {
+		t.Error("the dominoes exploded")
+	}
+	if r.StepMean <= 0 || r.StepMax < r.StepMean {
+		t.Errorf("step mean, max = %v, %v", r.StepMean, r.StepMax)
+	}
+	if r.MeanContacts == 0 || r.MaxPenetration <= 0 {
+		t.Errorf("contacts, penetration = %v, %v, want the dominoes to touch the floor", r.MeanContacts, r.MaxPenetration)
+	}
+	if r.JitterMax == 0 {
+		t.Error("jitterMax = 0, want the first domino to be falling")
+	}
+	if got := len(r.csvRecord()); got != len(csvHeader) {
+		t.Errorf("len(csvRecord()) = %d, want %d", got, len(csvHeader))
+	}
+}
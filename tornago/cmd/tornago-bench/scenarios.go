@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/tornago"
+	"math/rand"
+)
+
+const (
+	// the acceleration of every dynamic body on the y axis.
+	gravity = -10
+)
+
+// config holds the settings shared by every scenario of a run.
+type config struct {
+	steps      int
+	dt         float64
+	broadphase string
+	workers    int
+}
+
+// scenario is a canonical scene. build adds its bodies and constraints to a
+// scene that already has a floor, whose top is at y = 0.
+type scenario struct {
+	name  string
+	build func(s *scene)
+}
+
+// scenarios is every scenario, in the order they are run.
+var scenarios = []scenario{
+	{"pyramid", buildPyramid},
+	{"dominoes", buildDominoes},
+	{"ballpit", buildBallPit},
+	{"rope", buildRope},
+}
+
+// scenarioNames returns the names of every scenario.
+func scenarioNames() []string {
+	names := make([]string, len(scenarios))
+	for i, s := range scenarios {
+		names[i] = s.name
+	}
+	return names
+}
+
+// findScenario returns the scenario with the given name.
+func findScenario(name string) (scenario, bool) {
+	for _, s := range scenarios {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return scenario{}, false
+}
+
+// scene is a world being benchmarked and the dynamic bodies it holds.
+type scene struct {
+	world  *tornago.World
+	bodies []*tornago.RigidBody
+}
+
+// newBroadphase returns the broadphase with the given name.
+func newBroadphase(name string) (tornago.Broadphase, error) {
+	switch name {
+	case "naive":
+		return &tornago.NaiveBroadphase{}, nil
+	case "sap":
+		// World.Step replaces the broadphases that can't move bodies with a
+		// naive one, the results would be those of naive.
+		return nil, fmt.Errorf("broadphase %q can't move bodies, World.Step would use naive instead", name)
+	case "sap3":
+		return &tornago.SAP3{}, nil
+	case "octree":
+		return tornago.NewOctree(&glm.Vec3{}, 64, 8), nil
+	}
+	return nil, fmt.Errorf("unknown broadphase %q", name)
+}
+
+// newScene returns a scene with a profiling world and a static floor.
+func newScene(cfg *config) (*scene, error) {
+	broadphase, err := newBroadphase(cfg.broadphase)
+	if err != nil {
+		return nil, err
+	}
+	w := tornago.NewWorld(broadphase, tornago.ContactResolver{})
+	w.SetNarrowphaseWorkers(cfg.workers)
+	w.SetProfiling(true)
+
+	s := &scene{world: w}
+	s.addBox(&glm.Vec3{X: 0, Y: -1, Z: 0}, &glm.Vec3{X: 50, Y: 1, Z: 50}, 0)
+	return s, nil
+}
+
+// add adds b to the scene, bodies with a mass of 0 are static, the others
+// fall.
+func (s *scene) add(b *tornago.RigidBody, position *glm.Vec3, mass float32, shape tornago.CollisionShape) {
+	b.SetMass(mass)
+	b.SetCollisionShape(shape)
+	b.SetPositionVec3(position)
+	if mass != 0 {
+		b.SetAcceleration3f(0, gravity, 0)
+		s.bodies = append(s.bodies, b)
+	}
+	s.world.AddRigidBody(b)
+}
+
+// addBox adds a box to the scene, a mass of 0 makes it static.
+func (s *scene) addBox(position, halfSize *glm.Vec3, mass float32) *tornago.RigidBody {
+	b := tornago.NewRigidBody()
+	s.add(b, position, mass, tornago.NewCollisionBox(*halfSize))
+	return b
+}
+
+// addSphere adds a sphere to the scene, a mass of 0 makes it static.
+func (s *scene) addSphere(position *glm.Vec3, radius, mass float32) *tornago.RigidBody {
+	b := tornago.NewRigidBody()
+	s.add(b, position, mass, tornago.NewCollisionSphere(radius))
+	return b
+}
+
+// buildPyramid stacks unit boxes in a pyramid with a base of 10 boxes. The
+// boxes start resting, any motion is solver error.
+func buildPyramid(s *scene) {
+	const (
+		base    = 10
+		spacing = 1.02
+	)
+	halfSize := glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}
+	for row := 0; row < base; row++ {
+		n := base - row
+		for col := 0; col < n; col++ {
+			x := (float32(col) - float32(n-1)/2) * spacing
+			s.addBox(&glm.Vec3{X: x, Y: 0.5 + float32(row), Z: 0}, &halfSize, 1)
+		}
+	}
+}
+
+// buildDominoes stands 30 dominoes in a line and tips the first one over.
+func buildDominoes(s *scene) {
+	const (
+		count   = 30
+		spacing = 1.2
+	)
+	halfSize := glm.Vec3{X: 0.1, Y: 1, Z: 0.5}
+	for i := 0; i < count; i++ {
+		d := s.addBox(&glm.Vec3{X: float32(i) * spacing, Y: halfSize.Y, Z: 0}, &halfSize, 1)
+		if i == 0 {
+			// the top of the first domino moves towards the others.
+			d.SetRotation3f(0, 0, -3)
+		}
+	}
+}
+
+// buildBallPit drops 400 balls in a pit surrounded by 4 static walls.
+func buildBallPit(s *scene) {
+	const (
+		side    = 10
+		layers  = 4
+		radius  = 0.25
+		spacing = 0.55
+		wall    = 3.25
+	)
+	s.addBox(&glm.Vec3{X: -wall, Y: 1.5, Z: 0}, &glm.Vec3{X: 0.25, Y: 1.5, Z: 3.5}, 0)
+	s.addBox(&glm.Vec3{X: wall, Y: 1.5, Z: 0}, &glm.Vec3{X: 0.25, Y: 1.5, Z: 3.5}, 0)
+	s.addBox(&glm.Vec3{X: 0, Y: 1.5, Z: -wall}, &glm.Vec3{X: 3.5, Y: 1.5, Z: 0.25}, 0)
+	s.addBox(&glm.Vec3{X: 0, Y: 1.5, Z: wall}, &glm.Vec3{X: 3.5, Y: 1.5, Z: 0.25}, 0)
+
+	// the same seed on every run, the balls must start at the same place.
+	r := rand.New(rand.NewSource(1))
+	jitter := func() float32 { return (r.Float32()*2 - 1) * 0.05 }
+	offset := float32(side-1) / 2 * spacing
+	for y := 0; y < layers; y++ {
+		for z := 0; z < side; z++ {
+			for x := 0; x < side; x++ {
+				s.addSphere(&glm.Vec3{
+					X: float32(x)*spacing - offset + jitter(),
+					Y: 2 + float32(y)*spacing + jitter(),
+					Z: float32(z)*spacing - offset + jitter(),
+				}, radius, 1)
+			}
+		}
+	}
+}
+
+// buildRope hangs a horizontal rope of 20 links joined by rods from a point of
+// the world and lets it swing.
+func buildRope(s *scene) {
+	const (
+		links  = 20
+		length = 0.5
+		height = 12
+	)
+	anchor := glm.Vec3{X: 0, Y: height, Z: 0}
+	var prev *tornago.RigidBody
+	for i := 1; i <= links; i++ {
+		link := s.addSphere(&glm.Vec3{X: float32(i) * length, Y: height, Z: 0}, 0.1, 0.5)
+		if prev == nil {
+			s.world.AddConstraint(tornago.NewRodConstraintToWorld(length, link, &glm.Vec3{}, &anchor))
+		} else {
+			s.world.AddConstraint(tornago.NewRodConstraintToBody(length, prev, link, &glm.Vec3{}, &glm.Vec3{}))
+		}
+		prev = link
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestScenarios(t *testing.T) {
+	tests := map[string]int{
+		"pyramid":  55,
+		"dominoes": 30,
+		"ballpit":  400,
+		"rope":     20,
+	}
+	if len(scenarios) != len(tests) {
+		t.Errorf("len(scenarios) = %d, want %d", len(scenarios), len(tests))
+	}
+	cfg := config{steps: 1, dt: 1.0 / 60, broadphase: "naive"}
+	for name, bodies := range tests {
+		sc, ok := findScenario(name)
+		if !ok {
+			t.Errorf("findScenario(%q) = false, want true", name)
+			continue
+		}
+		s, err := newScene(&cfg)
+		if err != nil {
+			t.Fatalf("newScene = %v", err)
+		}
+		sc.build(s)
+		if len(s.bodies) != bodies {
+			t.Errorf("%s has %d dynamic bodies, want %d", name, len(s.bodies), bodies)
+		}
+	}
+	if _, ok := findScenario("jenga"); ok {
+		t.Error(`findScenario("jenga") = true, want false`)
+	}
+}
+
+func TestNewBroadphase(t *testing.T) {
+	for _, name := range []string{"naive", "sap3", "octree"} {
+		if _, err := newBroadphase(name); err != nil {
+			t.Errorf("newBroadphase(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"bvh", "sap"} {
+		if _, err := newBroadphase(name); err == nil {
+			t.Errorf("newBroadphase(%q) = nil, want an error", name)
+		}
+	}
+}
@@ -14,6 +14,22 @@ type CollisionShape interface {
 	RayTest(Ray, RayResult)
 }
 
+// boundingVolumeInner is implemented by collision shapes that can compute
+// their bounding volume without allocating. The world uses it when available
+// to keep World.Step allocation free.
+type boundingVolumeInner interface {
+	BoundingVolumeIn(dst *BoundingSphere)
+}
+
+// boundingVolumeIn stores the bounding volume of shape in dst.
+func boundingVolumeIn(shape CollisionShape, dst *BoundingSphere) {
+	if s, ok := shape.(boundingVolumeInner); ok {
+		s.BoundingVolumeIn(dst)
+		return
+	}
+	*dst = *shape.GetBoundingVolume()
+}
+
 // CollisionSphere represents a sphere.
 type CollisionSphere struct {
 	body   *RigidBody
@@ -27,9 +43,10 @@ func NewCollisionSphere(radius float32) *CollisionSphere {
 	}
 }
 
-// Position returns the position of the sphere
+// Position returns the position of the sphere, the origin of the body's
+// shape which isn't its position when the center of mass is offset.
 func (s *CollisionSphere) Position() glm.Vec3 {
-	return s.body.Position()
+	return s.body.OriginPosition()
 }
 
 // Radius returns the radius of the sphere.
@@ -40,16 +57,24 @@ func (s *CollisionSphere) Radius() float32 {
 // GetBoundingVolume returns a bounding volume for this collision shape. Kinda
 // redundant on a sphere...
 func (s *CollisionSphere) GetBoundingVolume() *BoundingSphere {
-	return &BoundingSphere{
-		center: s.Position(),
-		radius: s.Radius(),
-	}
+	var v BoundingSphere
+	s.BoundingVolumeIn(&v)
+	return &v
+}
+
+// BoundingVolumeIn is the same as GetBoundingVolume but stores the result in
+// dst instead of allocating a new bounding sphere.
+func (s *CollisionSphere) BoundingVolumeIn(dst *BoundingSphere) {
+	dst.center = s.Position()
+	dst.radius = s.Radius()
 }
 
-// GetInertiaTensor returns the inertia tensor for this collision shape.
+// GetInertiaTensor returns the inertia tensor for this collision shape around
+// the center of mass of b.
 func (s *CollisionSphere) GetInertiaTensor(b *RigidBody) glm.Mat3 {
 	s.body = b
-	return sphereInertiaTensor(b.Mass(), s.radius)
+	it := sphereInertiaTensor(b.Mass(), s.radius)
+	return parallelAxisInertiaTensor(&it, b.Mass(), &b.centerOfMass)
 }
 
 // RayTest tests this ray against the sphere annd the result if there is
@@ -119,6 +144,14 @@ func NewCollisionBox(halfSize glm.Vec3) *CollisionBox {
 
 // GetBoundingVolume returns a bounding volume for this collision shape.
 func (b *CollisionBox) GetBoundingVolume() *BoundingSphere {
+	var v BoundingSphere
+	b.BoundingVolumeIn(&v)
+	return &v
+}
+
+// BoundingVolumeIn is the same as GetBoundingVolume but stores the result in
+// dst instead of allocating a new bounding sphere.
+func (b *CollisionBox) BoundingVolumeIn(dst *BoundingSphere) {
 	r := b.halfSize.X
 	if b.halfSize.Y > r {
 		r = b.halfSize.Y
@@ -127,21 +160,22 @@ func (b *CollisionBox) GetBoundingVolume() *BoundingSphere {
 		r = b.halfSize.Z
 	}
 
-	return &BoundingSphere{
-		center: b.body.Position(),
-		radius: r,
-	}
+	dst.center = b.Position()
+	dst.radius = r
 }
 
-// GetInertiaTensor returns the inertia tensor for this collision shape.
+// GetInertiaTensor returns the inertia tensor for this collision shape around
+// the center of mass of rb.
 func (b *CollisionBox) GetInertiaTensor(rb *RigidBody) glm.Mat3 {
 	b.body = rb
-	return cuboidInertiaTensor(rb.Mass(), b.halfSize.X, b.halfSize.Y, b.halfSize.Z)
+	it := cuboidInertiaTensor(rb.Mass(), b.halfSize.X, b.halfSize.Y, b.halfSize.Z)
+	return parallelAxisInertiaTensor(&it, rb.Mass(), &rb.centerOfMass)
 }
 
-// Position returns this collision shape position.
+// Position returns this collision shape position, the origin of the body's
+// shape which isn't its position when the center of mass is offset.
 func (b *CollisionBox) Position() glm.Vec3 {
-	return b.body.Position()
+	return b.body.OriginPosition()
 }
 
 // RayTest tests this ray against the box annd the result if there is
@@ -150,9 +184,9 @@ func (b *CollisionBox) RayTest(ray Ray, res RayResult) {
 	var tmin, tmax, tymin, tymax, tzmin, tzmax float32
 	// We transform the ray in local space and use a AABB algorithm instead.
 	ro := ray.Origin()
-	ro = b.body.transformMatrix.TransformInverse(&ro)
+	ro = b.body.shapeTransform.TransformInverse(&ro)
 	dir := ray.Direction()
-	dir = b.body.transformMatrix.TransformInverseDirection(&dir)
+	dir = b.body.shapeTransform.TransformInverseDirection(&dir)
 
 	idir0 := 1.0 / dir.X
 	if idir0 >= 0 {
@@ -8,3 +8,104 @@ type Constraint interface {
 	// Returns how many contacts we're generated.
 	GenerateContacts([]Contact) int
 }
+
+// LinkingConstraint is a constraint between 2 rigid bodies. The world doesn't
+// generate contacts between bodies linked by a constraint unless the
+// constraint asks for it. The answer must not change while the constraint is
+// in a world.
+type LinkingConstraint interface {
+	Constraint
+
+	// LinkedBodies returns the bodies linked by this constraint and whether
+	// they should still collide with each other.
+	LinkedBodies() (b0, b1 *RigidBody, collide bool)
+}
+
+// BreakableConstraint is a constraint that reports the impulse the dispatcher
+// applied to keep it and that breaks when the force needed gets too large. A
+// broken constraint generates no contacts until it is repaired. Constraints
+// become breakable by embedding ConstraintFeedback.
+type BreakableConstraint interface {
+	Constraint
+
+	// Feedback returns the feedback of this constraint, the world writes to
+	// it after every step.
+	Feedback() *ConstraintFeedback
+}
+
+// verify, at compile time, that these types implement BreakableConstraint.
+var _ BreakableConstraint = &RodConstraintToWorld{}
+var _ BreakableConstraint = &RodConstraintToBody{}
+var _ BreakableConstraint = &StringToWorldConstraint{}
+var _ BreakableConstraint = &StringToBodyConstraint{}
+var _ BreakableConstraint = &SliderToWorldConstraint{}
+var _ BreakableConstraint = &JointConstraint{}
+
+// ConstraintFeedback holds the impulse a constraint needed during the last step
+// and the force it breaks at. The zero value never breaks.
+type ConstraintFeedback struct {
+	impulse, force float32
+	breakForce     float32
+	broken         bool
+
+	// unlinked is set while the world doesn't count this broken constraint as
+	// linking its bodies, they collide again.
+	unlinked bool
+}
+
+// Feedback returns f, it makes the types embedding a ConstraintFeedback
+// implement BreakableConstraint.
+func (f *ConstraintFeedback) Feedback() *ConstraintFeedback {
+	return f
+}
+
+// Impulse returns the impulse applied to keep the constraint during the last
+// step.
+func (f *ConstraintFeedback) Impulse() float32 {
+	return f.impulse
+}
+
+// Force returns the average force applied to keep the constraint during the
+// last step.
+func (f *ConstraintFeedback) Force() float32 {
+	return f.force
+}
+
+// BreakForce returns the force above which the constraint breaks, 0 if it
+// never breaks.
+func (f *ConstraintFeedback) BreakForce() float32 {
+	return f.breakForce
+}
+
+// SetBreakForce sets the force above which the constraint breaks, 0 for a
+// constraint that never breaks.
+func (f *ConstraintFeedback) SetBreakForce(force float32) {
+	f.breakForce = force
+}
+
+// Broken returns true if the constraint broke, it then generates no contacts
+// and the bodies it links collide with each other.
+func (f *ConstraintFeedback) Broken() bool {
+	return f.broken
+}
+
+// Repair makes a broken constraint work again. The bodies it links stop
+// colliding with each other again from the next step.
+func (f *ConstraintFeedback) Repair() {
+	f.broken = false
+}
+
+// record saves the impulse applied during a step of the given duration and
+// returns true if the constraint just broke.
+func (f *ConstraintFeedback) record(impulse, duration float32) bool {
+	f.impulse = impulse
+	f.force = 0
+	if duration > 0 {
+		f.force = impulse / duration
+	}
+	if f.broken || f.breakForce <= 0 || f.force <= f.breakForce {
+		return false
+	}
+	f.broken = true
+	return true
+}
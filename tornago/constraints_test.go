@@ -0,0 +1,143 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/glm"
+	"testing"
+)
+
+func TestConstraintFeedback(t *testing.T) {
+	var f ConstraintFeedback
+	if f.Feedback() != &f {
+		t.Error("Feedback() should return itself")
+	}
+	if f.record(100, 0.5) {
+		t.Error("a constraint without break force broke")
+	}
+	if f.Impulse() != 100 || f.Force() != 200 {
+		t.Errorf("Impulse(), Force() = %v, %v, want 100, 200", f.Impulse(), f.Force())
+	}
+
+	f.SetBreakForce(150)
+	if f.BreakForce() != 150 {
+		t.Errorf("BreakForce() = %v, want 150", f.BreakForce())
+	}
+	if f.record(50, 0.5) {
+		t.Error("the constraint broke under its break force")
+	}
+	if !f.record(100, 0.5) || !f.Broken() {
+		t.Error("the constraint didn't break above its break force")
+	}
+	if f.record(100, 0.5) {
+		t.Error("a broken constraint broke again")
+	}
+	f.Repair()
+	if f.Broken() {
+		t.Error("Repair() didn't repair the constraint")
+	}
+}
+
+func TestWorld_BreakableConstraint(t *testing.T) {
+	w := NewWorld(&NaiveBroadphase{}, ContactResolver{})
+	b := NewRigidBody()
+	b.SetMass(2)
+	b.SetCollisionShape(NewCollisionSphere(0.5))
+	b.SetLinearDamping(1)
+	b.SetAcceleration3f(0, -10, 0)
+	b.SetPosition3f(0, 0, 0)
+	w.AddRigidBody(b)
+
+	// the string is a little too long so it's already pulling on the body.
+	c := NewStringToWorldConstraint(glm.Vec3{X: 0, Y: 2, Z: 0}, glm.Vec3{}, b, 1.99, 0).(*StringToWorldConstraint)
+	w.AddConstraint(c)
+
+	var broken []BreakableConstraint
+	w.SetBreakHandler(func(c BreakableConstraint) {
+		broken = append(broken, c)
+		w.RemoveConstraint(c)
+	})
+
+	for i := 0; i < 30; i++ {
+		w.Step(1.0 / 60)
+	}
+	// the string holds the weight of the body.
+	if f := c.Force(); f < 15 || f > 25 {
+		t.Errorf("Force() = %v, want about 20", f)
+	}
+	if p := b.Position(); p.Y < -0.1 {
+		t.Errorf("b.Position() = %v, the body should hang from the string", p)
+	}
+
+	c.SetBreakForce(15)
+	w.Step(1.0 / 60)
+	if !c.Broken() {
+		t.Fatal("the string didn't break")
+	}
+	if len(broken) != 1 || broken[0] != c {
+		t.Errorf("break handler called with %v, want [%p]", broken, c)
+	}
+	if len(w.constraints) != 0 {
+		t.Errorf("the break handler didn't remove the constraint")
+	}
+	if n := c.GenerateContacts(make([]Contact, 1)); n != 0 {
+		t.Errorf("a broken constraint generated %d contacts", n)
+	}
+
+	for i := 0; i < 30; i++ {
+		w.Step(1.0 / 60)
+	}
+	if p := b.Position(); p.Y > -1 {
+		t.Errorf("b.Position() = %v, the body should have fallen", p)
+	}
+}
+
+func TestWorld_BreakableConstraintLink(t *testing.T) {
+	w := NewWorld(&NaiveBroadphase{}, ContactResolver{})
+	w.SetProfiling(true)
+	bodies := [2]*RigidBody{NewRigidBody(), NewRigidBody()}
+	for i, b := range bodies {
+		b.SetMass(1)
+		b.SetCollisionShape(NewCollisionSphere(2))
+		b.SetPosition3f(float32(i), 0, 0)
+		b.SetVelocity3f(float32(1-2*i), 0, 0)
+		w.AddRigidBody(b)
+	}
+
+	// holding the overlapping spheres moving towards each other breaks the
+	// rod.
+	c := NewRodConstraintToBody(0.5, bodies[0], bodies[1], &glm.Vec3{}, &glm.Vec3{})
+	w.AddConstraint(c)
+	if w.ShouldCollide(bodies[0], bodies[1]) {
+		t.Fatal("the linked bodies should not collide")
+	}
+
+	c.SetBreakForce(1)
+	w.Step(1.0 / 60)
+	if !c.Broken() {
+		t.Fatal("the rod didn't break")
+	}
+	if !w.ShouldCollide(bodies[0], bodies[1]) {
+		t.Error("the bodies of the broken rod should collide")
+	}
+	w.Step(1.0 / 60)
+	if n := w.Stats().Last.Contacts; n != 1 {
+		t.Errorf("%d contacts after the break, want the spheres to collide", n)
+	}
+
+	// removing the broken rod doesn't unlink its bodies twice, adding it back
+	// doesn't link them.
+	w.RemoveConstraint(c)
+	if len(w.linkedPairs) != 0 || w.unlinked != 0 {
+		t.Errorf("linkedPairs = %v, unlinked = %d, want none", w.linkedPairs, w.unlinked)
+	}
+	w.AddConstraint(c)
+	if !w.ShouldCollide(bodies[0], bodies[1]) {
+		t.Error("the bodies of the broken rod should collide")
+	}
+
+	c.SetBreakForce(0)
+	c.Repair()
+	w.Step(1.0 / 60)
+	if w.ShouldCollide(bodies[0], bodies[1]) {
+		t.Error("the bodies of the repaired rod should not collide")
+	}
+}
@@ -47,6 +47,9 @@ type Contact struct {
 
 	// Holds the restitution of the contact.
 	restitution float32
+
+	// Holds the total normal impulse applied to resolve the contact.
+	impulse float32
 }
 
 // Penetration returns the penetration depth of the 2 collision shapes.
@@ -54,6 +57,12 @@ func (c *Contact) Penetration() float32 {
 	return c.penetration
 }
 
+// Impulse returns the total impulse applied along the normal to resolve this
+// contact, it's 0 until the contact is resolved.
+func (c *Contact) Impulse() float32 {
+	return c.impulse
+}
+
 // Friction returns the friction between the 2 collision shapes.
 func (c *Contact) Friction() float32 {
 	return c.friction
@@ -206,6 +215,7 @@ func (c *Contact) resolveVelocity(data *contactDerivateData, velocityChange, rot
 	} else {
 		impulseContact = c.calculateFrictionImpulse(data, &inverseInertiaTensors)
 	}
+	c.impulse += impulseContact.X
 	impulse := data.contactToWorld.Mul3x1(&impulseContact)
 
 	impulsiveTorque := data.relativeContactPosition[0].Cross(&impulse)
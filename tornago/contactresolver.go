@@ -24,12 +24,40 @@ func makeOrthonormal(x, y, z *glm.Vec3) {
 // ContactResolver is the default Dispatcher.
 type ContactResolver struct{}
 
+// scratchDispatcher is a Dispatcher that can resolve contacts with scratch
+// memory owned by the world, so that a step doesn't allocate once it has grown
+// large enough.
+type scratchDispatcher interface {
+	// resolveContacts resolves the contacts, growing derivateData if needed,
+	// and returns how many position and velocity iterations were used.
+	resolveContacts(contacts []Contact, duration float32, derivateData *[]contactDerivateData) (int, int)
+}
+
 // ResolveContacts takes a contact slice and resolves them using the fancy core
 // algorithm.
 func (c ContactResolver) ResolveContacts(contacts []Contact, duration float32) {
+	var derivateData []contactDerivateData
+	c.resolveContacts(contacts, duration, &derivateData)
+}
+
+// ResolveContactsStats does the same as ResolveContacts but also records how
+// many iterations were used in stats.
+func (c ContactResolver) ResolveContactsStats(contacts []Contact, duration float32, stats *StepStats) {
+	var derivateData []contactDerivateData
+	stats.PositionIterations, stats.VelocityIterations = c.resolveContacts(contacts, duration, &derivateData)
+}
+
+// resolveContacts resolves the contacts and returns how many position and
+// velocity iterations were used. The scratch buffer is reused if it's large
+// enough.
+func (c ContactResolver) resolveContacts(contacts []Contact, duration float32, scratch *[]contactDerivateData) (int, int) {
 	// calculate derivate data
-	derivateData := make([]contactDerivateData, len(contacts))
+	if cap(*scratch) < len(contacts) {
+		*scratch = make([]contactDerivateData, len(contacts), 2*len(contacts))
+	}
+	derivateData := (*scratch)[:len(contacts)]
 	for n := 0; n < len(contacts); n++ {
+		derivateData[n] = contactDerivateData{}
 		contacts[n].calculateDerivateData(&derivateData[n], duration)
 		for i, b := range contacts[n].bodies {
 			if b != nil && b.callback != nil {
@@ -38,21 +66,23 @@ func (c ContactResolver) ResolveContacts(contacts []Contact, duration float32) {
 		}
 	}
 
-	c.adjustPositions(contacts, derivateData)
-	c.adjustVelocities(contacts, derivateData, duration)
-
+	positionIterations := c.adjustPositions(contacts, derivateData)
+	velocityIterations := c.adjustVelocities(contacts, derivateData, duration)
+	return positionIterations, velocityIterations
 }
 
-// adjustVelocities resolves as many velocities as it can.
+// adjustVelocities resolves as many velocities as it can and returns how many
+// iterations it used.
 // TODO(hydroflame): make a list that assumes (with reason) temporal coherence
 // to speed up the algorithm. Also this can probably be done in parallel with
 // the other adjust.
-func (c ContactResolver) adjustVelocities(contacts []Contact, derivateData []contactDerivateData, duration float32) {
+func (c ContactResolver) adjustVelocities(contacts []Contact, derivateData []contactDerivateData, duration float32) int {
 	// reserve some memory for keeping track of velocity change.
 	var velocityChange, rotationChange [2]glm.Vec3
 
 	// resolve penetration first
-	for i := 0; i < positionIterations; i++ {
+	var iterations int
+	for ; iterations < positionIterations; iterations++ {
 
 		// keep track of worse so far
 		worst := len(contacts)
@@ -66,7 +96,7 @@ func (c ContactResolver) adjustVelocities(contacts []Contact, derivateData []con
 
 		// if we didn't find any bad contact then we're done.
 		if worst == len(contacts) {
-			break
+			return iterations
 		}
 
 		// resolve velocity.
@@ -96,18 +126,21 @@ func (c ContactResolver) adjustVelocities(contacts []Contact, derivateData []con
 			}
 		}
 	}
+	return iterations
 }
 
-// adjustPositions resolves as many positions as it can.
+// adjustPositions resolves as many positions as it can and returns how many
+// iterations it used.
 // TODO(hydroflame): make a list that assumes (with reason) temporal coherence
 // to speed up the algorithm. Also this can probably be done in parallel with
 // the other adjust.
-func (c ContactResolver) adjustPositions(contacts []Contact, derivateData []contactDerivateData) {
+func (c ContactResolver) adjustPositions(contacts []Contact, derivateData []contactDerivateData) int {
 	// reserve some memory for keeping track of position change.
 	var linearChange, angularChange [2]glm.Vec3
 
 	// resolve penetration first
-	for i := 0; i < positionIterations; i++ {
+	var iterations int
+	for ; iterations < positionIterations; iterations++ {
 
 		// keep track of worse so far
 		worst := len(contacts)
@@ -121,7 +154,7 @@ func (c ContactResolver) adjustPositions(contacts []Contact, derivateData []cont
 
 		// if we didn't find any bad contact then we're done.
 		if worst == len(contacts) {
-			break
+			return iterations
 		}
 
 		contacts[worst].resolvePenetration(&derivateData[worst], &linearChange, &angularChange)
@@ -142,4 +175,5 @@ func (c ContactResolver) adjustPositions(contacts []Contact, derivateData []cont
 			}
 		}
 	}
+	return iterations
 }
@@ -6,7 +6,15 @@
 //  world := tornago.NewWorld(&tornago.NaiveBroadphase{}, tornago.ContactResolver{})
 // The first argument is the Broadphase, which is the algorithm used to detect
 // possible collisions. Test different broadphase to see which is more efficient
-// for your scene. The second argument is the collision dispatcher. It's the
+// for your scene. The Octree keeps its bodies between steps and suits large
+// worlds with objects of very different sizes.
+//  world := tornago.NewWorld(tornago.NewOctree(&center, 500, 8), tornago.ContactResolver{})
+// SAP3 streams its pairs to several goroutines generating the contacts at the
+// same time, the result is the same as with a single one.
+//  world := tornago.NewWorld(&tornago.SAP3{}, tornago.ContactResolver{})
+//  world.SetNarrowphaseWorkers(runtime.NumCPU())
+//  defer world.Close() // stops the goroutines
+// The second argument is the collision dispatcher. It's the
 // algorithm that takes the set of collision for a step and resolves them. For
 // now we only have 1 available dispatcher but you're free to implement your
 // own.
@@ -26,6 +34,9 @@
 // However your rigid bodies still won't collide as they have no shape. So we'll
 // need to add one.
 //  b1.SetCollisionShape(tornago.NewCollisionBox(glm.Vec3{0.5, 0.5, 0.5}))
+// Instead of setting the mass yourself the mass and inertia can be computed
+// from the shape.
+//  err := b1.SetMassFromDensity(1000) // water, in kg/m^3
 // Now you can add this shape to the world
 //  world.AddRigidBody(b1)
 // and voila, you're ready to step the world.
@@ -55,6 +66,10 @@
 //	)
 // and then set the appropriate group/mask for each body
 //	mushroom.Group, mushroom.Mask = GroupPowerup, MaskPowerup
+// Bodies linked by a constraint don't collide with each other. Specific pairs
+// can be ignored and a filter can reject any pair before the narrowphase.
+//	world.IgnorePair(player, sword)
+//	world.SetPairFilter(func(b0, b1 *RigidBody) bool { return !sameTeam(b0, b1) })
 //
 // Collision callbacks
 //
@@ -62,6 +77,28 @@
 // argument is the other rigid body with which it collided, you can use
 // RigibBody.Userdata to store a reference to any sort of data you could find
 // usefull during collision but closure can also be a great help.
+// Bodies, constraints and force generators added or removed from a callback are
+// only added or removed at the end of the step.
+//
+// Materials
+//
+// The friction and restitution of a contact are combined from both bodies. By
+// default they are averaged, materials choose how they're combined and a
+// material table can override specific pairs. Materials are set per body, which
+// is also per shape since a body has a single collision shape.
+//	ice := NewMaterial("ice", 0.02, 0.1)
+//	ice.FrictionCombine = CombineMin
+//	body.SetMaterial(ice)
+//	table := NewMaterialTable()
+//	table.SetPair(ice, skates, 0, 0)
+//	world.SetMaterialTable(table)
+//
+// Custom shapes
+//
+// Any type implementing CollisionShape can be used once a contact generator is
+// registered for it against the other shapes it should collide with. Pairs
+// without a generator are skipped.
+//	DefaultNarrowphase.Register(&Floor{}, &CollisionSphere{}, floorAndSphere)
 //
 // Constraints
 //
@@ -74,6 +111,12 @@
 // it was axis aligned, located at {0,0,0}, so if localPoint is {0,0,0} we mean
 // the center of mass. Then simply add it to the world.
 //  world.AddConstraint(str)
+// The constraints of this package report the force they needed during the
+// last step and break when it goes above their break force.
+//  str.SetBreakForce(500)
+//  world.SetBreakHandler(func(c tornago.BreakableConstraint) {
+//  	world.RemoveConstraint(c)
+//  })
 //
 // Force generators
 //
@@ -84,6 +127,27 @@
 //  world.AddForceGenerator(spring, &b1)
 // Force generators are called every frame and apply the force they're supposed
 // to.
+// Force fields, like explosions, wind, drag and vortices, are force generators
+// that affect every body in a region of the world.
+//  blast := NewExplosion(&glm.Vec3{0, 0, 0}, 10, 50, FalloffLinear)
+//  world.AddForceField(blast)
+//
+// Soft bodies
+//
+// Cloth, flags, capes and jelly are simulated with soft bodies, particles held
+// together by springs.
+//  flag, err := tornago.NewCloth(&glm.Vec3{0, 10, 0}, 2, 1, 20, 10, 0.5)
+//  if err != nil {
+//  	// at least 1 segment is needed along each side.
+//  }
+//  flag.SetAcceleration3f(0, -10, 0)
+//  for x := 0; x <= 20; x += 20 {
+//  	flag.Pin(x) // hold the top corners in place
+//  }
+//  world.AddSoftBody(flag)
+// Soft bodies collide with the spheres and boxes of the world and their mesh
+// can be given directly to the renderer.
+//  model := render.NewVUNModel(flag.Mesh())
 //
 // Ray tests
 //
@@ -91,4 +155,24 @@
 // queries that required casting a ray through the world and receiving who was
 // hit. First create a ray and select a result method and call
 //  world.RayTest(ray, result)
+//
+// Profiling
+//
+// If your steps are slow you can ask the world to collect timings and counters
+// for every phase of the step.
+//  world.SetProfiling(true)
+//  world.Step(1.0/60.0)
+//  stats := world.Stats()
+// stats.Last holds the stats of the last step and stats.Total the sum of every
+// step since profiling was enabled or ResetStats was called. To compare the
+// speed and stability of 2 versions of the engine run the canonical scenes of
+// the tornago-bench command.
+//  go run github.com/luxengine/lux/tornago/cmd/tornago-bench -format csv
+//
+// Scenes
+//
+// A world can be saved to JSON and loaded back, to write levels by hand or
+// to reproduce a bug. Callbacks and user data aren't saved.
+//  err := world.SaveWorld(file)
+//  world, err := tornago.LoadWorld(file)
 package tornago
@@ -0,0 +1,399 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+)
+
+// ForceField is a force generator that affects every rigid body of a world
+// inside a region instead of being registered per body. Add it to a world with
+// World.AddForceField. Force fields are still force generators and can be
+// registered for a single body with World.AddForceGenerator, Update must then
+// be called once per step by the user.
+type ForceField interface {
+	ForceGenerator
+
+	// Region returns the bounding sphere of the region affected by the field
+	// and true, or false if the field affects the whole world.
+	Region() (BoundingSphere, bool)
+
+	// Update is called once per step, before the field is applied to the
+	// bodies, to advance the time of the field.
+	Update(duration float32)
+}
+
+// verify, at compile time, that these types implement ForceField.
+var _ ForceField = &Explosion{}
+var _ ForceField = &Wind{}
+var _ ForceField = &Aerodynamics{}
+var _ ForceField = &Vortex{}
+
+// Falloff describes how the strength of a field decreases with the distance to
+// its center.
+type Falloff int
+
+// The falloff modes.
+const (
+	// FalloffNone keeps the full strength everywhere in the field.
+	FalloffNone Falloff = iota
+
+	// FalloffLinear decreases linearly to 0 at the edge of the field.
+	FalloffLinear
+
+	// FalloffQuadratic decreases with the square of the distance to the
+	// edge, most of the strength is near the center.
+	FalloffQuadratic
+)
+
+// scale returns the strength multiplier at the given distance in a field of
+// the given radius.
+func (f Falloff) scale(distance, radius float32) float32 {
+	if distance >= radius {
+		return 0
+	}
+	s := 1 - distance/radius
+	switch f {
+	case FalloffLinear:
+		return s
+	case FalloffQuadratic:
+		return s * s
+	}
+	return 1
+}
+
+// Explosion is a force field that pushes bodies away from its center. The
+// impulse is spread over Duration seconds after the explosion is detonated,
+// after that it has no effect until it is detonated again.
+type Explosion struct {
+	// The center of the explosion.
+	Center glm.Vec3
+
+	// The radius of the blast, bodies further than that aren't affected.
+	Radius float32
+
+	// The impulse given to a body at the center of the explosion, heavier
+	// bodies are moved less.
+	Impulse float32
+
+	// How long the impulse is spread over, in seconds. 0 means a single step.
+	Duration float32
+
+	// How the impulse decreases with the distance.
+	Falloff Falloff
+
+	// If not nil, bodies hidden from the center by another body of this world
+	// are not affected. Occlusion requires a ray test per affected body.
+	Occluders *World
+
+	elapsed float32
+	started bool
+}
+
+// NewExplosion returns an explosion with the given parameters, it is detonated
+// as soon as it is added to a world.
+func NewExplosion(center *glm.Vec3, radius, impulse float32, falloff Falloff) *Explosion {
+	var e Explosion
+	e.New(center, radius, impulse, falloff)
+	return &e
+}
+
+// New initialises this Explosion with the given arguments. This is used for
+// memory management.
+func (e *Explosion) New(center *glm.Vec3, radius, impulse float32, falloff Falloff) {
+	*e = Explosion{
+		Center:  *center,
+		Radius:  radius,
+		Impulse: impulse,
+		Falloff: falloff,
+	}
+}
+
+// Detonate restarts the explosion.
+func (e *Explosion) Detonate() {
+	e.elapsed = 0
+	e.started = false
+}
+
+// Done returns true if the explosion is over, it can be removed from the world
+// or detonated again.
+func (e *Explosion) Done() bool {
+	return e.started && e.elapsed > 0 && e.elapsed >= e.Duration
+}
+
+// Region returns the blast sphere.
+func (e *Explosion) Region() (BoundingSphere, bool) {
+	return NewBoundingSphere(&e.Center, e.Radius), true
+}
+
+// Update advances the time of the explosion.
+func (e *Explosion) Update(duration float32) {
+	if e.started {
+		e.elapsed += duration
+		return
+	}
+	e.started = true
+}
+
+// UpdateForce calculates and update the force applied to the given rigid body.
+func (e *Explosion) UpdateForce(b *RigidBody, duration float32) {
+	if e.Done() || duration <= 0 {
+		return
+	}
+
+	dir := b.position.Sub(&e.Center)
+	distance := dir.Len()
+	scale := e.Falloff.scale(distance, e.Radius)
+	if scale == 0 {
+		return
+	}
+	if distance < 1e-6 {
+		// at the center there is no direction to push towards.
+		return
+	}
+	dir.MulWith(1 / distance)
+
+	if e.Occluders != nil {
+		result := RayResultClosest{Origin: e.Center}
+		e.Occluders.RayTest(NewRay(e.Center, dir, distance), &result)
+		if result.Body != nil && result.Body != b {
+			return
+		}
+	}
+
+	// the impulse is spread over the duration, the last step only gives what
+	// remains of it.
+	fraction := float32(1)
+	if e.Duration > 0 {
+		fraction = math.Min(duration, e.Duration-e.elapsed) / e.Duration
+	}
+	dir.MulWith(e.Impulse * scale * fraction / duration)
+	b.AddForce(&dir)
+}
+
+// Wind is a force field that drags bodies along with the air. The force is
+// proportional to the velocity of the body relative to the wind, so bodies
+// never go faster than the wind. Turbulence adds smooth deterministic gusts
+// that vary in time and space.
+type Wind struct {
+	// The velocity of the wind.
+	Velocity glm.Vec3
+
+	// How strongly the bodies follow the wind. The force is Coefficient times
+	// the relative velocity.
+	Coefficient float32
+
+	// The amplitude of the gusts, as a velocity.
+	Turbulence float32
+
+	// How fast the gusts change, in cycles per second.
+	Frequency float32
+
+	// The region affected by the wind, the whole world if its radius is 0.
+	Area BoundingSphere
+
+	time float32
+}
+
+// NewWind returns a wind blowing everywhere with the given velocity and
+// coefficient.
+func NewWind(velocity *glm.Vec3, coefficient float32) *Wind {
+	var w Wind
+	w.New(velocity, coefficient)
+	return &w
+}
+
+// New initialises this Wind with the given arguments. This is used for memory
+// management.
+func (w *Wind) New(velocity *glm.Vec3, coefficient float32) {
+	*w = Wind{
+		Velocity:    *velocity,
+		Coefficient: coefficient,
+		Frequency:   1,
+	}
+}
+
+// Region returns the area of the wind.
+func (w *Wind) Region() (BoundingSphere, bool) {
+	return w.Area, w.Area.radius > 0
+}
+
+// Update advances the time of the gusts.
+func (w *Wind) Update(duration float32) {
+	w.time += duration
+}
+
+// VelocityAt returns the velocity of the wind at the given point, including
+// the gusts.
+func (w *Wind) VelocityAt(p *glm.Vec3) glm.Vec3 {
+	v := w.Velocity
+	if w.Turbulence == 0 {
+		return v
+	}
+	// a few out of phase sines make for gusts that don't look periodic.
+	t := 2 * math.Pi * w.Frequency * w.time
+	v.X += w.Turbulence * math.Sin(t+0.31*p.Y+0.17*p.Z) * math.Cos(0.7*t+0.23*p.X)
+	v.Y += w.Turbulence * 0.5 * math.Sin(1.3*t+0.19*p.X+0.29*p.Z)
+	v.Z += w.Turbulence * math.Cos(0.9*t+0.27*p.X+0.13*p.Y) * math.Sin(1.1*t+0.37*p.Z)
+	return v
+}
+
+// UpdateForce calculates and update the force applied to the given rigid body.
+func (w *Wind) UpdateForce(b *RigidBody, _ float32) {
+	force := w.VelocityAt(&b.position)
+	force.SubWith(&b.velocity)
+	force.MulWith(w.Coefficient)
+	b.AddForce(&force)
+}
+
+// Aerodynamics is a force field that applies drag and lift to bodies moving
+// through a fluid. The drag is opposed to the velocity of the body relative
+// to the fluid and the lift is perpendicular to it, pushing bodies along their
+// lift axis like a wing.
+type Aerodynamics struct {
+	// The velocity of the fluid.
+	FluidVelocity glm.Vec3
+
+	// The linear and quadratic drag coefficients, the drag is
+	// LinearDrag*|v| + QuadraticDrag*|v|^2.
+	LinearDrag, QuadraticDrag float32
+
+	// The lift coefficient, 0 for no lift.
+	Lift float32
+
+	// The axis, in body local space, that lift pushes along. Usually the
+	// normal of the wing.
+	LiftAxis glm.Vec3
+
+	// The region affected, the whole world if its radius is 0.
+	Area BoundingSphere
+}
+
+// NewAerodynamics returns an aerodynamics field affecting the whole world with
+// the given drag coefficients and no lift.
+func NewAerodynamics(linearDrag, quadraticDrag float32) *Aerodynamics {
+	var a Aerodynamics
+	a.New(linearDrag, quadraticDrag)
+	return &a
+}
+
+// New initialises this Aerodynamics with the given arguments. This is used for
+// memory management.
+func (a *Aerodynamics) New(linearDrag, quadraticDrag float32) {
+	*a = Aerodynamics{
+		LinearDrag:    linearDrag,
+		QuadraticDrag: quadraticDrag,
+		LiftAxis:      glm.Vec3{X: 0, Y: 1, Z: 0},
+	}
+}
+
+// Region returns the area of the field.
+func (a *Aerodynamics) Region() (BoundingSphere, bool) {
+	return a.Area, a.Area.radius > 0
+}
+
+// Update does nothing, aerodynamics don't change with time.
+func (a *Aerodynamics) Update(float32) {}
+
+// UpdateForce calculates and update the force applied to the given rigid body.
+func (a *Aerodynamics) UpdateForce(b *RigidBody, _ float32) {
+	v := b.velocity.Sub(&a.FluidVelocity)
+	speed := v.Len()
+	if speed < 1e-6 {
+		return
+	}
+	v.MulWith(1 / speed)
+
+	// drag
+	force := v.Mul(-(a.LinearDrag*speed + a.QuadraticDrag*speed*speed))
+
+	// lift is strongest when the axis is at 45 degrees from the velocity and
+	// disappears when they are parallel or perpendicular.
+	if a.Lift != 0 {
+		n := b.transformMatrix.TransformDirection(&a.LiftAxis)
+		n.Normalize()
+		cos := n.Dot(&v)
+		n.AddScaledVec(-cos, &v)
+		force.AddScaledVec(-a.Lift*speed*speed*cos, &n)
+	}
+	b.AddForce(&force)
+}
+
+// Vortex is a force field that spins bodies around an axis, like a tornado or
+// a whirlpool. Like the attractors it accelerates every body the same way
+// regardless of their mass.
+type Vortex struct {
+	// The middle of the circle at the base of the vortex.
+	Base glm.Vec3
+
+	// The axis of the vortex, must be normalized. Bodies spin counter
+	// clockwise around it.
+	Axis glm.Vec3
+
+	// The radius and height of the vortex cylinder.
+	Radius, Height float32
+
+	// The acceleration around the axis.
+	Tangential float32
+
+	// The acceleration towards the axis, negative to push bodies away.
+	Inward float32
+
+	// The acceleration along the axis.
+	Lift float32
+
+	// How the accelerations decrease with the distance to the axis.
+	Falloff Falloff
+}
+
+// NewVortex returns a vortex with the given shape and tangential acceleration.
+// The axis must be normalized.
+func NewVortex(base, axis *glm.Vec3, radius, height, tangential float32) *Vortex {
+	var v Vortex
+	v.New(base, axis, radius, height, tangential)
+	return &v
+}
+
+// New initialises this Vortex with the given arguments. This is used for
+// memory management.
+func (v *Vortex) New(base, axis *glm.Vec3, radius, height, tangential float32) {
+	*v = Vortex{
+		Base:       *base,
+		Axis:       *axis,
+		Radius:     radius,
+		Height:     height,
+		Tangential: tangential,
+	}
+}
+
+// Region returns the sphere around the vortex cylinder.
+func (v *Vortex) Region() (BoundingSphere, bool) {
+	center := v.Base
+	center.AddScaledVec(v.Height/2, &v.Axis)
+	return NewBoundingSphere(&center, math.Sqrt(v.Radius*v.Radius+v.Height*v.Height/4)), true
+}
+
+// Update does nothing, vortices don't change with time.
+func (v *Vortex) Update(float32) {}
+
+// UpdateForce calculates and update the force applied to the given rigid body.
+func (v *Vortex) UpdateForce(b *RigidBody, _ float32) {
+	rel := b.position.Sub(&v.Base)
+	h := rel.Dot(&v.Axis)
+	if h < 0 || h > v.Height {
+		return
+	}
+	rel.AddScaledVec(-h, &v.Axis)
+	distance := rel.Len()
+	scale := v.Falloff.scale(distance, v.Radius)
+	if scale == 0 || distance < 1e-6 {
+		return
+	}
+	rel.MulWith(1 / distance)
+
+	force := v.Axis.Cross(&rel)
+	force.MulWith(v.Tangential)
+	force.AddScaledVec(-v.Inward, &rel)
+	force.AddScaledVec(v.Lift, &v.Axis)
+	force.MulWith(scale * b.Mass())
+	b.AddForce(&force)
+}
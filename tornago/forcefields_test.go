@@ -0,0 +1,202 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"testing"
+)
+
+func newFieldTestBody(x, y, z float32) *RigidBody {
+	b := NewRigidBody()
+	b.SetMass(2)
+	b.SetLinearDamping(1)
+	b.SetCollisionShape(NewCollisionSphere(0.5))
+	b.SetPosition3f(x, y, z)
+	b.calculateDerivedData()
+	return b
+}
+
+func TestFalloff_scale(t *testing.T) {
+	tests := []struct {
+		falloff  Falloff
+		distance float32
+		expected float32
+	}{
+		{FalloffNone, 5, 1},
+		{FalloffNone, 10, 0},
+		{FalloffLinear, 5, 0.5},
+		{FalloffQuadratic, 5, 0.25},
+		{FalloffQuadratic, 0, 1},
+	}
+	for i, test := range tests {
+		if s := test.falloff.scale(test.distance, 10); s != test.expected {
+			t.Errorf("[%d] scale(%f, 10) = %f, want %f", i, test.distance, s, test.expected)
+		}
+	}
+}
+
+func TestExplosion(t *testing.T) {
+	near := newFieldTestBody(2, 0, 0)
+	far := newFieldTestBody(0, 0, 20)
+	e := NewExplosion(&glm.Vec3{}, 4, 10, FalloffLinear)
+
+	e.Update(0.1)
+	for _, b := range []*RigidBody{near, far} {
+		e.UpdateForce(b, 0.1)
+		b.Integrate(0.1)
+	}
+
+	// half of the impulse on a mass of 2.
+	if v := near.Velocity(); !v.EqualThreshold(&glm.Vec3{X: 2.5, Y: 0, Z: 0}, 1e-4) {
+		t.Errorf("near.Velocity() = %v, want {2.5, 0, 0}", v)
+	}
+	if v := far.Velocity(); v != (glm.Vec3{}) {
+		t.Errorf("far.Velocity() = %v, want zero", v)
+	}
+
+	// the explosion is over after the first step.
+	e.Update(0.1)
+	if !e.Done() {
+		t.Error("e.Done() = false, want true")
+	}
+	e.UpdateForce(near, 0.1)
+	near.Integrate(0.1)
+	if v := near.Velocity(); !v.EqualThreshold(&glm.Vec3{X: 2.5, Y: 0, Z: 0}, 1e-4) {
+		t.Errorf("near.Velocity() = %v, the explosion should be over", v)
+	}
+
+	e.Detonate()
+	if e.Done() {
+		t.Error("e.Done() = true after Detonate")
+	}
+}
+
+func TestExplosion_Occlusion(t *testing.T) {
+	w := NewWorld(&NaiveBroadphase{}, ContactResolver{})
+	wall := newFieldTestBody(2, 0, 0)
+	wall.SetMass(0)
+	wall.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 0.1, Y: 2, Z: 2}))
+	wall.calculateDerivedData()
+	hidden := newFieldTestBody(4, 0, 0)
+	exposed := newFieldTestBody(-4, 0, 0)
+	w.AddRigidBody(wall)
+	w.AddRigidBody(hidden)
+	w.AddRigidBody(exposed)
+
+	e := NewExplosion(&glm.Vec3{}, 10, 10, FalloffNone)
+	e.Occluders = w
+	w.AddForceField(e)
+	w.Step(1.0 / 60)
+
+	if v := hidden.Velocity(); v.X != 0 {
+		t.Errorf("hidden.Velocity() = %v, the wall should protect it", v)
+	}
+	if v := exposed.Velocity(); v.X >= 0 {
+		t.Errorf("exposed.Velocity() = %v, should be pushed towards -X", v)
+	}
+	if v := wall.Velocity(); v != (glm.Vec3{}) {
+		t.Errorf("wall.Velocity() = %v, static bodies must not move", v)
+	}
+}
+
+func TestWind(t *testing.T) {
+	b := newFieldTestBody(0, 0, 0)
+	w := NewWind(&glm.Vec3{X: 5, Y: 0, Z: 0}, 4)
+	for i := 0; i < 600; i++ {
+		w.Update(1.0 / 60)
+		w.UpdateForce(b, 1.0/60)
+		b.Integrate(1.0 / 60)
+	}
+	if v := b.Velocity(); !v.EqualThreshold(&glm.Vec3{X: 5, Y: 0, Z: 0}, 1e-2) {
+		t.Errorf("b.Velocity() = %v, should follow the wind", v)
+	}
+
+	w.Turbulence = 2
+	v0 := w.VelocityAt(&glm.Vec3{})
+	w.Update(0.3)
+	v1 := w.VelocityAt(&glm.Vec3{})
+	if v0 == v1 {
+		t.Errorf("turbulence doesn't change with time: %v", v0)
+	}
+	if d := v1.Sub(&w.Velocity); d.Len() > 2*math.Sqrt(2.25) {
+		t.Errorf("gust %v is stronger than the turbulence", d)
+	}
+}
+
+func TestAerodynamics(t *testing.T) {
+	b := newFieldTestBody(0, 0, 0)
+	b.SetVelocity3f(2, 0, 0)
+	a := NewAerodynamics(1, 0.5)
+	a.UpdateForce(b, 1.0/60)
+	if f := b.forceAccumulator; !f.EqualThreshold(&glm.Vec3{X: -4, Y: 0, Z: 0}, 1e-5) {
+		t.Errorf("drag = %v, want {-4, 0, 0}", f)
+	}
+	b.clearAccumulators()
+
+	// a wing tilted at 45 degrees moving forward is pushed up.
+	a = NewAerodynamics(0, 0)
+	a.Lift = 1
+	s := math.Sqrt(0.5)
+	a.LiftAxis = glm.Vec3{X: -s, Y: s, Z: 0}
+	a.UpdateForce(b, 1.0/60)
+	if f := b.forceAccumulator; !f.EqualThreshold(&glm.Vec3{X: 0, Y: 2, Z: 0}, 1e-5) {
+		t.Errorf("lift = %v, want {0, 2, 0}", f)
+	}
+}
+
+func TestVortex(t *testing.T) {
+	v := NewVortex(&glm.Vec3{}, &glm.Vec3{X: 0, Y: 1, Z: 0}, 5, 10, 3)
+	v.Inward = 1
+	v.Lift = 2
+
+	b := newFieldTestBody(1, 2, 0)
+	v.UpdateForce(b, 1.0/60)
+	// mass 2, counter clockwise around +Y at +X is -Z.
+	if f := b.forceAccumulator; !f.EqualThreshold(&glm.Vec3{X: -2, Y: 4, Z: -6}, 1e-5) {
+		t.Errorf("force = %v, want {-2, 4, -6}", f)
+	}
+
+	above := newFieldTestBody(1, 11, 0)
+	v.UpdateForce(above, 1.0/60)
+	if f := above.forceAccumulator; f != (glm.Vec3{}) {
+		t.Errorf("force above the vortex = %v, want zero", f)
+	}
+
+	region, bounded := v.Region()
+	if !bounded || region.center != (glm.Vec3{X: 0, Y: 5, Z: 0}) {
+		t.Errorf("v.Region() = %v, %v", region, bounded)
+	}
+}
+
+func TestWorld_ForceField(t *testing.T) {
+	w, _ := newStepTestWorld(&NaiveBroadphase{}, 10, 1)
+	wind := NewWind(&glm.Vec3{X: 10, Y: 0, Z: 0}, 1)
+	wind.Area = NewBoundingSphere(&glm.Vec3{X: 100, Y: 0, Z: 0}, 1)
+	outside := newFieldTestBody(0, 50, 0)
+	inside := newFieldTestBody(100, 0, 0)
+	w.AddRigidBody(outside)
+	w.AddRigidBody(inside)
+
+	w.AddForceField(wind)
+	w.AddForceField(wind)
+	if len(w.forceFields) != 1 {
+		t.Errorf("World should contain 1 force field: %d", len(w.forceFields))
+	}
+
+	w.Step(1.0 / 60)
+	if v := inside.Velocity(); v.X <= 0 {
+		t.Errorf("inside.Velocity() = %v, should be blown", v)
+	}
+	if v := outside.Velocity(); v.X != 0 {
+		t.Errorf("outside.Velocity() = %v, shouldn't be blown", v)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { w.Step(1.0 / 60) }); allocs != 0 {
+		t.Errorf("w.Step allocated %v times per run, want 0", allocs)
+	}
+
+	w.RemoveForceField(wind)
+	if len(w.forceFields) != 0 {
+		t.Errorf("World should contain 0 force field: %d", len(w.forceFields))
+	}
+}
@@ -22,3 +22,21 @@ func cuboidInertiaTensor(mass, dx, dy, dz float32) glm.Mat3 {
 		0, 0, f * mass * (dx2 + dy2),
 	}
 }
+
+// parallelAxisInertiaTensor returns the inertia tensor it of a body of the
+// given mass around its center of mass moved to a point offset away from it,
+// with the parallel axis theorem.
+func parallelAxisInertiaTensor(it *glm.Mat3, mass float32, offset *glm.Vec3) glm.Mat3 {
+	d2 := offset.Dot(offset)
+	m := *it
+	for col := 0; col < 3; col++ {
+		for row := 0; row < 3; row++ {
+			v := -*offset.I(row) * *offset.I(col)
+			if row == col {
+				v += d2
+			}
+			m[col*3+row] += mass * v
+		}
+	}
+	return m
+}
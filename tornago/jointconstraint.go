@@ -0,0 +1,164 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+)
+
+// JointConstraint is a ball and socket joint between 2 rigid bodies. The
+// anchor points of both bodies are kept together and the rotation of the
+// second body relative to the first can optionally be limited. The limits are
+// enforced by keeping points on levers along the joint axis and along a normal
+// to it close to each other, so they are approximate but cheap and stable.
+type JointConstraint struct {
+	// The bodies involved in the joint, usually the parent and the child.
+	Bodies [2]*RigidBody
+
+	// The anchor of the joint in each body local space.
+	LocalPoints [2]glm.Vec3
+
+	// The joint axis in each body local space. With no rotation between the
+	// bodies both axes point in the same world direction.
+	LocalAxes [2]glm.Vec3
+
+	// A normal to the joint axis in each body local space, used for the twist
+	// limit.
+	LocalNormals [2]glm.Vec3
+
+	// The maximum angle in radians between the axes of both bodies. 0 means
+	// no limit.
+	SwingLimit float32
+
+	// The maximum rotation in radians around the axis. It's only enforced
+	// when SwingLimit is also set. 0 means no limit.
+	TwistLimit float32
+
+	// The length of the levers used to enforce the limits, it should be
+	// around the size of the bodies.
+	LeverLength float32
+
+	// If true the 2 bodies still collide with each other.
+	CollideLinked bool
+
+	// The impulse feedback and break force of the joint.
+	ConstraintFeedback
+}
+
+// NewJointConstraint returns a joint between the 2 bodies at the given anchor
+// with the given axis, both in world coordinates. The current orientation of
+// the bodies is used as the rest pose so their derived data must be up to date.
+func NewJointConstraint(body0, body1 *RigidBody, anchor, axis *glm.Vec3, swingLimit, twistLimit float32) *JointConstraint {
+	var j JointConstraint
+	j.New(body0, body1, anchor, axis, swingLimit, twistLimit)
+	return &j
+}
+
+// New initialises this JointConstraint with the given arguments. This is used
+// for memory management.
+func (j *JointConstraint) New(body0, body1 *RigidBody, anchor, axis *glm.Vec3, swingLimit, twistLimit float32) {
+	x := axis.Normalized()
+	var y, z glm.Vec3
+	makeOrthonormal(&x, &y, &z)
+	y.Normalize()
+
+	*j = JointConstraint{
+		Bodies:      [2]*RigidBody{body0, body1},
+		SwingLimit:  swingLimit,
+		TwistLimit:  twistLimit,
+		LeverLength: 1,
+	}
+	for i, b := range j.Bodies {
+		b.transformMatrix.TransformInverseIn(anchor, &j.LocalPoints[i])
+		b.transformMatrix.TransformInverseDirectionIn(&x, &j.LocalAxes[i])
+		b.transformMatrix.TransformInverseDirectionIn(&y, &j.LocalNormals[i])
+	}
+}
+
+// LinkedBodies returns the bodies of this joint.
+func (j *JointConstraint) LinkedBodies() (*RigidBody, *RigidBody, bool) {
+	return j.Bodies[0], j.Bodies[1], j.CollideLinked
+}
+
+// GenerateContacts generates up to 3 contacts, one to keep the anchors
+// together and one for each limit that is exceeded.
+func (j *JointConstraint) GenerateContacts(contacts []Contact) int {
+	if j.Broken() {
+		return 0
+	}
+	var anchors, levers [2]glm.Vec3
+	for i, b := range j.Bodies {
+		b.transformMatrix.TransformIn(&j.LocalPoints[i], &anchors[i])
+	}
+
+	var n int
+	if n < len(contacts) && j.distanceLimit(&anchors[0], &anchors[1], 0, &contacts[n]) {
+		n++
+	}
+
+	if j.SwingLimit <= 0 || j.SwingLimit >= math.Pi {
+		return n
+	}
+
+	// the levers of the first body are moved by the anchors separation so
+	// that the limits only react to rotation.
+	var separation glm.Vec3
+	separation.SubOf(&anchors[1], &anchors[0])
+
+	for i, b := range j.Bodies {
+		p := j.LocalPoints[i]
+		p.AddScaledVec(j.LeverLength, &j.LocalAxes[i])
+		b.transformMatrix.TransformIn(&p, &levers[i])
+	}
+	levers[0].AddWith(&separation)
+	if n < len(contacts) && j.distanceLimit(&levers[0], &levers[1], chordLength(j.LeverLength, j.SwingLimit), &contacts[n]) {
+		n++
+	}
+
+	if j.TwistLimit <= 0 {
+		return n
+	}
+
+	// the normal levers also move when the joint swings, so the twist limit
+	// includes the swing.
+	for i, b := range j.Bodies {
+		p := j.LocalPoints[i]
+		p.AddScaledVec(j.LeverLength, &j.LocalNormals[i])
+		b.transformMatrix.TransformIn(&p, &levers[i])
+	}
+	levers[0].AddWith(&separation)
+	if n < len(contacts) && j.distanceLimit(&levers[0], &levers[1], chordLength(j.LeverLength, j.SwingLimit+j.TwistLimit), &contacts[n]) {
+		n++
+	}
+	return n
+}
+
+// distanceLimit fills contact if the world points p0, on the first body, and
+// p1, on the second body, are further then max apart. Returns true if a
+// contact was generated.
+func (j *JointConstraint) distanceLimit(p0, p1 *glm.Vec3, max float32, contact *Contact) bool {
+	dir := p1.Sub(p0)
+	l2 := dir.Len2()
+	if l2 <= max*max || l2 < 1e-12 {
+		return false
+	}
+	l := math.Sqrt(l2)
+	dir.MulWith(1 / l)
+	*contact = Contact{
+		bodies:      j.Bodies,
+		point:       *p1,
+		normal:      dir,
+		penetration: l - max,
+		friction:    0,
+		restitution: 0,
+	}
+	return true
+}
+
+// chordLength returns the distance between the 2 ends of an arc of the given
+// radius and angle.
+func chordLength(radius, angle float32) float32 {
+	if angle >= math.Pi {
+		return 2 * radius
+	}
+	return 2 * radius * math.Sin(angle/2)
+}
@@ -0,0 +1,103 @@
+package tornago
+
+import (
+	"fmt"
+	"github.com/luxengine/lux/geo"
+	"github.com/luxengine/lux/glm"
+)
+
+// MassProperties are the mass, center of mass and inertia of a collision shape
+// of uniform density.
+type MassProperties struct {
+	// The mass of the shape.
+	Mass float32
+
+	// The center of mass in the local space of the shape.
+	CenterOfMass glm.Vec3
+
+	// The inertia tensor around the center of mass, in the local space of the
+	// shape.
+	InertiaTensor glm.Mat3
+}
+
+// DensityShape is implemented by the collision shapes that can compute their
+// mass properties. Implement it on custom shapes to use
+// RigidBody.SetMassFromDensity with them.
+type DensityShape interface {
+	CollisionShape
+
+	// MassProperties returns the mass properties of the shape with the given
+	// density.
+	MassProperties(density float32) MassProperties
+}
+
+// verify, at compile time, that these types implement DensityShape.
+var _ DensityShape = &CollisionSphere{}
+var _ DensityShape = &CollisionBox{}
+
+// MassProperties returns the mass properties of this sphere with the given
+// density.
+func (s *CollisionSphere) MassProperties(density float32) MassProperties {
+	sphere := geo.Sphere{Radius: s.radius}
+	return MassProperties{
+		Mass:          sphere.Mass(density),
+		InertiaTensor: sphere.InertiaTensor(density),
+	}
+}
+
+// MassProperties returns the mass properties of this box with the given
+// density.
+func (b *CollisionBox) MassProperties(density float32) MassProperties {
+	obb := geo.OBB{
+		Orientation: glm.Ident3(),
+		HalfExtend:  b.halfSize,
+	}
+	return MassProperties{
+		Mass:          obb.Mass(density),
+		InertiaTensor: obb.InertiaTensor(density),
+	}
+}
+
+// SetMassFromDensity sets the mass, center of mass and inertia tensor of this
+// body from its collision shape filled with the given density. The shape must
+// implement DensityShape. The origin of the shape doesn't move, the position of
+// the body moves with its center of mass.
+func (b *RigidBody) SetMassFromDensity(density float32) error {
+	shape, ok := b.shape.(DensityShape)
+	if !ok {
+		return fmt.Errorf("tornago: collision shape %T can't compute its mass properties", b.shape)
+	}
+	if density <= 0 {
+		return fmt.Errorf("tornago: density must be positive, got %f", density)
+	}
+
+	props := shape.MassProperties(density)
+	b.SetMass(props.Mass)
+	b.SetInertiaTensor(&props.InertiaTensor)
+
+	origin := b.OriginPosition()
+	b.centerOfMass = props.CenterOfMass
+	b.SetOriginPosition(&origin)
+	return nil
+}
+
+// CenterOfMass returns the center of mass of this body in the local space of
+// its collision shape. The position of the body is the position of its center
+// of mass.
+func (b *RigidBody) CenterOfMass() glm.Vec3 {
+	return b.centerOfMass
+}
+
+// OriginPosition returns the world position of the origin of the collision
+// shape of this body.
+func (b *RigidBody) OriginPosition() glm.Vec3 {
+	offset := b.orientation.Rotate(&b.centerOfMass)
+	return b.position.Sub(&offset)
+}
+
+// SetOriginPosition moves this body so that the origin of its collision shape
+// is at the given world position.
+func (b *RigidBody) SetOriginPosition(origin *glm.Vec3) {
+	offset := b.orientation.Rotate(&b.centerOfMass)
+	b.position.AddOf(origin, &offset)
+}
@@ -0,0 +1,144 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"testing"
+)
+
+// offsetSphere is a custom shape, a sphere weighted on one side so its center of
+// mass is away from its center.
+type offsetSphere struct {
+	CollisionSphere
+	offset glm.Vec3
+}
+
+func (s *offsetSphere) MassProperties(density float32) MassProperties {
+	props := s.CollisionSphere.MassProperties(density)
+	props.CenterOfMass = s.offset
+	return props
+}
+
+func TestRigidBody_SetMassFromDensity(t *testing.T) {
+	b := NewRigidBody()
+	b.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 1, Y: 2, Z: 3}))
+	if err := b.SetMassFromDensity(0.5); err != nil {
+		t.Fatal(err)
+	}
+	if m := b.Mass(); m != 24 {
+		t.Errorf("b.Mass() = %f, want 24", m)
+	}
+	it := b.InertiaTensor()
+	expected := glm.Mat3{8 * 13, 0, 0, 0, 8 * 10, 0, 0, 0, 8 * 5}
+	if !it.EqualThreshold(&expected, 1e-3) {
+		t.Errorf("b.InertiaTensor() = %v, want %v", it, expected)
+	}
+
+	b.SetCollisionShape(NewCollisionSphere(1))
+	if err := b.SetMassFromDensity(3); err != nil {
+		t.Fatal(err)
+	}
+	if m := b.Mass(); math.Abs(m-4*math.Pi) > 1e-4 {
+		t.Errorf("b.Mass() = %f, want 4pi", m)
+	}
+
+	if err := b.SetMassFromDensity(0); err == nil {
+		t.Error("b.SetMassFromDensity(0) = nil, want an error")
+	}
+	b.SetCollisionShape(&testFloor{body: b})
+	if err := b.SetMassFromDensity(1); err == nil {
+		t.Error("SetMassFromDensity with a shape without mass properties should fail")
+	}
+}
+
+func TestRigidBody_CenterOfMass(t *testing.T) {
+	b := NewRigidBody()
+	b.SetCollisionShape(&offsetSphere{
+		CollisionSphere: *NewCollisionSphere(1),
+		offset:          glm.Vec3{X: 1, Y: 0, Z: 0},
+	})
+	b.SetPosition3f(0, 5, 0)
+	q := glm.QuatRotate(math.Pi/2, &glm.Vec3{X: 0, Y: 0, Z: 1})
+	b.SetOrientationQuat(&q)
+
+	if err := b.SetMassFromDensity(1); err != nil {
+		t.Fatal(err)
+	}
+	if c := b.CenterOfMass(); c != (glm.Vec3{X: 1, Y: 0, Z: 0}) {
+		t.Errorf("b.CenterOfMass() = %v, want {1, 0, 0}", c)
+	}
+
+	// the origin doesn't move, the center of mass is rotated to +Y.
+	if p := b.OriginPosition(); !nearVec3(p, glm.Vec3{X: 0, Y: 5, Z: 0}) {
+		t.Errorf("b.OriginPosition() = %v, want {0, 5, 0}", p)
+	}
+	if p := b.Position(); !nearVec3(p, glm.Vec3{X: 0, Y: 6, Z: 0}) {
+		t.Errorf("b.Position() = %v, want {0, 6, 0}", p)
+	}
+
+	b.SetOriginPosition(&glm.Vec3{X: 2, Y: 0, Z: 0})
+	if p := b.Position(); !nearVec3(p, glm.Vec3{X: 2, Y: 1, Z: 0}) {
+		t.Errorf("b.Position() = %v, want {2, 1, 0}", p)
+	}
+}
+
+func TestRigidBody_CenterOfMassShapes(t *testing.T) {
+	box := NewCollisionBox(glm.Vec3{X: 1, Y: 1, Z: 1})
+	b := NewRigidBody()
+	b.SetMass(2)
+	b.centerOfMass = glm.Vec3{X: 1, Y: 0, Z: 0}
+	b.SetCollisionShape(box)
+	b.SetOriginPosition(&glm.Vec3{X: 0, Y: 5, Z: 0})
+	b.calculateDerivedData()
+
+	// the parallel axis theorem, 2 * diag(0, 1, 1) around the center of mass.
+	it := b.InertiaTensor()
+	expected := cuboidInertiaTensor(2, 1, 1, 1)
+	expected[4] += 2
+	expected[8] += 2
+	if !it.EqualThreshold(&expected, 1e-4) {
+		t.Errorf("b.InertiaTensor() = %v, want %v", it, expected)
+	}
+
+	// the box stays at the origin of the shape, not at the center of mass.
+	if p := box.Position(); !nearVec3(p, glm.Vec3{X: 0, Y: 5, Z: 0}) {
+		t.Errorf("box.Position() = %v, want {0, 5, 0}", p)
+	}
+	var volume BoundingSphere
+	boundingVolumeIn(box, &volume)
+	if !nearVec3(volume.center, glm.Vec3{X: 0, Y: 5, Z: 0}) {
+		t.Errorf("bounding volume center = %v, want {0, 5, 0}", volume.center)
+	}
+
+	s := NewRigidBody()
+	sphere := NewCollisionSphere(1)
+	s.SetCollisionShape(sphere)
+	s.SetPosition3f(0, 6.5, 0)
+	s.calculateDerivedData()
+	contacts := make([]Contact, 1)
+	if n := sphereAndBox(sphere, box, contacts); n != 1 {
+		t.Fatalf("sphereAndBox = %d, want 1", n)
+	}
+	if p := contacts[0].penetration; math.Abs(p-0.5) > 1e-5 {
+		t.Errorf("penetration = %f, want 0.5", p)
+	}
+
+	// the sphere is offset the same way.
+	b.SetCollisionShape(sphere)
+	if p := sphere.Position(); !nearVec3(p, glm.Vec3{X: 0, Y: 5, Z: 0}) {
+		t.Errorf("sphere.Position() = %v, want {0, 5, 0}", p)
+	}
+	it = b.InertiaTensor()
+	expected = sphereInertiaTensor(2, 1)
+	expected[4] += 2
+	expected[8] += 2
+	if !it.EqualThreshold(&expected, 1e-4) {
+		t.Errorf("b.InertiaTensor() = %v, want %v", it, expected)
+	}
+}
+
+// nearVec3 returns true if a and b are less than 1e-5 apart.
+func nearVec3(a, b glm.Vec3) bool {
+	d := a.Sub(&b)
+	return d.Len() < 1e-5
+}
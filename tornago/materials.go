@@ -0,0 +1,171 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/math"
+)
+
+// CombineMode selects how the friction or restitution of 2 surfaces are
+// combined into the value used by their contact.
+type CombineMode int
+
+// The combine modes. When both surfaces use a different mode the one that
+// comes last in this list wins.
+const (
+	// CombineAverage uses the average of both values.
+	CombineAverage CombineMode = iota
+
+	// CombineMin uses the smallest value, ice stays slippery on rubber.
+	CombineMin
+
+	// CombineMultiply uses the product of both values.
+	CombineMultiply
+
+	// CombineMax uses the largest value, rubber stays grippy on ice.
+	CombineMax
+)
+
+// combine returns a and b combined with this mode.
+func (m CombineMode) combine(a, b float32) float32 {
+	switch m {
+	case CombineMin:
+		return math.Min(a, b)
+	case CombineMultiply:
+		return a * b
+	case CombineMax:
+		return math.Max(a, b)
+	}
+	return (a + b) / 2
+}
+
+// combineModes returns the mode that wins between a and b.
+func combineModes(a, b CombineMode) CombineMode {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Material describes the surface of a rigid body. Materials are shared between
+// bodies so changing a material changes every body using it. A rigid body has
+// a single collision shape and a single material, there is no material per
+// part of a shape.
+type Material struct {
+	// The name of the material, only used to identify it.
+	Name string
+
+	// The friction and restitution of the surface.
+	Friction, Restitution float32
+
+	// How the friction and restitution are combined with the other surface.
+	FrictionCombine, RestitutionCombine CombineMode
+}
+
+// NewMaterial returns a material with the given arguments that averages its
+// friction and restitution with the other surface.
+func NewMaterial(name string, friction, restitution float32) *Material {
+	var m Material
+	m.New(name, friction, restitution)
+	return &m
+}
+
+// New initialises this Material with the given arguments. This is used for
+// memory management.
+func (m *Material) New(name string, friction, restitution float32) {
+	*m = Material{
+		Name:        name,
+		Friction:    friction,
+		Restitution: restitution,
+	}
+}
+
+// materialPair is the key of the MaterialTable overrides.
+type materialPair [2]*Material
+
+// materialOverride is the friction and restitution of a pair of materials.
+type materialOverride struct {
+	friction, restitution float32
+}
+
+// MaterialTable holds the friction and restitution of specific pairs of
+// materials, overriding the combine modes. The zero value is an empty table.
+type MaterialTable struct {
+	overrides map[materialPair]materialOverride
+}
+
+// NewMaterialTable returns an empty material table.
+func NewMaterialTable() *MaterialTable {
+	return &MaterialTable{}
+}
+
+// SetPair sets the friction and restitution to use when a and b touch, the
+// order of the materials doesn't matter.
+func (t *MaterialTable) SetPair(a, b *Material, friction, restitution float32) {
+	if t.overrides == nil {
+		t.overrides = make(map[materialPair]materialOverride)
+	}
+	o := materialOverride{friction: friction, restitution: restitution}
+	t.overrides[materialPair{a, b}] = o
+	t.overrides[materialPair{b, a}] = o
+}
+
+// RemovePair removes the override of the pair a, b.
+func (t *MaterialTable) RemovePair(a, b *Material) {
+	delete(t.overrides, materialPair{a, b})
+	delete(t.overrides, materialPair{b, a})
+}
+
+// Pair returns the friction and restitution to use when a and b touch and
+// true, or false if the pair has no override.
+func (t *MaterialTable) Pair(a, b *Material) (friction, restitution float32, ok bool) {
+	o, ok := t.overrides[materialPair{a, b}]
+	return o.friction, o.restitution, ok
+}
+
+// Combine returns the friction and restitution of a contact between the given
+// bodies. It uses the override of the table if there is one and the combine
+// modes of the materials otherwise. Bodies without a material use their own
+// friction and restitution and average them. t may be nil.
+func (t *MaterialTable) Combine(b0, b1 *RigidBody) (friction, restitution float32) {
+	return combineSurfaces(t, b0, b1)
+}
+
+// surfacer is anything touching rigid bodies, rigid and soft bodies.
+type surfacer interface {
+	// surface returns the friction, restitution and combine modes.
+	surface() (friction, restitution float32, frictionCombine, restitutionCombine CombineMode)
+
+	// surfaceMaterial returns the material looked up in the MaterialTable or
+	// nil.
+	surfaceMaterial() *Material
+}
+
+// combineSurfaces does the work of MaterialTable.Combine, t may be nil. Every
+// contact uses it so that all shapes combine surfaces the same way.
+func combineSurfaces(t *MaterialTable, b0, b1 surfacer) (friction, restitution float32) {
+	m0, m1 := b0.surfaceMaterial(), b1.surfaceMaterial()
+	if t != nil && m0 != nil && m1 != nil && len(t.overrides) > 0 {
+		if f, r, ok := t.Pair(m0, m1); ok {
+			return f, r
+		}
+	}
+
+	f0, r0, fc0, rc0 := b0.surface()
+	f1, r1, fc1, rc1 := b1.surface()
+	friction = combineModes(fc0, fc1).combine(f0, f1)
+	restitution = combineModes(rc0, rc1).combine(r0, r1)
+	return
+}
+
+// surface returns the friction, restitution and combine modes of this body,
+// from its material if it has one.
+func (b *RigidBody) surface() (friction, restitution float32, frictionCombine, restitutionCombine CombineMode) {
+	if m := b.material; m != nil {
+		return m.Friction, m.Restitution, m.FrictionCombine, m.RestitutionCombine
+	}
+	return b.friction, b.restitution, CombineAverage, CombineAverage
+}
+
+// surfaceMaterial returns the material of this body.
+func (b *RigidBody) surfaceMaterial() *Material {
+	return b.material
+}
@@ -0,0 +1,113 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/glm"
+	"testing"
+)
+
+func TestCombineMode_combine(t *testing.T) {
+	tests := []struct {
+		mode     CombineMode
+		expected float32
+	}{
+		{CombineAverage, 0.525},
+		{CombineMin, 0.25},
+		{CombineMultiply, 0.2},
+		{CombineMax, 0.8},
+	}
+	for i, test := range tests {
+		if v := test.mode.combine(0.25, 0.8); v != test.expected {
+			t.Errorf("[%d] combine(0.25, 0.8) = %v, want %f", i, v, test.expected)
+		}
+	}
+
+	if m := combineModes(CombineMax, CombineMin); m != CombineMax {
+		t.Errorf("combineModes(CombineMax, CombineMin) = %d, want CombineMax", m)
+	}
+}
+
+func TestMaterialTable_Combine(t *testing.T) {
+	ice := NewMaterial("ice", 0.02, 0.1)
+	ice.FrictionCombine = CombineMin
+	rubber := NewMaterial("rubber", 1, 0.8)
+	rubber.RestitutionCombine = CombineMax
+	steel := NewMaterial("steel", 0.6, 0.3)
+
+	b0, b1 := NewRigidBody(), NewRigidBody()
+	b0.SetFriction(0.4)
+	b0.SetRestitution(0.2)
+	b1.SetFriction(0.6)
+	b1.SetRestitution(0.4)
+
+	var table *MaterialTable
+	if f, r := table.Combine(b0, b1); f != 0.5 || r != 0.3 {
+		t.Errorf("no materials: Combine = %f, %f, want 0.5, 0.3", f, r)
+	}
+
+	b0.SetMaterial(ice)
+	b1.SetMaterial(rubber)
+	if b0.Material() != ice {
+		t.Errorf("b0.Material() = %v, want ice", b0.Material())
+	}
+	if f, r := table.Combine(b0, b1); f != 0.02 || r != 0.8 {
+		t.Errorf("ice on rubber: Combine = %f, %f, want 0.02, 0.8", f, r)
+	}
+
+	table = NewMaterialTable()
+	table.SetPair(rubber, ice, 0.3, 0)
+	if f, r := table.Combine(b0, b1); f != 0.3 || r != 0 {
+		t.Errorf("override: Combine = %f, %f, want 0.3, 0", f, r)
+	}
+	b1.SetMaterial(steel)
+	if f, r := table.Combine(b0, b1); f != 0.02 || r != 0.2 {
+		t.Errorf("ice on steel: Combine = %f, %f, want 0.02, 0.2", f, r)
+	}
+
+	table.RemovePair(ice, rubber)
+	if _, _, ok := table.Pair(rubber, ice); ok {
+		t.Error("table.Pair(rubber, ice) still exists")
+	}
+}
+
+func TestWorld_Materials(t *testing.T) {
+	w := NewWorld(&NaiveBroadphase{}, ContactResolver{})
+	ice := NewMaterial("ice", 0, 0)
+	ice.FrictionCombine = CombineMin
+	rubber := NewMaterial("rubber", 1, 0)
+
+	ground := NewRigidBody()
+	ground.SetMass(0)
+	ground.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 50, Y: 1, Z: 50}))
+	ground.SetPosition3f(0, -1, 0)
+	ground.SetMaterial(ice)
+	w.AddRigidBody(ground)
+
+	box := NewRigidBody()
+	box.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}))
+	box.SetPosition3f(0, 0.49, 0)
+	box.SetVelocity3f(5, 0, 0)
+	box.SetAcceleration3f(0, -10, 0)
+	box.SetLinearDamping(1)
+	box.SetMaterial(rubber)
+	w.AddRigidBody(box)
+
+	for i := 0; i < 30; i++ {
+		w.Step(1.0 / 60)
+	}
+	if v := box.Velocity(); v.X < 4.9 {
+		t.Errorf("box.Velocity() = %v, rubber on ice should slide", v)
+	}
+
+	table := NewMaterialTable()
+	table.SetPair(ice, rubber, 1, 0)
+	w.SetMaterialTable(table)
+	if w.MaterialTable() != table {
+		t.Error("w.MaterialTable() didn't return the table")
+	}
+	for i := 0; i < 60; i++ {
+		w.Step(1.0 / 60)
+	}
+	if v := box.Velocity(); v.X > 4 {
+		t.Errorf("box.Velocity() = %v, the override should slow it down", v)
+	}
+}
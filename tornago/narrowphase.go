@@ -0,0 +1,135 @@
+package tornago
+
+import (
+	"fmt"
+	"github.com/luxengine/lux/glm"
+	"reflect"
+)
+
+// ContactGenerator generates the contacts between 2 rigid bodies whose
+// collision shapes are of the types it was registered for. It writes at most
+// len(contacts) contacts and returns how many it wrote. The friction and
+// restitution of the contacts are filled by the world afterwards.
+type ContactGenerator func(b0, b1 *RigidBody, contacts []Contact) int
+
+// shapePair is the key of the narrowphase registry.
+type shapePair [2]reflect.Type
+
+// NarrowphaseRegistry holds the contact generators for every pair of
+// collision shape types. Use it to add support for custom collision shapes.
+// Pairs that have no generator are skipped.
+type NarrowphaseRegistry struct {
+	generators  map[shapePair]ContactGenerator
+	unsupported func(b0, b1 *RigidBody)
+}
+
+// DefaultNarrowphase is the registry used by worlds that weren't given one. It
+// supports every shape of this package, register generators for your own
+// shapes here to have them collide in every world.
+var DefaultNarrowphase = NewNarrowphaseRegistry()
+
+// NewNarrowphaseRegistry returns a registry that supports every collision
+// shape of this package.
+func NewNarrowphaseRegistry() *NarrowphaseRegistry {
+	var r NarrowphaseRegistry
+	r.New()
+	return &r
+}
+
+// New initialises this NarrowphaseRegistry with the generators of this
+// package. This is used for memory management.
+func (r *NarrowphaseRegistry) New() {
+	*r = NarrowphaseRegistry{
+		generators: make(map[shapePair]ContactGenerator),
+	}
+	r.Register(&CollisionSphere{}, &CollisionSphere{}, generateSphereSphere)
+	r.Register(&CollisionSphere{}, &CollisionBox{}, generateSphereBox)
+	r.Register(&CollisionBox{}, &CollisionBox{}, generateBoxBox)
+}
+
+// Register sets the contact generator to use between shapes of the types of a
+// and b, in that order. The values of a and b are only used for their type.
+// The generator is also used for b and a, with the bodies swapped, unless that
+// order has its own generator.
+func (r *NarrowphaseRegistry) Register(a, b CollisionShape, generator ContactGenerator) {
+	r.generators[shapePair{reflect.TypeOf(a), reflect.TypeOf(b)}] = generator
+}
+
+// Unregister removes the contact generator between shapes of the types of a
+// and b, in that order.
+func (r *NarrowphaseRegistry) Unregister(a, b CollisionShape) {
+	delete(r.generators, shapePair{reflect.TypeOf(a), reflect.TypeOf(b)})
+}
+
+// Supports returns nil if there is a contact generator for shapes of the types
+// of a and b, in either order, or an error describing the missing pair.
+func (r *NarrowphaseRegistry) Supports(a, b CollisionShape) error {
+	if _, _, ok := r.lookup(a, b); ok {
+		return nil
+	}
+	return fmt.Errorf("tornago: no contact generator between %T and %T", a, b)
+}
+
+// SetUnsupportedHandler sets a function called every time a pair of bodies is
+// skipped because there is no generator for their shapes, nil to skip
+// silently.
+func (r *NarrowphaseRegistry) SetUnsupportedHandler(handler func(b0, b1 *RigidBody)) {
+	r.unsupported = handler
+}
+
+// GenerateContacts generates the contacts between the 2 bodies using the
+// generator registered for their shapes, it returns 0 if there is none.
+func (r *NarrowphaseRegistry) GenerateContacts(b0, b1 *RigidBody, contacts []Contact) int {
+	generator, swap, ok := r.lookup(b0.shape, b1.shape)
+	if !ok {
+		if r.unsupported != nil {
+			r.unsupported(b0, b1)
+		}
+		return 0
+	}
+	if swap {
+		return generator(b1, b0, contacts)
+	}
+	return generator(b0, b1, contacts)
+}
+
+// lookup returns the generator for a and b and whether it was registered for
+// b and a instead.
+func (r *NarrowphaseRegistry) lookup(a, b CollisionShape) (ContactGenerator, bool, bool) {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if g, ok := r.generators[shapePair{ta, tb}]; ok {
+		return g, false, true
+	}
+	if g, ok := r.generators[shapePair{tb, ta}]; ok {
+		return g, true, true
+	}
+	return nil, false, false
+}
+
+// NewContact returns a contact between the 2 bodies for use in custom contact
+// generators. The normal points from the second body towards the first and
+// the point should be midway between the inter-penetrating points. body1 can
+// be nil for a contact against the scenery.
+func NewContact(body0, body1 *RigidBody, point, normal *glm.Vec3, penetration float32) Contact {
+	return Contact{
+		bodies:      [2]*RigidBody{body0, body1},
+		point:       *point,
+		normal:      *normal,
+		penetration: penetration,
+	}
+}
+
+// generateSphereSphere is the ContactGenerator for 2 spheres.
+func generateSphereSphere(b0, b1 *RigidBody, contacts []Contact) int {
+	return sphereAndSphere(b0.shape.(*CollisionSphere), b1.shape.(*CollisionSphere), contacts)
+}
+
+// generateSphereBox is the ContactGenerator for a sphere and a box.
+func generateSphereBox(b0, b1 *RigidBody, contacts []Contact) int {
+	return sphereAndBox(b0.shape.(*CollisionSphere), b1.shape.(*CollisionBox), contacts)
+}
+
+// generateBoxBox is the ContactGenerator for 2 boxes.
+func generateBoxBox(b0, b1 *RigidBody, contacts []Contact) int {
+	return boxAndBox(b0.shape.(*CollisionBox), b1.shape.(*CollisionBox), contacts)
+}
@@ -0,0 +1,124 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/glm"
+	"testing"
+)
+
+// testFloor is a custom collision shape, an infinite floor at the height of
+// its body.
+type testFloor struct {
+	body *RigidBody
+}
+
+func (f *testFloor) GetBoundingVolume() *BoundingSphere {
+	s := NewBoundingSphere(&f.body.position, 1e6)
+	return &s
+}
+
+func (f *testFloor) GetInertiaTensor(*RigidBody) glm.Mat3 { return glm.Mat3{} }
+
+func (f *testFloor) RayTest(Ray, RayResult) {}
+
+// floorAndSphere is a custom ContactGenerator, the floor is always the first
+// body.
+func floorAndSphere(floor, sphere *RigidBody, contacts []Contact) int {
+	r := sphere.CollisionShape().(*CollisionSphere).Radius()
+	pen := floor.position.Y - (sphere.position.Y - r)
+	if pen < 0 {
+		return 0
+	}
+	point := sphere.position
+	point.Y -= r
+	contacts[0] = NewContact(sphere, nil, &point, &glm.Vec3{X: 0, Y: 1, Z: 0}, pen)
+	return 1
+}
+
+func TestNarrowphaseRegistry(t *testing.T) {
+	r := NewNarrowphaseRegistry()
+	sphere, box := &CollisionSphere{}, &CollisionBox{}
+	floor := &testFloor{}
+
+	if err := r.Supports(box, sphere); err != nil {
+		t.Errorf("r.Supports(box, sphere) = %v, want nil", err)
+	}
+	if err := r.Supports(floor, sphere); err == nil {
+		t.Error("r.Supports(floor, sphere) = nil, want an error")
+	}
+
+	fb := NewRigidBody()
+	fb.SetMass(0)
+	fb.SetCollisionShape(&testFloor{body: fb})
+	sb := NewRigidBody()
+	sb.SetCollisionShape(NewCollisionSphere(1))
+	sb.SetPosition3f(0, 0.5, 0)
+	sb.SetFriction(0.4)
+	fb.SetFriction(0.2)
+
+	var skipped int
+	r.SetUnsupportedHandler(func(b0, b1 *RigidBody) { skipped++ })
+	contacts := make([]Contact, 4)
+	if n := r.GenerateContacts(sb, fb, contacts); n != 0 || skipped != 1 {
+		t.Errorf("unsupported pair generated %d contacts and was skipped %d times", n, skipped)
+	}
+
+	r.Register(floor, sphere, floorAndSphere)
+	// the generator is also used with the bodies in the other order.
+	for _, pc := range []potentialContact{{bodies: [2]*RigidBody{sb, fb}}, {bodies: [2]*RigidBody{fb, sb}}} {
+		n := resolvePotentialContacts([]potentialContact{pc}, contacts, nil, r)
+		if n != 1 {
+			t.Errorf("resolvePotentialContacts = %d, want 1", n)
+			continue
+		}
+		if p := contacts[0].Penetration(); p != 0.5 {
+			t.Errorf("contacts[0].Penetration() = %f, want 0.5", p)
+		}
+		if f := contacts[0].Friction(); f != 0.3 {
+			t.Errorf("contacts[0].Friction() = %f, want 0.3", f)
+		}
+	}
+
+	r.Unregister(floor, sphere)
+	if err := r.Supports(sphere, floor); err == nil {
+		t.Error("r.Supports(sphere, floor) = nil after Unregister")
+	}
+}
+
+func TestWorld_Narrowphase(t *testing.T) {
+	w := NewWorld(&NaiveBroadphase{}, ContactResolver{})
+	if w.Narrowphase() != DefaultNarrowphase {
+		t.Error("w.Narrowphase() should default to DefaultNarrowphase")
+	}
+
+	r := NewNarrowphaseRegistry()
+	r.Register(&testFloor{}, &CollisionSphere{}, floorAndSphere)
+	w.SetNarrowphase(r)
+
+	floor := NewRigidBody()
+	floor.SetMass(0)
+	floor.SetCollisionShape(&testFloor{body: floor})
+	w.AddRigidBody(floor)
+
+	ball := NewRigidBody()
+	ball.SetCollisionShape(NewCollisionSphere(1))
+	ball.SetPosition3f(0, 3, 0)
+	ball.SetAcceleration3f(0, -10, 0)
+	w.AddRigidBody(ball)
+
+	// a box has no generator against the floor and falls through.
+	box := NewRigidBody()
+	box.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 1, Y: 1, Z: 1}))
+	box.SetPosition3f(10, 3, 0)
+	box.SetAcceleration3f(0, -10, 0)
+	w.AddRigidBody(box)
+
+	for i := 0; i < 120; i++ {
+		w.Step(1.0 / 60)
+	}
+	if p := ball.Position(); p.Y < 0.9 || p.Y > 1.1 {
+		t.Errorf("ball.Position() = %v, should rest on the floor", p)
+	}
+	if p := box.Position(); p.Y > -1 {
+		t.Errorf("box.Position() = %v, should have fallen through", p)
+	}
+}
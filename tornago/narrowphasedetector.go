@@ -22,13 +22,14 @@ func sphereAndSphere(s1, s2 *CollisionSphere, contacts []Contact) int {
 
 	point := p2
 	point.AddScaledVec(0.5, &midline)
+	friction, restitution := combineSurfaces(nil, s1.body, s2.body)
 	contacts[0] = Contact{
 		bodies:      [2]*RigidBody{s1.body, s2.body},
 		point:       point,
 		normal:      normal,
 		penetration: s1.Radius() + s2.Radius() - size,
-		friction:    (s1.body.friction + s2.body.friction) / 2,
-		restitution: (s1.body.restitution + s2.body.restitution) / 2,
+		friction:    friction,
+		restitution: restitution,
 	}
 	return 1
 }
@@ -81,7 +82,7 @@ func boxAndHalfSpace(b *CollisionBox, p *CollisionPlane, contacts []Contact) int
 
 	for x := 0; x < len(vertices); x++ {
 		// transform it.
-		vertexPos := b.body.transformMatrix.Mul3x1(&vertices[x])
+		vertexPos := b.body.shapeTransform.Mul3x1(&vertices[x])
 
 		// basically we transform the vertex to the same axis as the offset as
 		// the plane offset represent (Y axis?) and then just check if we're
@@ -113,7 +114,7 @@ func boxAndHalfSpace(b *CollisionBox, p *CollisionPlane, contacts []Contact) int
 // sphereAndBox check for collision between a sphere and a box.
 func sphereAndBox(s *CollisionSphere, b *CollisionBox, contacts []Contact) int {
 	scenter := s.Position()
-	transform := b.body.transformMatrix
+	transform := b.body.shapeTransform
 	relsCenter := transform.TransformInverse(&scenter)
 
 	closestPoint := glm.Vec3{
@@ -180,13 +181,14 @@ func sphereAndBox(s *CollisionSphere, b *CollisionBox, contacts []Contact) int {
 		pen -= math.Sqrt(dist)
 	}
 
+	friction, restitution := combineSurfaces(nil, s.body, b.body)
 	contacts[0] = Contact{
 		bodies:      [2]*RigidBody{s.body, b.body},
 		point:       closestPointWorld,
 		normal:      normal,
 		penetration: pen,
-		friction:    (s.body.friction + b.body.friction) / 2,
-		restitution: (s.body.restitution + b.body.restitution) / 2,
+		friction:    friction,
+		restitution: restitution,
 	}
 	return 1
 }
@@ -8,18 +8,18 @@ import (
 func boxAndBoxEarly(b1, b2 *CollisionBox) bool {
 	// % = cross product
 	// * = dot product
-	p1 := glm.Vec3{X: b1.body.transformMatrix[9], Y: b1.body.transformMatrix[10], Z: b1.body.transformMatrix[11]}
-	p2 := glm.Vec3{X: b2.body.transformMatrix[9], Y: b2.body.transformMatrix[10], Z: b2.body.transformMatrix[11]}
+	p1 := glm.Vec3{X: b1.body.shapeTransform[9], Y: b1.body.shapeTransform[10], Z: b1.body.shapeTransform[11]}
+	p2 := glm.Vec3{X: b2.body.shapeTransform[9], Y: b2.body.shapeTransform[10], Z: b2.body.shapeTransform[11]}
 	toCenter := p2.Sub(&p1)
 
 	// just store a copy of all these vectors
-	b10 := glm.Vec3{X: b1.body.transformMatrix[0], Y: b1.body.transformMatrix[1], Z: b1.body.transformMatrix[2]}
-	b11 := glm.Vec3{X: b1.body.transformMatrix[3], Y: b1.body.transformMatrix[4], Z: b1.body.transformMatrix[5]}
-	b12 := glm.Vec3{X: b1.body.transformMatrix[6], Y: b1.body.transformMatrix[7], Z: b1.body.transformMatrix[8]}
+	b10 := glm.Vec3{X: b1.body.shapeTransform[0], Y: b1.body.shapeTransform[1], Z: b1.body.shapeTransform[2]}
+	b11 := glm.Vec3{X: b1.body.shapeTransform[3], Y: b1.body.shapeTransform[4], Z: b1.body.shapeTransform[5]}
+	b12 := glm.Vec3{X: b1.body.shapeTransform[6], Y: b1.body.shapeTransform[7], Z: b1.body.shapeTransform[8]}
 
-	b20 := glm.Vec3{X: b2.body.transformMatrix[0], Y: b2.body.transformMatrix[1], Z: b2.body.transformMatrix[2]}
-	b21 := glm.Vec3{X: b2.body.transformMatrix[3], Y: b2.body.transformMatrix[4], Z: b2.body.transformMatrix[5]}
-	b22 := glm.Vec3{X: b2.body.transformMatrix[6], Y: b2.body.transformMatrix[7], Z: b2.body.transformMatrix[8]}
+	b20 := glm.Vec3{X: b2.body.shapeTransform[0], Y: b2.body.shapeTransform[1], Z: b2.body.shapeTransform[2]}
+	b21 := glm.Vec3{X: b2.body.shapeTransform[3], Y: b2.body.shapeTransform[4], Z: b2.body.shapeTransform[5]}
+	b22 := glm.Vec3{X: b2.body.shapeTransform[6], Y: b2.body.shapeTransform[7], Z: b2.body.shapeTransform[8]}
 
 	b10b20 := b10.Cross(&b20)
 	b10b21 := b10.Cross(&b21)
@@ -58,9 +58,9 @@ func penetrationOnAxis(b1, b2 *CollisionBox, axis, toCenter *glm.Vec3) float32 {
 }
 
 func transformToAxis(b *CollisionBox, axis *glm.Vec3) float32 {
-	b1 := glm.Vec3{X: b.body.transformMatrix[0], Y: b.body.transformMatrix[1], Z: b.body.transformMatrix[2]}
-	b2 := glm.Vec3{X: b.body.transformMatrix[3], Y: b.body.transformMatrix[4], Z: b.body.transformMatrix[5]}
-	b3 := glm.Vec3{X: b.body.transformMatrix[6], Y: b.body.transformMatrix[7], Z: b.body.transformMatrix[8]}
+	b1 := glm.Vec3{X: b.body.shapeTransform[0], Y: b.body.shapeTransform[1], Z: b.body.shapeTransform[2]}
+	b2 := glm.Vec3{X: b.body.shapeTransform[3], Y: b.body.shapeTransform[4], Z: b.body.shapeTransform[5]}
+	b3 := glm.Vec3{X: b.body.shapeTransform[6], Y: b.body.shapeTransform[7], Z: b.body.shapeTransform[8]}
 
 	return b.halfSize.X*math.Abs(axis.Dot(&b1)) +
 		b.halfSize.Y*math.Abs(axis.Dot(&b2)) +
@@ -93,7 +93,7 @@ func fillPointFaceBoxBox(one, two *CollisionBox, toCentre *glm.Vec3, contacts []
 	// We know which axis the collision is on (i.e. best),
 	// but we need to work out which of the two faces on
 	// this axis.
-	normal := glm.Vec3{X: one.body.transformMatrix[best*3], Y: one.body.transformMatrix[(best*3)+1], Z: one.body.transformMatrix[(best*3)+2]}
+	normal := glm.Vec3{X: one.body.shapeTransform[best*3], Y: one.body.shapeTransform[(best*3)+1], Z: one.body.shapeTransform[(best*3)+2]}
 	if normal.Dot(toCentre) > 0 {
 		normal.Invert()
 	}
@@ -101,28 +101,29 @@ func fillPointFaceBoxBox(one, two *CollisionBox, toCentre *glm.Vec3, contacts []
 	// Work out which vertex of box two we're colliding with.
 	vertex := two.halfSize
 
-	t0 := glm.Vec3{X: two.body.transformMatrix[0], Y: two.body.transformMatrix[1], Z: two.body.transformMatrix[2]}
+	t0 := glm.Vec3{X: two.body.shapeTransform[0], Y: two.body.shapeTransform[1], Z: two.body.shapeTransform[2]}
 	if t0.Dot(&normal) < 0 {
 		vertex.X = -vertex.X
 	}
 
-	t1 := glm.Vec3{X: two.body.transformMatrix[3], Y: two.body.transformMatrix[4], Z: two.body.transformMatrix[5]}
+	t1 := glm.Vec3{X: two.body.shapeTransform[3], Y: two.body.shapeTransform[4], Z: two.body.shapeTransform[5]}
 	if t1.Dot(&normal) < 0 {
 		vertex.Y = -vertex.Y
 	}
 
-	t2 := glm.Vec3{X: two.body.transformMatrix[6], Y: two.body.transformMatrix[7], Z: two.body.transformMatrix[8]}
+	t2 := glm.Vec3{X: two.body.shapeTransform[6], Y: two.body.shapeTransform[7], Z: two.body.shapeTransform[8]}
 	if t2.Dot(&normal) < 0 {
 		vertex.Z = -vertex.Z
 	}
 
+	friction, restitution := combineSurfaces(nil, one.body, two.body)
 	contacts[0] = Contact{
 		bodies:      [2]*RigidBody{one.body, two.body},
-		point:       two.body.transformMatrix.Mul3x1(&vertex),
+		point:       two.body.shapeTransform.Mul3x1(&vertex),
 		normal:      normal,
 		penetration: pen,
-		friction:    (one.body.friction + two.body.friction) / 2,
-		restitution: (one.body.restitution + two.body.restitution) / 2,
+		friction:    friction,
+		restitution: restitution,
 	}
 }
 
@@ -132,7 +133,7 @@ func boxAndBox(b1, b2 *CollisionBox, contacts []Contact) int {
 		return 0
 	}
 
-	p1, p2 := b1.body.Position(), b2.body.Position()
+	p1, p2 := b1.Position(), b2.Position()
 	toCentre := p2.Sub(&p1)
 
 	// We start by assuming theres is no contact.
@@ -143,32 +144,32 @@ func boxAndBox(b1, b2 *CollisionBox, contacts []Contact) int {
 	// a separating axis, and keeping track of the axis with
 	// the smallest penetration otherwise.
 
-	b10 := glm.Vec3{X: b1.body.transformMatrix[0], Y: b1.body.transformMatrix[1], Z: b1.body.transformMatrix[2]}
+	b10 := glm.Vec3{X: b1.body.shapeTransform[0], Y: b1.body.shapeTransform[1], Z: b1.body.shapeTransform[2]}
 	if !tryAxis(b1, b2, b10, &toCentre, 0, &pen, &best) {
 		return 0
 	}
 
-	b11 := glm.Vec3{X: b1.body.transformMatrix[3], Y: b1.body.transformMatrix[4], Z: b1.body.transformMatrix[5]}
+	b11 := glm.Vec3{X: b1.body.shapeTransform[3], Y: b1.body.shapeTransform[4], Z: b1.body.shapeTransform[5]}
 	if !tryAxis(b1, b2, b11, &toCentre, 1, &pen, &best) {
 		return 0
 	}
 
-	b12 := glm.Vec3{X: b1.body.transformMatrix[6], Y: b1.body.transformMatrix[7], Z: b1.body.transformMatrix[8]}
+	b12 := glm.Vec3{X: b1.body.shapeTransform[6], Y: b1.body.shapeTransform[7], Z: b1.body.shapeTransform[8]}
 	if !tryAxis(b1, b2, b12, &toCentre, 2, &pen, &best) {
 		return 0
 	}
 
-	b20 := glm.Vec3{X: b2.body.transformMatrix[0], Y: b2.body.transformMatrix[1], Z: b2.body.transformMatrix[2]}
+	b20 := glm.Vec3{X: b2.body.shapeTransform[0], Y: b2.body.shapeTransform[1], Z: b2.body.shapeTransform[2]}
 	if !tryAxis(b1, b2, b20, &toCentre, 3, &pen, &best) {
 		return 0
 	}
 
-	b21 := glm.Vec3{X: b2.body.transformMatrix[3], Y: b2.body.transformMatrix[4], Z: b2.body.transformMatrix[5]}
+	b21 := glm.Vec3{X: b2.body.shapeTransform[3], Y: b2.body.shapeTransform[4], Z: b2.body.shapeTransform[5]}
 	if !tryAxis(b1, b2, b21, &toCentre, 4, &pen, &best) {
 		return 0
 	}
 
-	b22 := glm.Vec3{X: b2.body.transformMatrix[6], Y: b2.body.transformMatrix[7], Z: b2.body.transformMatrix[8]}
+	b22 := glm.Vec3{X: b2.body.shapeTransform[6], Y: b2.body.shapeTransform[7], Z: b2.body.shapeTransform[8]}
 	if !tryAxis(b1, b2, b22, &toCentre, 5, &pen, &best) {
 		return 0
 	}
@@ -237,8 +238,8 @@ func boxAndBox(b1, b2 *CollisionBox, contacts []Contact) int {
 		oneAxisIndex := best / 3
 		twoAxisIndex := best % 3
 
-		oneAxis := glm.Vec3{X: b1.body.transformMatrix[(oneAxisIndex * 3)], Y: b1.body.transformMatrix[(oneAxisIndex*3)+1], Z: b1.body.transformMatrix[(oneAxisIndex*3)+2]}
-		twoAxis := glm.Vec3{X: b2.body.transformMatrix[(twoAxisIndex * 3)], Y: b2.body.transformMatrix[(twoAxisIndex*3)+1], Z: b2.body.transformMatrix[(twoAxisIndex*3)+2]}
+		oneAxis := glm.Vec3{X: b1.body.shapeTransform[(oneAxisIndex * 3)], Y: b1.body.shapeTransform[(oneAxisIndex*3)+1], Z: b1.body.shapeTransform[(oneAxisIndex*3)+2]}
+		twoAxis := glm.Vec3{X: b2.body.shapeTransform[(twoAxisIndex * 3)], Y: b2.body.shapeTransform[(twoAxisIndex*3)+1], Z: b2.body.shapeTransform[(twoAxisIndex*3)+2]}
 		axis := oneAxis.Cross(&twoAxis)
 		axis.Normalize()
 
@@ -258,21 +259,21 @@ func boxAndBox(b1, b2 *CollisionBox, contacts []Contact) int {
 		for i := uint32(0); i < 3; i++ {
 			if i == oneAxisIndex {
 				*ptOnOneEdge.I(int(i)) = 0
-			} else if a := (glm.Vec3{X: b1.body.transformMatrix[i*3], Y: b1.body.transformMatrix[i*3+1], Z: b1.body.transformMatrix[i*3+2]}); a.Dot(&axis) > 0 {
+			} else if a := (glm.Vec3{X: b1.body.shapeTransform[i*3], Y: b1.body.shapeTransform[i*3+1], Z: b1.body.shapeTransform[i*3+2]}); a.Dot(&axis) > 0 {
 				*ptOnOneEdge.I(int(i)) = -*ptOnOneEdge.I(int(i))
 			}
 
 			if i == twoAxisIndex {
 				*ptOnTwoEdge.I(int(i)) = 0
-			} else if a := (glm.Vec3{X: b2.body.transformMatrix[i*3], Y: b2.body.transformMatrix[i*3+1], Z: b2.body.transformMatrix[i*3+2]}); a.Dot(&axis) < 0 {
+			} else if a := (glm.Vec3{X: b2.body.shapeTransform[i*3], Y: b2.body.shapeTransform[i*3+1], Z: b2.body.shapeTransform[i*3+2]}); a.Dot(&axis) < 0 {
 				*ptOnTwoEdge.I(int(i)) = -*ptOnTwoEdge.I(int(i))
 			}
 		}
 
 		// Move them into world coordinates (they are already oriented
 		// correctly, since they have been derived from the axes).
-		ptOnOneEdge = b1.body.transformMatrix.Mul3x1(&ptOnOneEdge)
-		ptOnTwoEdge = b2.body.transformMatrix.Mul3x1(&ptOnTwoEdge)
+		ptOnOneEdge = b1.body.shapeTransform.Mul3x1(&ptOnOneEdge)
+		ptOnTwoEdge = b2.body.shapeTransform.Mul3x1(&ptOnTwoEdge)
 
 		// So we have a point and a direction for the colliding edges.
 		// We need to find out point of closest approach of the two
@@ -282,13 +283,14 @@ func boxAndBox(b1, b2 *CollisionBox, contacts []Contact) int {
 			&ptOnTwoEdge, &twoAxis, *b2.halfSize.I(int(twoAxisIndex)),
 			bestSingleAxis > 2)
 
+		friction, restitution := combineSurfaces(nil, b1.body, b2.body)
 		contacts[0] = Contact{
 			bodies:      [2]*RigidBody{b1.body, b2.body},
 			point:       vertex,
 			normal:      axis,
 			penetration: pen,
-			friction:    (b1.body.friction + b2.body.friction) / 2,
-			restitution: (b1.body.restitution + b2.body.restitution) / 2,
+			friction:    friction,
+			restitution: restitution,
 		}
 		return 1
 	}
@@ -0,0 +1,443 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+)
+
+// verify, at compile time, that Octree implements MovableBroadphase.
+var _ MovableBroadphase = &Octree{}
+
+// octreeEntry is a body stored in an octree.
+type octreeEntry struct {
+	body   *RigidBody
+	volume BoundingSphere
+
+	// the node the entry is in and its index in the octree entries.
+	node  *octreeNode
+	index int
+}
+
+// octreeNode is a cube of the octree. Its entries have their center inside the
+// cube and a radius smaller than half its size, so they are all inside the
+// loose cube of twice its size. The reach of a node tightens that bound when
+// its entries are smaller than that. Nodes without entries or children are
+// removed so the tree only covers where the bodies are.
+type octreeNode struct {
+	center   glm.Vec3
+	halfSize float32
+	depth    int
+	entries  []*octreeEntry
+
+	// the largest radius of the entries of this node and its children, it
+	// can be larger than needed until the next prune.
+	reach float32
+
+	parent   *octreeNode
+	children [8]*octreeNode
+}
+
+// Octree is a loose octree broadphase. Bodies are stored in the smallest node
+// they fit in according to their size, which makes it efficient for worlds
+// with objects of very different sizes. Bodies outside of the root cube are
+// kept in the root and still collide normally.
+type Octree struct {
+	root    octreeNode
+	entries []*octreeEntry
+	bodies  map[*RigidBody]*octreeEntry
+
+	// the maximum depth of the tree, the root has depth 0.
+	maxDepth int
+}
+
+// NewOctree returns an empty octree covering the cube at center with the given
+// half size. maxDepth limits how many times the cube is subdivided.
+func NewOctree(center *glm.Vec3, halfSize float32, maxDepth int) *Octree {
+	var o Octree
+	o.New(center, halfSize, maxDepth)
+	return &o
+}
+
+// New initialises this Octree with the given arguments. This is used for memory
+// management.
+func (o *Octree) New(center *glm.Vec3, halfSize float32, maxDepth int) {
+	*o = Octree{
+		root: octreeNode{
+			center:   *center,
+			halfSize: halfSize,
+		},
+		bodies:   make(map[*RigidBody]*octreeEntry),
+		maxDepth: maxDepth,
+	}
+}
+
+// Insert adds the body with the given volume to the octree, the volume is
+// copied. Inserting a body twice moves it.
+func (o *Octree) Insert(b *RigidBody, volume *BoundingSphere) {
+	if _, ok := o.bodies[b]; ok {
+		o.Move(b, volume)
+		return
+	}
+	e := &octreeEntry{
+		body:   b,
+		volume: *volume,
+		index:  len(o.entries),
+	}
+	o.entries = append(o.entries, e)
+	o.bodies[b] = e
+	o.place(e)
+}
+
+// Remove removes the body from the octree.
+func (o *Octree) Remove(b *RigidBody) {
+	e, ok := o.bodies[b]
+	if !ok {
+		return
+	}
+	e.node.remove(e)
+	e.node.prune()
+	delete(o.bodies, b)
+
+	last := o.entries[len(o.entries)-1]
+	o.entries[e.index] = last
+	last.index = e.index
+	o.entries[len(o.entries)-1] = nil
+	o.entries = o.entries[:len(o.entries)-1]
+}
+
+// Move updates the volume of a body, it's ignored if the body wasn't inserted.
+func (o *Octree) Move(b *RigidBody, volume *BoundingSphere) {
+	e, ok := o.bodies[b]
+	if !ok {
+		return
+	}
+	shrunk := volume.radius < e.volume.radius
+	e.volume = *volume
+	if o.target(&e.volume) == e.node {
+		if shrunk {
+			e.node.prune()
+		}
+		return
+	}
+	old := e.node
+	old.remove(e)
+	o.place(e)
+	old.prune()
+}
+
+// Len returns how many bodies are in the octree.
+func (o *Octree) Len() int {
+	return len(o.entries)
+}
+
+// place adds e to the node it fits in, creating nodes as needed.
+func (o *Octree) place(e *octreeEntry) {
+	n := o.target(&e.volume)
+	n.entries = append(n.entries, e)
+	e.node = n
+}
+
+// target returns the node the volume fits in, creating nodes as needed and
+// growing the reach of the nodes on the way.
+func (o *Octree) target(volume *BoundingSphere) *octreeNode {
+	n := &o.root
+	if !n.containsPoint(&volume.center) {
+		return n
+	}
+	n.reach = math.Max(n.reach, volume.radius)
+	for n.depth < o.maxDepth && volume.radius <= n.halfSize/2 {
+		i := n.octant(&volume.center)
+		if n.children[i] == nil {
+			n.children[i] = n.child(i)
+		}
+		n = n.children[i]
+		n.reach = math.Max(n.reach, volume.radius)
+	}
+	return n
+}
+
+// GeneratePotentialContacts generates a potential contact for every pair of
+// overlapping volumes.
+func (o *Octree) GeneratePotentialContacts(contacts []potentialContact) int {
+	var cnt int
+	for _, e := range o.entries {
+		if cnt == len(contacts) {
+			return cnt
+		}
+		min, max := sphereBounds(&e.volume)
+		cnt += o.root.pairs(e, &min, &max, contacts[cnt:])
+	}
+	return cnt
+}
+
+// QuerySphere appends to dst every body whose volume overlaps the sphere and
+// returns the extended slice.
+func (o *Octree) QuerySphere(region *BoundingSphere, dst []*RigidBody) []*RigidBody {
+	min, max := sphereBounds(region)
+	return o.root.querySphere(region, &min, &max, dst)
+}
+
+// QueryAABB appends to dst every body whose volume overlaps the axis aligned
+// box between min and max and returns the extended slice.
+func (o *Octree) QueryAABB(min, max *glm.Vec3, dst []*RigidBody) []*RigidBody {
+	return o.root.queryAABB(min, max, dst)
+}
+
+// RayTest tests the ray against the shapes of the bodies whose volume it
+// crosses and reports the hits to result.
+func (o *Octree) RayTest(ray Ray, result RayResult) {
+	o.root.rayTest(&ray, result, true)
+}
+
+// containsPoint returns true if p is inside the cube of this node.
+func (n *octreeNode) containsPoint(p *glm.Vec3) bool {
+	return math.Abs(p.X-n.center.X) <= n.halfSize &&
+		math.Abs(p.Y-n.center.Y) <= n.halfSize &&
+		math.Abs(p.Z-n.center.Z) <= n.halfSize
+}
+
+// octant returns the index of the child whose cube contains p.
+func (n *octreeNode) octant(p *glm.Vec3) int {
+	var i int
+	if p.X >= n.center.X {
+		i |= 1
+	}
+	if p.Y >= n.center.Y {
+		i |= 2
+	}
+	if p.Z >= n.center.Z {
+		i |= 4
+	}
+	return i
+}
+
+// child returns a new child node for octant i.
+func (n *octreeNode) child(i int) *octreeNode {
+	h := n.halfSize / 2
+	offset := func(bit int) float32 {
+		if i&bit != 0 {
+			return h
+		}
+		return -h
+	}
+	c := glm.Vec3{X: n.center.X + offset(1), Y: n.center.Y + offset(2), Z: n.center.Z + offset(4)}
+	return &octreeNode{
+		center:   c,
+		halfSize: h,
+		depth:    n.depth + 1,
+		parent:   n,
+	}
+}
+
+// remove removes e from the entries of this node.
+func (n *octreeNode) remove(e *octreeEntry) {
+	for i, o := range n.entries {
+		if o == e {
+			last := len(n.entries) - 1
+			n.entries[i] = n.entries[last]
+			n.entries[last] = nil
+			n.entries = n.entries[:last]
+			return
+		}
+	}
+}
+
+// prune removes this node and its ancestors if they are empty and recomputes
+// the reach of the ones left, it stops at the first node that doesn't change.
+func (n *octreeNode) prune() {
+	for ; n != nil; n = n.parent {
+		var reach float32
+		for _, e := range n.entries {
+			reach = math.Max(reach, e.volume.radius)
+		}
+		removed := false
+		for i, c := range n.children {
+			if c == nil {
+				continue
+			}
+			if c.empty() {
+				n.children[i] = nil
+				removed = true
+				continue
+			}
+			reach = math.Max(reach, c.reach)
+		}
+		if !removed && reach == n.reach && !n.empty() {
+			return
+		}
+		n.reach = reach
+	}
+}
+
+// empty returns true if this node has no entries and no children.
+func (n *octreeNode) empty() bool {
+	if len(n.entries) > 0 {
+		return false
+	}
+	for _, c := range n.children {
+		if c != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// bounds returns the corners of the box that contains every entry of this
+// node and its children, it's only valid for nodes below the root.
+func (n *octreeNode) bounds() (min, max glm.Vec3) {
+	l := n.halfSize + n.reach
+	min = glm.Vec3{X: n.center.X - l, Y: n.center.Y - l, Z: n.center.Z - l}
+	max = glm.Vec3{X: n.center.X + l, Y: n.center.Y + l, Z: n.center.Z + l}
+	return
+}
+
+// overlapsBox returns true if the bounds of this node overlap the box between
+// min and max.
+func (n *octreeNode) overlapsBox(min, max *glm.Vec3) bool {
+	l := n.halfSize + n.reach
+	return min.X <= n.center.X+l && max.X >= n.center.X-l &&
+		min.Y <= n.center.Y+l && max.Y >= n.center.Y-l &&
+		min.Z <= n.center.Z+l && max.Z >= n.center.Z-l
+}
+
+// sphereBounds returns the corners of the box around the sphere.
+func sphereBounds(s *BoundingSphere) (min, max glm.Vec3) {
+	r := s.radius
+	min = glm.Vec3{X: s.center.X - r, Y: s.center.Y - r, Z: s.center.Z - r}
+	max = glm.Vec3{X: s.center.X + r, Y: s.center.Y + r, Z: s.center.Z + r}
+	return
+}
+
+// pairs writes the potential contacts between e, whose box is between min and
+// max, and the entries of this node and its children with a greater index, so
+// that every pair is only generated once.
+func (n *octreeNode) pairs(e *octreeEntry, min, max *glm.Vec3, contacts []potentialContact) int {
+	var cnt int
+	for _, o := range n.entries {
+		if o.index <= e.index || !e.volume.Overlaps(&o.volume) {
+			continue
+		}
+		if cnt == len(contacts) {
+			return cnt
+		}
+		contacts[cnt] = potentialContact{
+			bodies: [2]*RigidBody{e.body, o.body},
+		}
+		cnt++
+	}
+	for _, c := range n.children {
+		if c != nil && c.overlapsBox(min, max) {
+			cnt += c.pairs(e, min, max, contacts[cnt:])
+		}
+	}
+	return cnt
+}
+
+// querySphere appends the bodies of this node and its children that overlap
+// the sphere, whose box is between min and max, to dst.
+func (n *octreeNode) querySphere(s *BoundingSphere, min, max *glm.Vec3, dst []*RigidBody) []*RigidBody {
+	for _, e := range n.entries {
+		if e.volume.Overlaps(s) {
+			dst = append(dst, e.body)
+		}
+	}
+	for _, c := range n.children {
+		if c != nil && c.overlapsBox(min, max) {
+			dst = c.querySphere(s, min, max, dst)
+		}
+	}
+	return dst
+}
+
+// queryAABB appends the bodies of this node and its children that overlap the
+// box to dst.
+func (n *octreeNode) queryAABB(min, max *glm.Vec3, dst []*RigidBody) []*RigidBody {
+	for _, e := range n.entries {
+		if sphereOverlapsAABB(&e.volume, min, max) {
+			dst = append(dst, e.body)
+		}
+	}
+	for _, c := range n.children {
+		if c != nil && c.overlapsBox(min, max) {
+			dst = c.queryAABB(min, max, dst)
+		}
+	}
+	return dst
+}
+
+// rayTest tests the ray against the shapes of the bodies of this node and its
+// children.
+func (n *octreeNode) rayTest(ray *Ray, result RayResult, root bool) {
+	if !root {
+		min, max := n.bounds()
+		if !segmentOverlapsAABB(ray, &min, &max) {
+			return
+		}
+	}
+	for _, e := range n.entries {
+		if segmentOverlapsSphere(ray, &e.volume) {
+			e.body.shape.RayTest(*ray, result)
+		}
+	}
+	for _, c := range n.children {
+		if c != nil {
+			c.rayTest(ray, result, false)
+		}
+	}
+}
+
+// sphereOverlapsAABB returns true if the sphere overlaps the box between min
+// and max.
+func sphereOverlapsAABB(s *BoundingSphere, min, max *glm.Vec3) bool {
+	c, lo, hi := vec3Array(&s.center), vec3Array(min), vec3Array(max)
+	var d2 float32
+	for axis := range c {
+		if c[axis] < lo[axis] {
+			d2 += (lo[axis] - c[axis]) * (lo[axis] - c[axis])
+		} else if c[axis] > hi[axis] {
+			d2 += (c[axis] - hi[axis]) * (c[axis] - hi[axis])
+		}
+	}
+	return d2 <= s.radius*s.radius
+}
+
+// segmentOverlapsSphere returns true if the ray, up to its length, touches the
+// sphere.
+func segmentOverlapsSphere(ray *Ray, s *BoundingSphere) bool {
+	m := s.center.Sub(&ray.origin)
+	t := math.Clamp(m.Dot(&ray.direction), 0, ray.len)
+	m.AddScaledVec(-t, &ray.direction)
+	return m.Len2() <= s.radius*s.radius
+}
+
+// segmentOverlapsAABB returns true if the ray, up to its length, crosses the
+// box between min and max.
+func segmentOverlapsAABB(ray *Ray, min, max *glm.Vec3) bool {
+	origin, dir := vec3Array(&ray.origin), vec3Array(&ray.direction)
+	mins, maxs := vec3Array(min), vec3Array(max)
+	tmin, tmax := float32(0), ray.len
+	for axis := range origin {
+		o, d := origin[axis], dir[axis]
+		lo, hi := mins[axis], maxs[axis]
+		if math.Abs(d) < 1e-12 {
+			if o < lo || o > hi {
+				return false
+			}
+			continue
+		}
+		t1, t2 := (lo-o)/d, (hi-o)/d
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tmin, tmax = math.Max(tmin, t1), math.Min(tmax, t2)
+		if tmin > tmax {
+			return false
+		}
+	}
+	return true
+}
+
+// vec3Array returns the components of v as an array so they can be iterated.
+func vec3Array(v *glm.Vec3) [3]float32 {
+	return [3]float32{v.X, v.Y, v.Z}
+}
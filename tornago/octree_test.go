@@ -0,0 +1,155 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/glm"
+	"testing"
+)
+
+func newOctreeTestBody(x, y, z, radius float32) *RigidBody {
+	b := NewRigidBody()
+	b.SetCollisionShape(NewCollisionSphere(radius))
+	b.SetPosition3f(x, y, z)
+	b.calculateDerivedData()
+	return b
+}
+
+func TestOctree(t *testing.T) {
+	o := NewOctree(&glm.Vec3{}, 64, 6)
+	small0 := newOctreeTestBody(10, 10, 10, 1)
+	small1 := newOctreeTestBody(11, 10, 10, 1)
+	big := newOctreeTestBody(-10, 0, 0, 30)
+	outside := newOctreeTestBody(200, 0, 0, 1)
+	for _, b := range []*RigidBody{small0, small1, big, outside} {
+		o.Insert(b, b.shape.GetBoundingVolume())
+	}
+	if n := o.Len(); n != 4 {
+		t.Errorf("o.Len() = %d, want 4", n)
+	}
+	if e := o.bodies[small0]; e.node.depth != 6 {
+		t.Errorf("small body at depth %d, want 6", e.node.depth)
+	}
+	if e := o.bodies[big]; e.node.depth != 1 {
+		t.Errorf("big body at depth %d, want 1", e.node.depth)
+	}
+	if e := o.bodies[outside]; e.node != &o.root {
+		t.Error("body outside of the octree should be in the root")
+	}
+
+	contacts := make([]potentialContact, 10)
+	if n := o.GeneratePotentialContacts(contacts); n != 3 {
+		t.Errorf("o.GeneratePotentialContacts = %d, want 3: %v", n, contacts[:n])
+	}
+
+	// move the outside body on the first small one.
+	outside.SetPosition3f(10, 11.4, 10)
+	outside.calculateDerivedData()
+	o.Move(outside, outside.shape.GetBoundingVolume())
+	if n := o.GeneratePotentialContacts(contacts); n != 6 {
+		t.Errorf("o.GeneratePotentialContacts after Move = %d, want 6", n)
+	}
+
+	// contacts buffer too small.
+	if n := o.GeneratePotentialContacts(contacts[:2]); n != 2 {
+		t.Errorf("o.GeneratePotentialContacts = %d, want 2", n)
+	}
+
+	region := NewBoundingSphere(&glm.Vec3{X: 10, Y: 10, Z: 10}, 0.5)
+	if found := o.QuerySphere(&region, nil); len(found) != 4 {
+		t.Errorf("o.QuerySphere = %v, want 4 bodies", found)
+	}
+	min, max := glm.Vec3{X: 30, Y: -1, Z: -1}, glm.Vec3{X: 35, Y: 1, Z: 1}
+	if found := o.QueryAABB(&min, &max, nil); len(found) != 0 {
+		t.Errorf("o.QueryAABB = %v, want none", found)
+	}
+	min.X, max.X = -45, -39
+	if found := o.QueryAABB(&min, &max, nil); len(found) != 1 || found[0] != big {
+		t.Errorf("o.QueryAABB = %v, want the big body", found)
+	}
+
+	var result RayResultAll
+	o.RayTest(NewRayFromTo(glm.Vec3{X: 10.5, Y: 10, Z: -40}, glm.Vec3{X: 10.5, Y: 10, Z: 40}), &result)
+	if len(result.Bodies) != 3 {
+		t.Errorf("o.RayTest hit %d bodies, want 3", len(result.Bodies))
+	}
+
+	o.Remove(small0)
+	o.Remove(small0)
+	if n := o.Len(); n != 3 {
+		t.Errorf("o.Len() = %d, want 3", n)
+	}
+	if n := o.GeneratePotentialContacts(contacts); n != 3 {
+		t.Errorf("o.GeneratePotentialContacts after Remove = %d, want 3", n)
+	}
+}
+
+// countOctreeNodes returns how many nodes are below n, n included.
+func countOctreeNodes(n *octreeNode) int {
+	cnt := 1
+	for _, c := range n.children {
+		if c != nil {
+			cnt += countOctreeNodes(c)
+		}
+	}
+	return cnt
+}
+
+func TestOctree_Prune(t *testing.T) {
+	o := NewOctree(&glm.Vec3{}, 64, 6)
+	small := newOctreeTestBody(10, 10, 10, 1)
+	big := newOctreeTestBody(-10, 0, 0, 30)
+	o.Insert(small, small.shape.GetBoundingVolume())
+	o.Insert(big, big.shape.GetBoundingVolume())
+	nodes := countOctreeNodes(&o.root)
+
+	// a body streaming through the world leaves no nodes behind.
+	for x := float32(-60); x <= 60; x += 7 {
+		small.SetPosition3f(x, -x/2, 20)
+		small.calculateDerivedData()
+		o.Move(small, small.shape.GetBoundingVolume())
+	}
+	small.SetPosition3f(10, 10, 10)
+	small.calculateDerivedData()
+	o.Move(small, small.shape.GetBoundingVolume())
+	if n := countOctreeNodes(&o.root); n != nodes {
+		t.Errorf("%d nodes after moving around, want %d", n, nodes)
+	}
+
+	// the reach shrinks with the bodies.
+	o.Remove(big)
+	if o.root.reach != 1 {
+		t.Errorf("o.root.reach = %f, want 1", o.root.reach)
+	}
+	o.Remove(small)
+	if n := countOctreeNodes(&o.root); n != 1 || o.root.reach != 0 {
+		t.Errorf("%d nodes of reach %f left in an empty octree, want only the root", n, o.root.reach)
+	}
+}
+
+func TestWorld_Octree(t *testing.T) {
+	o := NewOctree(&glm.Vec3{}, 32, 5)
+	w := NewWorld(o, ContactResolver{})
+	a := newOctreeTestBody(0, 0, 0, 1)
+	b := newOctreeTestBody(5, 0, 0, 1)
+	b.SetVelocity3f(-10, 0, 0)
+	b.SetLinearDamping(1)
+	w.AddRigidBody(a)
+	w.AddRigidBody(b)
+
+	w.SetProfiling(true)
+	var collided bool
+	for i := 0; i < 30; i++ {
+		w.Step(1.0 / 60)
+		if w.Stats().Last.Contacts > 0 {
+			collided = true
+		}
+	}
+	if !collided {
+		t.Error("the bodies never collided")
+	}
+	if w.Broadphase() != o {
+		t.Error("the world replaced the octree")
+	}
+	if v := a.Velocity(); v.X >= 0 {
+		t.Errorf("a.Velocity() = %v, should have been hit", v)
+	}
+}
@@ -0,0 +1,205 @@
+package tornago
+
+import (
+	"sync"
+	"time"
+)
+
+// StreamingBroadphase is a Broadphase that can send its potential contacts
+// through a channel as it finds them. Worlds with narrowphase workers use it to
+// run the narrowphase while the broadphase is still sweeping.
+type StreamingBroadphase interface {
+	Broadphase
+
+	// StreamPotentialContacts sends the potential contacts to out, in an order
+	// that only depends on the bodies and their volumes. It must not close
+	// out.
+	StreamPotentialContacts(out chan<- potentialContact)
+}
+
+const (
+	// how many potential contacts are handed to a narrowphase worker at once.
+	narrowphaseBatchSize = 64
+)
+
+// narrowphaseBatch is a group of consecutive potential contacts of the stream
+// and the contacts a worker generated for them.
+type narrowphaseBatch struct {
+	world     *World
+	pairs     []potentialContact
+	contacts  []Contact
+	generated int
+	filtered  int
+}
+
+// narrowphasePool is the goroutines streaming the broadphase and generating
+// contacts, they are reused by every Step. They only hold the channels, the
+// world is handed over with the batches, so a dropped world can still be
+// collected.
+type narrowphasePool struct {
+	workers int
+
+	// the broadphase to stream, its pairs and the end of the stream, a
+	// potential contact without bodies.
+	broadphases chan StreamingBroadphase
+	pairs       chan potentialContact
+
+	// the batches to resolve, wg is done when they all are.
+	work chan *narrowphaseBatch
+	wg   sync.WaitGroup
+}
+
+// newNarrowphasePool starts the goroutines of a pool with the given amount of
+// workers.
+func newNarrowphasePool(workers int) *narrowphasePool {
+	p := &narrowphasePool{
+		workers:     workers,
+		broadphases: make(chan StreamingBroadphase),
+		pairs:       make(chan potentialContact, narrowphaseBatchSize),
+		work:        make(chan *narrowphaseBatch, workers),
+	}
+	go func(broadphases <-chan StreamingBroadphase, pairs chan<- potentialContact) {
+		for broadphase := range broadphases {
+			broadphase.StreamPotentialContacts(pairs)
+			pairs <- potentialContact{}
+		}
+	}(p.broadphases, p.pairs)
+	for i := 0; i < workers; i++ {
+		go func(work <-chan *narrowphaseBatch, wg *sync.WaitGroup) {
+			for b := range work {
+				b.world.resolveBatch(b)
+				wg.Done()
+			}
+		}(p.work, &p.wg)
+	}
+	return p
+}
+
+// stop ends the goroutines of the pool once they are done.
+func (p *narrowphasePool) stop() {
+	close(p.broadphases)
+	close(p.work)
+}
+
+// SetNarrowphaseWorkers sets how many goroutines generate contacts during
+// Step. With more than 1 worker and a StreamingBroadphase, such as SAP3, the
+// broadphase streams its pairs to the workers and the contacts are merged back
+// in the order of the stream, so the simulation stays deterministic. The pair
+// filter and the unsupported handler of the narrowphase are then called
+// concurrently. Other broadphases always use a single goroutine. The
+// goroutines are started by the next Step and kept until the amount of workers
+// changes, setting it back to 1 or calling Close stops them.
+func (w *World) SetNarrowphaseWorkers(workers int) {
+	if w.pool != nil && w.pool.workers != workers {
+		w.Close()
+	}
+	w.workers = workers
+}
+
+// Close stops the narrowphase goroutines of the world, a world with more than 1
+// narrowphase worker keeps them running until it's closed. The world can still
+// be stepped after, the goroutines are started again by the next Step.
+func (w *World) Close() {
+	if w.pool != nil {
+		w.pool.stop()
+		w.pool = nil
+	}
+}
+
+// NarrowphaseWorkers returns how many goroutines generate contacts during
+// Step.
+func (w *World) NarrowphaseWorkers() int {
+	if w.workers < 1 {
+		return 1
+	}
+	return w.workers
+}
+
+// collideParallel runs the broadphase, the pair filtering and the narrowphase
+// as a pipeline and writes the contacts in w.contacts. It returns how many
+// contacts were generated, there is always room for more after them. start is
+// the beginning of the current stats lap, the broadphase lap ends with the
+// stream and the narrowphase lap when the last batch is resolved.
+func (w *World) collideParallel(broadphase StreamingBroadphase, stats *StepStats, start *time.Time) int {
+	if w.pool == nil {
+		w.pool = newNarrowphasePool(w.workers)
+	}
+	p := w.pool
+	p.broadphases <- broadphase
+
+	// cut the stream in batches, their index is the order of their contacts.
+	var nb, npc int
+	b := w.batch(nb)
+	for pc := <-p.pairs; pc.bodies[0] != nil; pc = <-p.pairs {
+		b.pairs = append(b.pairs, pc)
+		npc++
+		if len(b.pairs) == narrowphaseBatchSize {
+			p.wg.Add(1)
+			p.work <- b
+			nb++
+			b = w.batch(nb)
+		}
+	}
+	if len(b.pairs) > 0 {
+		p.wg.Add(1)
+		p.work <- b
+		nb++
+	}
+	if stats != nil {
+		*start = lap(&stats.Broadphase, *start)
+	}
+	p.wg.Wait()
+
+	var total, filtered int
+	for _, b := range w.batches[:nb] {
+		total += b.generated
+		filtered += b.filtered
+	}
+	for total >= len(w.contacts) {
+		w.growContacts(0)
+		if stats != nil {
+			stats.Overflows++
+		}
+	}
+
+	var gen int
+	for _, b := range w.batches[:nb] {
+		gen += copy(w.contacts[gen:], b.contacts[:b.generated])
+	}
+
+	if stats != nil {
+		*start = lap(&stats.Narrowphase, *start)
+		stats.PotentialContacts = npc
+		stats.FilteredPairs = filtered
+	}
+	return gen
+}
+
+// batch returns the empty batch at index i, creating it if needed.
+func (w *World) batch(i int) *narrowphaseBatch {
+	if i == len(w.batches) {
+		w.batches = append(w.batches, &narrowphaseBatch{
+			world: w,
+			pairs: make([]potentialContact, 0, narrowphaseBatchSize),
+		})
+	}
+	b := w.batches[i]
+	b.pairs = b.pairs[:0]
+	b.generated = 0
+	b.filtered = 0
+	return b
+}
+
+// resolveBatch filters the pairs of the batch and generates their contacts.
+// It only reads the world so any number of batches can be resolved at once.
+func (w *World) resolveBatch(b *narrowphaseBatch) {
+	n := w.filterPairs(b.pairs)
+	b.filtered = len(b.pairs) - n
+	for {
+		b.generated = resolvePotentialContacts(b.pairs[:n], b.contacts, w.materials, w.narrowphase)
+		if b.generated < len(b.contacts) {
+			return
+		}
+		b.contacts = make([]Contact, 2*len(b.contacts)+minScratchGrowth)
+	}
+}
@@ -0,0 +1,109 @@
+package tornago
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWorld_NarrowphaseWorkers(t *testing.T) {
+	serial, serialBodies := newStepTestWorld(&SAP3{}, 150, 1)
+	parallel, parallelBodies := newStepTestWorld(&SAP3{}, 150, 4)
+	defer parallel.Close()
+	if n := parallel.NarrowphaseWorkers(); n != 4 {
+		t.Errorf("NarrowphaseWorkers() = %d, want 4", n)
+	}
+	if n := NewWorld(&SAP3{}, ContactResolver{}).NarrowphaseWorkers(); n != 1 {
+		t.Errorf("NarrowphaseWorkers() = %d, want 1", n)
+	}
+
+	// some pairs are ignored to make sure the workers filter them.
+	serial.IgnorePair(serialBodies[2], serialBodies[3])
+	parallel.IgnorePair(parallelBodies[2], parallelBodies[3])
+
+	serial.SetProfiling(true)
+	parallel.SetProfiling(true)
+	for i := 0; i < 60; i++ {
+		serial.Step(1.0 / 60)
+		parallel.Step(1.0 / 60)
+
+		s, p := serial.Stats().Last, parallel.Stats().Last
+		if s.PotentialContacts != p.PotentialContacts || s.FilteredPairs != p.FilteredPairs || s.Contacts != p.Contacts {
+			t.Fatalf("step %d: parallel stats %+v, want %+v", i, p, s)
+		}
+	}
+
+	if serial.Stats().Total.Contacts == 0 {
+		t.Error("the bodies never collided")
+	}
+	if serial.Stats().Total.FilteredPairs == 0 {
+		t.Error("the ignored pair was never filtered")
+	}
+	if total := parallel.Stats().Total; total.Broadphase == 0 || total.Narrowphase == 0 {
+		t.Errorf("parallel broadphase, narrowphase time = %v, %v, want both", total.Broadphase, total.Narrowphase)
+	}
+
+	// the contacts are merged in the order of the stream so both worlds must
+	// be exactly the same.
+	for i := range serialBodies {
+		if s, p := serialBodies[i].Position(), parallelBodies[i].Position(); s != p {
+			t.Errorf("body %d: parallel position = %v, want %v", i, p, s)
+		}
+	}
+}
+
+func TestWorld_NarrowphaseWorkersAllocs(t *testing.T) {
+	w, _ := newStepTestWorld(&SAP3{}, 150, 4)
+	defer w.Close()
+
+	// let the scratch buffers grow.
+	for i := 0; i < 10; i++ {
+		w.Step(1.0 / 60)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { w.Step(1.0 / 60) }); allocs != 0 {
+		t.Errorf("w.Step allocated %v times per run, want 0", allocs)
+	}
+
+	// changing the amount of workers replaces the goroutines.
+	w.SetNarrowphaseWorkers(2)
+	if w.pool != nil {
+		t.Error("the workers weren't stopped")
+	}
+	w.Step(1.0 / 60)
+	if w.pool == nil || w.pool.workers != 2 {
+		t.Errorf("w.pool = %v, want 2 workers", w.pool)
+	}
+}
+
+func TestWorld_Close(t *testing.T) {
+	before := runtime.NumGoroutine()
+	w, _ := newStepTestWorld(&SAP3{}, 20, 4)
+	w.Step(1.0 / 60)
+	if n := runtime.NumGoroutine(); n <= before {
+		t.Fatalf("%d goroutines while stepping with 4 workers, want more than %d", n, before)
+	}
+
+	w.Close()
+	// the goroutines take a moment to see their channels closed.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines after Close, want %d", n, before)
+	}
+
+	// a closed world can still step.
+	w.Step(1.0 / 60)
+	w.Close()
+}
+
+func BenchmarkWorld_StepParallel(b *testing.B) {
+	w, _ := newStepTestWorld(&SAP3{}, 150, 4)
+	defer w.Close()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Step(1.0 / 60)
+	}
+}
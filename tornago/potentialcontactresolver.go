@@ -1,17 +1,19 @@
 package tornago
 
-const (
-	unsupportedcollisionshape = "Unsupported collision shape. Please notify the lux team."
-)
-
 // potentialContact holds 2 rigid bodies that might be in contact.
 type potentialContact struct {
 	bodies [2]*RigidBody
 }
 
 // resolvePotentialContacts checks every potential contact and calls the
-// appropriate function to verify and generate it.
-func resolvePotentialContacts(pcontacts []potentialContact, contacts []Contact) int {
+// appropriate contact generator of narrowphase, or DefaultNarrowphase if nil,
+// to verify and generate it. The friction and restitution of the contacts are
+// combined from the surfaces of the bodies, materials may be nil.
+func resolvePotentialContacts(pcontacts []potentialContact, contacts []Contact, materials *MaterialTable, narrowphase *NarrowphaseRegistry) int {
+	if narrowphase == nil {
+		narrowphase = DefaultNarrowphase
+	}
+
 	var size int
 	for _, pc := range pcontacts {
 		if size == len(contacts) {
@@ -24,32 +26,20 @@ func resolvePotentialContacts(pcontacts []potentialContact, contacts []Contact)
 			continue
 		}
 
-		switch shape1 := pc.bodies[0].shape.(type) {
-		case *CollisionSphere:
-			switch shape2 := pc.bodies[1].shape.(type) {
-			case *CollisionSphere:
-				c := sphereAndSphere(shape1, shape2, contacts[size:])
-				size += c
-			case *CollisionBox:
-				c := sphereAndBox(shape1, shape2, contacts[size:])
-				size += c
-			default:
-				panic(unsupportedcollisionshape)
-			}
-		case *CollisionBox:
-			switch shape2 := pc.bodies[1].shape.(type) {
-			case *CollisionSphere:
-				c := sphereAndBox(shape2, shape1, contacts[size:])
-				size += c
-			case *CollisionBox:
-				c := boxAndBox(shape1, shape2, contacts[size:])
-				size += c
-			default:
-				panic(unsupportedcollisionshape)
-			}
-		default:
-			panic(unsupportedcollisionshape)
+		c := narrowphase.GenerateContacts(pc.bodies[0], pc.bodies[1], contacts[size:])
+		if c == 0 {
+			continue
+		}
+
+		// custom generators don't know about materials and the built-in
+		// ones don't know the table of the world, so the surfaces are
+		// combined again here for everyone.
+		friction, restitution := combineSurfaces(materials, pc.bodies[0], pc.bodies[1])
+		for i := size; i < size+c; i++ {
+			contacts[i].friction = friction
+			contacts[i].restitution = restitution
 		}
+		size += c
 	}
 	return size
 }
@@ -19,13 +19,13 @@ func TestResolvePotentialContact_CollisionGrouping(t *testing.T) {
 		{bodies: [2]*RigidBody{rb1, rb2}},
 	}
 	contacts := make([]Contact, 1)
-	if n := resolvePotentialContacts(pcontacts, contacts); n != 1 {
+	if n := resolvePotentialContacts(pcontacts, contacts, nil, nil); n != 1 {
 		t.Errorf("Group CollideAll & CollideAll should generate a contact. %d", n)
 	}
 
 	rb1.SetGroup(Group(-1))
 
-	if n := resolvePotentialContacts(pcontacts, contacts); n != 0 {
+	if n := resolvePotentialContacts(pcontacts, contacts, nil, nil); n != 0 {
 		t.Errorf("Group CollideAll & CollideNone should not generate a contact. %d", n)
 	}
 }
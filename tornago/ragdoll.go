@@ -0,0 +1,219 @@
+package tornago
+
+import (
+	"fmt"
+	"github.com/luxengine/lux/glm"
+)
+
+// RagdollBone describes a single bone of a ragdoll skeleton. In its own space a
+// bone starts at the origin and extends along +Y.
+type RagdollBone struct {
+	// The name of the bone, must be unique in the skeleton.
+	Name string
+
+	// The name of the parent bone, empty for the root. Parents must come
+	// before their children in the skeleton.
+	Parent string
+
+	// The length and radius of the bone, the radius must be positive.
+	Length, Radius float32
+
+	// The mass of the bone, defaults to 1.
+	Mass float32
+
+	// If true the bone is simulated with a sphere of the given radius
+	// instead of a box, good for heads and hands.
+	Sphere bool
+
+	// The joint limits, in radians, relative to the parent. 0 means no limit.
+	// See JointConstraint.
+	SwingLimit, TwistLimit float32
+}
+
+// RagdollSkeleton describes the bones of a ragdoll and how it collides.
+type RagdollSkeleton struct {
+	// The bones, parents first.
+	Bones []RagdollBone
+
+	// The collision group of every body of the ragdoll, it can't be 0. The
+	// bodies of a ragdoll never collide with bodies of this group so it
+	// shouldn't be the group of anything else, and every ragdoll should have
+	// its own group if they need to collide with each other.
+	Group uint16
+
+	// The collision mask of every body of the ragdoll, GroupAll if 0. Group is
+	// always removed from the mask.
+	Mask uint16
+}
+
+// BoneTransform is the world space transform of the start of a bone.
+type BoneTransform struct {
+	Position    glm.Vec3
+	Orientation glm.Quat
+}
+
+// Ragdoll is a set of rigid bodies linked by joints built from a skeleton.
+type Ragdoll struct {
+	bones   []RagdollBone
+	parents []int
+	bodies  []*RigidBody
+	joints  []*JointConstraint
+}
+
+// NewRagdoll builds a ragdoll from the skeleton in the given pose. pose must
+// have one transform per bone, in the same order. The ragdoll still needs to
+// be added to a world.
+func NewRagdoll(skeleton *RagdollSkeleton, pose []BoneTransform) (*Ragdoll, error) {
+	if len(pose) != len(skeleton.Bones) {
+		return nil, fmt.Errorf("tornago: ragdoll has %d bones but the pose has %d transforms", len(skeleton.Bones), len(pose))
+	}
+
+	group := skeleton.Group
+	if group == 0 {
+		return nil, fmt.Errorf("tornago: ragdoll skeleton has no collision group")
+	}
+	mask := skeleton.Mask
+	if mask == 0 {
+		mask = GroupAll
+	}
+	mask &^= group
+
+	r := Ragdoll{
+		bones:   append([]RagdollBone(nil), skeleton.Bones...),
+		parents: make([]int, len(skeleton.Bones)),
+		bodies:  make([]*RigidBody, len(skeleton.Bones)),
+	}
+
+	for i, bone := range r.bones {
+		if bone.Name == "" {
+			return nil, fmt.Errorf("tornago: ragdoll bone %d has no name", i)
+		}
+		if r.BoneIndex(bone.Name) != i {
+			return nil, fmt.Errorf("tornago: ragdoll bone %q is defined twice", bone.Name)
+		}
+		r.parents[i] = -1
+		if bone.Parent != "" {
+			p := r.BoneIndex(bone.Parent)
+			if p < 0 || p >= i {
+				return nil, fmt.Errorf("tornago: ragdoll bone %q parent %q must be defined before it", bone.Name, bone.Parent)
+			}
+			r.parents[i] = p
+		}
+		if !(bone.Radius > 0) {
+			return nil, fmt.Errorf("tornago: ragdoll bone %q radius must be positive, got %f", bone.Name, bone.Radius)
+		}
+
+		b := NewRigidBody()
+		mass := bone.Mass
+		if mass <= 0 {
+			mass = 1
+		}
+		b.SetMass(mass)
+		if bone.Sphere {
+			b.SetCollisionShape(NewCollisionSphere(bone.Radius))
+		} else {
+			b.SetCollisionShape(NewCollisionBox(glm.Vec3{X: bone.Radius, Y: bone.Length / 2, Z: bone.Radius}))
+		}
+		b.SetGroup(group)
+		b.SetMask(mask)
+		r.bodies[i] = b
+	}
+
+	r.SetPose(pose)
+
+	for i, p := range r.parents {
+		if p < 0 {
+			continue
+		}
+		dir := r.boneDirection(i)
+		j := NewJointConstraint(r.bodies[p], r.bodies[i], &pose[i].Position, &dir, r.bones[i].SwingLimit, r.bones[i].TwistLimit)
+		if r.bones[i].Length > 0 {
+			j.LeverLength = r.bones[i].Length
+		}
+		r.joints = append(r.joints, j)
+	}
+	return &r, nil
+}
+
+// AddToWorld adds every body and joint of the ragdoll to the world.
+func (r *Ragdoll) AddToWorld(w *World) {
+	for _, b := range r.bodies {
+		w.AddRigidBody(b)
+	}
+	for _, j := range r.joints {
+		w.AddConstraint(j)
+	}
+}
+
+// RemoveFromWorld removes every body and joint of the ragdoll from the world.
+func (r *Ragdoll) RemoveFromWorld(w *World) {
+	for _, j := range r.joints {
+		w.RemoveConstraint(j)
+	}
+	for _, b := range r.bodies {
+		w.RemoveRigidBody(b)
+	}
+}
+
+// BoneIndex returns the index of the bone with the given name or -1.
+func (r *Ragdoll) BoneIndex(name string) int {
+	for i := range r.bones {
+		if r.bones[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Body returns the rigid body of the bone with the given name or nil.
+func (r *Ragdoll) Body(name string) *RigidBody {
+	if i := r.BoneIndex(name); i >= 0 {
+		return r.bodies[i]
+	}
+	return nil
+}
+
+// Bodies returns the rigid bodies of the ragdoll, in the same order as the
+// skeleton bones. Do not modify the slice.
+func (r *Ragdoll) Bodies() []*RigidBody {
+	return r.bodies
+}
+
+// Joints returns the joints of the ragdoll, one per bone that has a parent.
+// Do not modify the slice.
+func (r *Ragdoll) Joints() []*JointConstraint {
+	return r.joints
+}
+
+// SetPose teleports every body to match the given bone transforms and stops
+// them. Use it to switch from an animated character to its ragdoll.
+func (r *Ragdoll) SetPose(pose []BoneTransform) {
+	for i, b := range r.bodies {
+		half := glm.Vec3{X: 0, Y: r.bones[i].Length / 2, Z: 0}
+		center := pose[i].Orientation.Rotate(&half)
+		center.AddWith(&pose[i].Position)
+
+		b.SetPositionVec3(&center)
+		b.SetOrientationQuat(&pose[i].Orientation)
+		b.SetVelocity3f(0, 0, 0)
+		b.SetRotation3f(0, 0, 0)
+		b.calculateDerivedData()
+	}
+}
+
+// Pose fills pose with the current bone transforms of the ragdoll, pose must
+// have one transform per bone. Use it to drive the skinned mesh of the
+// character.
+func (r *Ragdoll) Pose(pose []BoneTransform) {
+	for i, b := range r.bodies {
+		half := glm.Vec3{X: 0, Y: -r.bones[i].Length / 2, Z: 0}
+		pose[i].Orientation = b.Orientation()
+		pose[i].Position = pose[i].Orientation.Rotate(&half)
+		pose[i].Position.AddWith(&b.position)
+	}
+}
+
+// boneDirection returns the world direction of bone i.
+func (r *Ragdoll) boneDirection(i int) glm.Vec3 {
+	return r.bodies[i].transformMatrix.TransformDirection(&glm.Vec3{X: 0, Y: 1, Z: 0})
+}
@@ -0,0 +1,209 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"testing"
+)
+
+// testSkeleton returns a small humanoid skeleton and its rest pose, standing
+// on the origin.
+func testSkeleton() (*RagdollSkeleton, []BoneTransform) {
+	skeleton := &RagdollSkeleton{
+		Bones: []RagdollBone{
+			{Name: "pelvis", Length: 0.3, Radius: 0.15, Mass: 10},
+			{Name: "spine", Parent: "pelvis", Length: 0.5, Radius: 0.15, Mass: 15, SwingLimit: 0.5, TwistLimit: 0.3},
+			{Name: "head", Parent: "spine", Length: 0.25, Radius: 0.12, Mass: 4, Sphere: true, SwingLimit: 0.7},
+			{Name: "leftArm", Parent: "spine", Length: 0.6, Radius: 0.05, Mass: 3, SwingLimit: 1.5},
+			{Name: "rightArm", Parent: "spine", Length: 0.6, Radius: 0.05, Mass: 3, SwingLimit: 1.5},
+			{Name: "leftLeg", Parent: "pelvis", Length: 0.9, Radius: 0.07, Mass: 8, SwingLimit: 1, TwistLimit: 0.2},
+			{Name: "rightLeg", Parent: "pelvis", Length: 0.9, Radius: 0.07, Mass: 8, SwingLimit: 1, TwistLimit: 0.2},
+		},
+		Group: Group2,
+	}
+
+	qi := glm.QuatIdent()
+	down := glm.QuatRotate(math.Pi, &glm.Vec3{X: 0, Y: 0, Z: 1})
+	left := glm.QuatRotate(math.Pi/2, &glm.Vec3{X: 0, Y: 0, Z: 1})
+	right := glm.QuatRotate(-math.Pi/2, &glm.Vec3{X: 0, Y: 0, Z: 1})
+	pose := []BoneTransform{
+		{Position: glm.Vec3{X: 0, Y: 1, Z: 0}, Orientation: qi},
+		{Position: glm.Vec3{X: 0, Y: 1.3, Z: 0}, Orientation: qi},
+		{Position: glm.Vec3{X: 0, Y: 1.8, Z: 0}, Orientation: qi},
+		{Position: glm.Vec3{X: -0.2, Y: 1.7, Z: 0}, Orientation: left},
+		{Position: glm.Vec3{X: 0.2, Y: 1.7, Z: 0}, Orientation: right},
+		{Position: glm.Vec3{X: -0.1, Y: 1, Z: 0}, Orientation: down},
+		{Position: glm.Vec3{X: 0.1, Y: 1, Z: 0}, Orientation: down},
+	}
+	return skeleton, pose
+}
+
+func TestNewRagdoll(t *testing.T) {
+	skeleton, pose := testSkeleton()
+	r, err := NewRagdoll(skeleton, pose)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(r.Bodies()); n != 7 {
+		t.Errorf("len(r.Bodies()) = %d, want 7", n)
+	}
+	if n := len(r.Joints()); n != 6 {
+		t.Errorf("len(r.Joints()) = %d, want 6", n)
+	}
+
+	if _, ok := r.Body("head").shape.(*CollisionSphere); !ok {
+		t.Error("head should be a sphere")
+	}
+	if _, ok := r.Body("spine").shape.(*CollisionBox); !ok {
+		t.Error("spine should be a box")
+	}
+	if r.Body("tail") != nil {
+		t.Error("r.Body(\"tail\") should be nil")
+	}
+
+	for _, b := range r.Bodies() {
+		if b.Group() != Group2 || b.Mask()&Group2 != 0 {
+			t.Errorf("group = %x, mask = %x, ragdoll shouldn't collide with itself", b.Group(), b.Mask())
+		}
+	}
+
+	if p := r.Body("leftLeg").Position(); !p.EqualThreshold(&glm.Vec3{X: -0.1, Y: 0.55, Z: 0}, 1e-5) {
+		t.Errorf("leftLeg position = %v, want {-0.1, 0.55, 0}", p)
+	}
+
+	// the pose should round trip.
+	got := make([]BoneTransform, len(pose))
+	r.Pose(got)
+	for i := range pose {
+		if !got[i].Position.EqualThreshold(&pose[i].Position, 1e-5) || !got[i].Orientation.OrientationEqualThreshold(&pose[i].Orientation, 1e-5) {
+			t.Errorf("bone %d = %v, want %v", i, got[i], pose[i])
+		}
+	}
+
+	// the joints start satisfied.
+	contacts := make([]Contact, 3)
+	for i, j := range r.Joints() {
+		if n := j.GenerateContacts(contacts); n != 0 {
+			t.Errorf("joint %d generated %d contacts in the rest pose", i, n)
+		}
+	}
+}
+
+func TestNewRagdoll_Errors(t *testing.T) {
+	qi := glm.QuatIdent()
+	pose := []BoneTransform{{Orientation: qi}, {Orientation: qi}}
+	tests := []RagdollSkeleton{
+		{Bones: []RagdollBone{{Name: "a", Radius: 1}}, Group: Group2},                                        // 0
+		{Bones: []RagdollBone{{Name: "a", Radius: 1}, {Name: "a", Radius: 1}}, Group: Group2},                // 1
+		{Bones: []RagdollBone{{Name: "a", Radius: 1}, {Radius: 1}}, Group: Group2},                           // 2
+		{Bones: []RagdollBone{{Name: "a", Radius: 1, Parent: "b"}, {Name: "b", Radius: 1}}, Group: Group2},   // 3
+		{Bones: []RagdollBone{{Name: "a", Radius: 1}, {Name: "b", Radius: 1, Parent: "c"}}, Group: Group2},   // 4
+		{Bones: []RagdollBone{{Name: "a", Radius: 1}, {Name: "b", Radius: 1, Parent: "a"}}},                  // 5 no group.
+		{Bones: []RagdollBone{{Name: "a", Radius: 1}, {Name: "b", Parent: "a"}}, Group: Group2},              // 6
+		{Bones: []RagdollBone{{Name: "a", Radius: -1, Sphere: true}, {Name: "b", Radius: 1}}, Group: Group2}, // 7
+	}
+	for i, test := range tests {
+		if _, err := NewRagdoll(&test, pose); err == nil {
+			t.Errorf("[%d] expected an error", i)
+		}
+	}
+}
+
+func TestRagdoll_Simulate(t *testing.T) {
+	w := NewWorld(&NaiveBroadphase{}, ContactResolver{})
+	ground := NewRigidBody()
+	ground.SetMass(0)
+	ground.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 50, Y: 1, Z: 50}))
+	ground.SetPosition3f(0, -1, 0)
+	w.AddRigidBody(ground)
+
+	skeleton, pose := testSkeleton()
+	r, err := NewRagdoll(skeleton, pose)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range r.Bodies() {
+		b.SetAcceleration3f(0, -10, 0)
+	}
+	r.Body("spine").SetVelocity3f(2, 0, 1)
+	r.AddToWorld(w)
+
+	for i := 0; i < 120; i++ {
+		w.Step(1.0 / 60)
+	}
+
+	for i, j := range r.Joints() {
+		var a0, a1 glm.Vec3
+		j.Bodies[0].PointInWorldCoordinates(&j.LocalPoints[0], &a0)
+		j.Bodies[1].PointInWorldCoordinates(&j.LocalPoints[1], &a1)
+		if d := a1.Sub(&a0); d.Len() > 0.05 {
+			t.Errorf("joint %d anchors are %f apart", i, d.Len())
+		}
+	}
+
+	r.RemoveFromWorld(w)
+	if len(w.bodies) != 1 || len(w.constraints) != 0 {
+		t.Errorf("ragdoll not removed, %d bodies, %d constraints", len(w.bodies), len(w.constraints))
+	}
+}
+
+func TestJointConstraint_Limits(t *testing.T) {
+	var b0, b1 RigidBody
+	b0.New()
+	b1.New()
+	b1.SetPosition3f(0, 1, 0)
+	b0.calculateDerivedData()
+	b1.calculateDerivedData()
+
+	j := NewJointConstraint(&b0, &b1, &glm.Vec3{X: 0, Y: 0.5, Z: 0}, &glm.Vec3{X: 0, Y: 1, Z: 0}, 0.5, 0.2)
+	contacts := make([]Contact, 3)
+	if n := j.GenerateContacts(contacts); n != 0 {
+		t.Errorf("j.GenerateContacts() = %d, want 0", n)
+	}
+
+	// rotate b1 around the anchor.
+	rotate := func(angle float32, axis glm.Vec3) {
+		q := glm.QuatRotate(angle, &axis)
+		pos := q.Rotate(&glm.Vec3{X: 0, Y: 0.5, Z: 0})
+		pos.AddWith(&glm.Vec3{X: 0, Y: 0.5, Z: 0})
+		b1.SetOrientationQuat(&q)
+		b1.SetPositionVec3(&pos)
+		b1.calculateDerivedData()
+	}
+
+	// swing a little, within the limit.
+	rotate(0.3, glm.Vec3{X: 0, Y: 0, Z: 1})
+	if n := j.GenerateContacts(contacts); n != 0 {
+		t.Errorf("j.GenerateContacts() = %d, want 0", n)
+	}
+
+	// swing too much.
+	rotate(1, glm.Vec3{X: 0, Y: 0, Z: 1})
+	if n := j.GenerateContacts(contacts); n != 2 {
+		t.Errorf("j.GenerateContacts() = %d, want 2", n)
+	}
+	if n := j.GenerateContacts(contacts[:1]); n != 1 {
+		t.Errorf("j.GenerateContacts() = %d, want 1", n)
+	}
+
+	// twist too much.
+	rotate(1, glm.Vec3{X: 0, Y: 1, Z: 0})
+	if n := j.GenerateContacts(contacts); n != 1 {
+		t.Errorf("j.GenerateContacts() = %d, want 1", n)
+	}
+	if contacts[0].bodies != j.Bodies {
+		t.Error("the twist contact should involve both bodies")
+	}
+
+	// pull the bodies apart.
+	b1.SetPosition3f(0, 2, 0)
+	b1.SetOrientationQuat(&glm.Quat{W: 1})
+	b1.calculateDerivedData()
+	if n := j.GenerateContacts(contacts); n != 1 {
+		t.Errorf("j.GenerateContacts() = %d, want 1", n)
+	}
+	if contacts[0].penetration != 1 {
+		t.Errorf("contacts[0].penetration = %f, want 1", contacts[0].penetration)
+	}
+}
@@ -58,6 +58,10 @@ type RigidBody struct {
 	// force you need to go from resting to moving when resting on a surface.
 	friction float32
 
+	// material is the surface of the rigid body, if set it's used instead of
+	// friction and restitution.
+	material *Material
+
 	// collisionGroup is the filter this rigidbody has, it will only collide
 	// with other rigid bodies
 	//    if (this.filter & other.mask != 0) && (other.filter & this.mask != 0)
@@ -75,6 +79,10 @@ type RigidBody struct {
 	// TODO(hydroflame): implement fixtures.
 	shape CollisionShape
 
+	// centerOfMass is the center of mass in the local space of the shape. The
+	// body position and transform are those of the center of mass.
+	centerOfMass glm.Vec3
+
 	// Derived data. These data are all derived from other data in the struct.
 	// They have no setters or getters.
 
@@ -83,6 +91,10 @@ type RigidBody struct {
 	//getPointIn*Space functions.
 	transformMatrix glm.Mat3x4
 
+	// shapeTransform is transformMatrix moved to the origin of the collision
+	// shape, the shapes are placed with it.
+	shapeTransform glm.Mat3x4
+
 	// Holds the inverseInertiaTensor in world coordinates. This is calculated
 	// from the transformMatrix.
 	inverseInertiaTensorWorld glm.Mat3
@@ -285,6 +297,11 @@ func (b *RigidBody) SetCollisionShape(shape CollisionShape) {
 	b.SetInertiaTensor(&it)
 }
 
+// CollisionShape returns the collision shape of this rigid body.
+func (b *RigidBody) CollisionShape() CollisionShape {
+	return b.shape
+}
+
 // Restitution return the restitution of this rigid body.
 func (b *RigidBody) Restitution() float32 {
 	return b.restitution
@@ -310,6 +327,17 @@ func (b *RigidBody) Friction() float32 {
 	return b.friction
 }
 
+// SetMaterial sets the material of this rigid body. While a material is set the
+// friction and restitution of the body are ignored, nil removes it.
+func (b *RigidBody) SetMaterial(material *Material) {
+	b.material = material
+}
+
+// Material returns the material of this rigid body or nil.
+func (b *RigidBody) Material() *Material {
+	return b.material
+}
+
 // SetGroup sets the collision group of this rigid body.
 func (b *RigidBody) SetGroup(group uint16) {
 	b.collisionGroup = group
@@ -428,6 +456,8 @@ func (b *RigidBody) clearAccumulators() {
 func (b *RigidBody) calculateDerivedData() {
 	b.orientation.Normalize()
 	b.transformMatrix.SetOrientationAndPos(&b.orientation, &b.position)
+	origin := b.OriginPosition()
+	b.shapeTransform.SetOrientationAndPos(&b.orientation, &origin)
 
 	{ //so this piece of code here rotates the inverse inertia tensor according
 		// to the transform matrix and stores the result in the inverse inertia
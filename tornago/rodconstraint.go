@@ -18,6 +18,8 @@ type RodConstraintToWorld struct {
 	LocalPoint glm.Vec3
 	// the point in world coordinate that this constraint is attached to.
 	WorldPoint glm.Vec3
+	// The impulse feedback and break force of the rod.
+	ConstraintFeedback
 }
 
 // RodConstraintToBody represents a rod constraint, meaning it must always be
@@ -29,6 +31,10 @@ type RodConstraintToBody struct {
 	Bodies [2]*RigidBody
 	// the local points for each bodies.
 	LocalPoints [2]glm.Vec3
+	// If true the 2 bodies still collide with each other.
+	CollideLinked bool
+	// The impulse feedback and break force of the rod.
+	ConstraintFeedback
 }
 
 // NewRodConstraintToWorld returns a new RodConstraintToWorld with the given
@@ -57,6 +63,9 @@ func NewRodConstraintToBody(length float32, body0, body1 *RigidBody, localPoint0
 // to better control memory allocation and time spent resolving contacts.
 // Returns how many contacts we're generated.
 func (r *RodConstraintToWorld) GenerateContacts(contacts []Contact) int {
+	if r.Broken() {
+		return 0
+	}
 
 	bodyPointInWorld := r.Body.transformMatrix.Transform(&r.LocalPoint)
 	// from that point, get the direction to the world static point
@@ -80,11 +89,19 @@ func (r *RodConstraintToWorld) GenerateContacts(contacts []Contact) int {
 	return 0
 }
 
+// LinkedBodies returns the bodies of this rod.
+func (r *RodConstraintToBody) LinkedBodies() (*RigidBody, *RigidBody, bool) {
+	return r.Bodies[0], r.Bodies[1], r.CollideLinked
+}
+
 // GenerateContacts is given a slice of contacts of size at least 1. Do not
 // increase the slice of the slice. The reason that the size is limited is
 // to better control memory allocation and time spent resolving contacts.
 // Returns how many contacts we're generated.
 func (r *RodConstraintToBody) GenerateContacts(contacts []Contact) int {
+	if r.Broken() {
+		return 0
+	}
 	worldPoints := [2]glm.Vec3{
 		r.Bodies[0].transformMatrix.Transform(&r.LocalPoints[0]),
 		r.Bodies[1].transformMatrix.Transform(&r.LocalPoints[1]),
@@ -1,5 +1,15 @@
 package tornago
 
+// verify, at compile time, that SAP3 implements these interfaces.
+var _ MovableBroadphase = &SAP3{}
+var _ StreamingBroadphase = &SAP3{}
+
+// sap3Entry is a body stored in a SAP3.
+type sap3Entry struct {
+	body   *RigidBody
+	volume BoundingSphere
+}
+
 type sap3Node struct {
 	// Is this the beginning or the end of the rigid body
 	start bool
@@ -8,39 +18,79 @@ type sap3Node struct {
 	value float32
 
 	// Which body is that representing.
-	body *RigidBody
+	entry *sap3Entry
 }
 
-// SAP3 is a sweep and prune broadphase
+// SAP3 is a persistent sweep and prune broadphase. It keeps its bodies sorted
+// on all 3 axis between steps, moving bodies only need a few swaps to keep the
+// lists sorted. It can stream its potential contacts to the parallel
+// narrowphase of the world.
 type SAP3 struct {
-	axisList [3][]sapNode
+	axisList [3][]sap3Node
+	entries  map[*RigidBody]*sap3Entry
+
+	// whether the volumes changed since the lists were last sorted.
+	dirty bool
+
+	// scratch memory for the sweep.
+	active []*sap3Entry
 }
 
-// Insert inserts that node in the SAP.
+// Insert inserts that node in the SAP. Inserting a body twice moves it.
 func (s *SAP3) Insert(body *RigidBody, volume *BoundingSphere) {
-	s.insertNode(sapNode{start: true, value: volume.MinX(), body: body}, 0)
-	s.insertNode(sapNode{start: false, value: volume.MaxX(), body: body}, 0)
+	if _, ok := s.entries[body]; ok {
+		s.Move(body, volume)
+		return
+	}
+	if s.entries == nil {
+		s.entries = make(map[*RigidBody]*sap3Entry)
+	}
+	e := &sap3Entry{body: body, volume: *volume}
+	s.entries[body] = e
 
-	s.insertNode(sapNode{start: true, value: volume.MinY(), body: body}, 1)
-	s.insertNode(sapNode{start: false, value: volume.MaxY(), body: body}, 1)
+	s.insertNode(sap3Node{start: true, value: volume.MinX(), entry: e}, 0)
+	s.insertNode(sap3Node{start: false, value: volume.MaxX(), entry: e}, 0)
 
-	s.insertNode(sapNode{start: true, value: volume.MinZ(), body: body}, 2)
-	s.insertNode(sapNode{start: false, value: volume.MaxZ(), body: body}, 2)
+	s.insertNode(sap3Node{start: true, value: volume.MinY(), entry: e}, 1)
+	s.insertNode(sap3Node{start: false, value: volume.MaxY(), entry: e}, 1)
+
+	s.insertNode(sap3Node{start: true, value: volume.MinZ(), entry: e}, 2)
+	s.insertNode(sap3Node{start: false, value: volume.MaxZ(), entry: e}, 2)
 }
 
 // Remove removes this rigid body from the broadphase. It will no longer be used
 // in the simulation.
-func (s *SAP3) Remove(body *RigidBody, volume *BoundingSphere) {
+func (s *SAP3) Remove(body *RigidBody) {
+	if _, ok := s.entries[body]; !ok {
+		return
+	}
 	s.remove(body, 0)
 	s.remove(body, 1)
 	s.remove(body, 2)
+	delete(s.entries, body)
+}
+
+// Move updates the volume of a body, it's ignored if the body wasn't inserted.
+// The lists are sorted again before the next sweep.
+func (s *SAP3) Move(body *RigidBody, volume *BoundingSphere) {
+	e, ok := s.entries[body]
+	if !ok {
+		return
+	}
+	e.volume = *volume
+	s.dirty = true
+}
+
+// Len returns how many bodies are in the broadphase.
+func (s *SAP3) Len() int {
+	return len(s.entries)
 }
 
 // remove removes the given body from the specified axis list.
 func (s *SAP3) remove(body *RigidBody, axis int) {
 	var found int
 	for i := 0; i < len(s.axisList[axis]); i++ {
-		if s.axisList[axis][i].body == body {
+		if s.axisList[axis][i].entry.body == body {
 			copy(s.axisList[axis][i:], s.axisList[axis][i+1:])
 			s.axisList[axis] = s.axisList[axis][:len(s.axisList[axis])-1]
 			found++
@@ -52,18 +102,13 @@ func (s *SAP3) remove(body *RigidBody, axis int) {
 	}
 }
 
-func (s *SAP3) insertNodeAt(n sapNode, i, axis int) {
-	s.axisList[axis] = append(s.axisList[axis], sapNode{})
+func (s *SAP3) insertNodeAt(n sap3Node, i, axis int) {
+	s.axisList[axis] = append(s.axisList[axis], sap3Node{})
 	copy(s.axisList[axis][i+1:], s.axisList[axis][i:])
 	s.axisList[axis][i] = n
 }
 
-func (s *SAP3) insertNode(n sapNode, axis int) {
-	if len(s.axisList[axis]) == axis {
-		s.axisList[axis] = append(s.axisList[axis], n)
-		return
-	}
-
+func (s *SAP3) insertNode(n sap3Node, axis int) {
 	for i, x := range s.axisList[axis] {
 		if x.value > n.value {
 			s.insertNodeAt(n, i, axis)
@@ -73,23 +118,59 @@ func (s *SAP3) insertNode(n sapNode, axis int) {
 	s.axisList[axis] = append(s.axisList[axis], n)
 }
 
-// GeneratePotentialContacts generates all potential contacts with everybody
-func (s *SAP3) GeneratePotentialContacts(narrowPhaseDetector chan<- potentialContact) {
-	active := make([]*RigidBody, 0, len(s.axisList[0])/2)
+// sort updates the values of the nodes from the volumes of their body and
+// sorts the lists again. Bodies move little between steps so an insertion sort
+// is close to linear.
+func (s *SAP3) sort() {
+	if !s.dirty {
+		return
+	}
+	s.dirty = false
+	for axis := range s.axisList {
+		list := s.axisList[axis]
+		for i := range list {
+			n := &list[i]
+			c, r := vec3Array(&n.entry.volume.center), n.entry.volume.radius
+			if n.start {
+				n.value = c[axis] - r
+			} else {
+				n.value = c[axis] + r
+			}
+		}
+		for i := 1; i < len(list); i++ {
+			n := list[i]
+			j := i - 1
+			for ; j >= 0 && list[j].value > n.value; j-- {
+				list[j+1] = list[j]
+			}
+			list[j+1] = n
+		}
+	}
+}
+
+// sweep calls emit for every pair of overlapping volumes, in the order of the
+// x axis. It stops early if emit returns false.
+func (s *SAP3) sweep(emit func(pc potentialContact) bool) {
+	s.sort()
+	active := s.active[:0]
 	for _, n := range s.axisList[0] {
 
 		// if its the start of an object, check if there are any active objects
 		// spawn collisions for all of them and add it to the active list
 		if n.start {
 			for _, a := range active {
-				narrowPhaseDetector <- potentialContact{
-					bodies: [2]*RigidBody{a, n.body},
+				if !a.volume.Overlaps(&n.entry.volume) {
+					continue
+				}
+				if !emit(potentialContact{bodies: [2]*RigidBody{a.body, n.entry.body}}) {
+					s.active = active[:0]
+					return
 				}
 			}
-			active = append(active, n.body)
+			active = append(active, n.entry)
 		} else { // if its the end of one delete it from the active list
 			for i, a := range active {
-				if a == n.body {
+				if a == n.entry {
 					//remove it we found it
 					copy(active[i:], active[i+1:])
 					active = active[:len(active)-1]
@@ -99,6 +180,31 @@ func (s *SAP3) GeneratePotentialContacts(narrowPhaseDetector chan<- potentialCon
 			}
 		}
 	}
+	s.active = active[:0]
+}
+
+// GeneratePotentialContacts generates a potential contact for every pair of
+// overlapping volumes.
+func (s *SAP3) GeneratePotentialContacts(contacts []potentialContact) int {
+	var cnt int
+	s.sweep(func(pc potentialContact) bool {
+		if cnt == len(contacts) {
+			return false
+		}
+		contacts[cnt] = pc
+		cnt++
+		return true
+	})
+	return cnt
+}
+
+// StreamPotentialContacts sends a potential contact for every pair of
+// overlapping volumes to out, in a deterministic order. It doesn't close out.
+func (s *SAP3) StreamPotentialContacts(out chan<- potentialContact) {
+	s.sweep(func(pc potentialContact) bool {
+		out <- pc
+		return true
+	})
 }
 
 /*
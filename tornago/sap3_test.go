@@ -1,6 +1,7 @@
 package tornago
 
 import (
+	"github.com/luxengine/lux/glm"
 	"testing"
 )
 
@@ -10,9 +11,44 @@ func TestSap3_Remove(t *testing.T) {
 	var v BoundingSphere
 	v.radius = 3
 	sap.Insert(&b, &v)
-	sap.Remove(&b, &v)
+	sap.Remove(&b)
 	if len(sap.axisList[0]) != 0 || len(sap.axisList[1]) != 0 || len(sap.axisList[2]) != 0 {
 		t.Errorf("not zero length, %d,%d,%d", len(sap.axisList[0]), len(sap.axisList[1]), len(sap.axisList[2]))
 		t.Errorf("%v, %v, %v", sap.axisList[0], sap.axisList[1], sap.axisList[2])
 	}
 }
+
+func TestSap3_Move(t *testing.T) {
+	sap := SAP3{}
+	a := newOctreeTestBody(0, 0, 0, 1)
+	b := newOctreeTestBody(5, 0, 0, 1)
+	va := NewBoundingSphere(&glm.Vec3{X: 0, Y: 0, Z: 0}, 1)
+	vb := NewBoundingSphere(&glm.Vec3{X: 5, Y: 0, Z: 0}, 1)
+	sap.Insert(a, &va)
+	sap.Insert(b, &vb)
+
+	contacts := make([]potentialContact, 4)
+	if n := sap.GeneratePotentialContacts(contacts); n != 0 {
+		t.Errorf("GeneratePotentialContacts() = %d, want 0", n)
+	}
+
+	// the lists must be sorted again, b now starts before a.
+	vb = NewBoundingSphere(&glm.Vec3{X: -0.5, Y: 0, Z: 0}, 1)
+	sap.Move(b, &vb)
+	if n := sap.GeneratePotentialContacts(contacts); n != 1 {
+		t.Fatalf("GeneratePotentialContacts() = %d, want 1", n)
+	}
+	if contacts[0].bodies != [2]*RigidBody{b, a} {
+		t.Errorf("contacts[0] = %v, want %v", contacts[0].bodies, [2]*RigidBody{b, a})
+	}
+
+	// overlapping on x isn't enough.
+	vb = NewBoundingSphere(&glm.Vec3{X: 0, Y: 5, Z: 0}, 1)
+	sap.Insert(b, &vb)
+	if n := sap.GeneratePotentialContacts(contacts); n != 0 {
+		t.Errorf("GeneratePotentialContacts() = %d, want 0", n)
+	}
+	if n := sap.Len(); n != 2 {
+		t.Errorf("Len() = %d, want 2", n)
+	}
+}
@@ -0,0 +1,1252 @@
+package tornago
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/luxengine/lux/glm"
+	"io"
+)
+
+const (
+	// the version of the scene format written by SaveWorld.
+	sceneVersion = 1
+)
+
+// scene is the JSON description of a world. Vectors are [x, y, z] arrays,
+// quaternions are [w, x, y, z] and matrices are their 9 floats. Bodies and
+// forces are referenced by their index in their list, materials by their name.
+type scene struct {
+	Version            int                   `json:"version"`
+	Broadphase         sceneBroadphase       `json:"broadphase"`
+	NarrowphaseWorkers int                   `json:"narrowphaseWorkers,omitempty"`
+	Profiling          bool                  `json:"profiling,omitempty"`
+	Materials          []sceneMaterial       `json:"materials,omitempty"`
+	MaterialPairs      []sceneMaterialPair   `json:"materialPairs,omitempty"`
+	Bodies             []sceneBody           `json:"bodies,omitempty"`
+	IgnoredPairs       [][2]int              `json:"ignoredPairs,omitempty"`
+	Constraints        []sceneConstraint     `json:"constraints,omitempty"`
+	Forces             []sceneForce          `json:"forces,omitempty"`
+	ForceGenerators    []sceneForceGenerator `json:"forceGenerators,omitempty"`
+	ForceFields        []int                 `json:"forceFields,omitempty"`
+	SoftBodies         []sceneSoftBody       `json:"softBodies,omitempty"`
+}
+
+type sceneVec3 [3]float32
+
+func toSceneVec3(v *glm.Vec3) sceneVec3 {
+	return sceneVec3{v.X, v.Y, v.Z}
+}
+
+func (v sceneVec3) vec3() glm.Vec3 {
+	return glm.Vec3{X: v[0], Y: v[1], Z: v[2]}
+}
+
+type sceneBroadphase struct {
+	// naive, sap, sap3 or octree.
+	Type     string     `json:"type"`
+	Center   *sceneVec3 `json:"center,omitempty"`
+	HalfSize float32    `json:"halfSize,omitempty"`
+	MaxDepth int        `json:"maxDepth,omitempty"`
+}
+
+type sceneMaterial struct {
+	Name               string  `json:"name"`
+	Friction           float32 `json:"friction"`
+	Restitution        float32 `json:"restitution"`
+	FrictionCombine    string  `json:"frictionCombine,omitempty"`
+	RestitutionCombine string  `json:"restitutionCombine,omitempty"`
+}
+
+type sceneMaterialPair struct {
+	Materials   [2]string `json:"materials"`
+	Friction    float32   `json:"friction"`
+	Restitution float32   `json:"restitution"`
+}
+
+type sceneShape struct {
+	// sphere or box.
+	Type     string     `json:"type"`
+	Radius   float32    `json:"radius,omitempty"`
+	HalfSize *sceneVec3 `json:"halfSize,omitempty"`
+}
+
+type sceneBody struct {
+	Shape sceneShape `json:"shape"`
+
+	// A mass of 0 is infinite. If Density is set the mass, center of mass and
+	// inertia tensor are computed from the shape instead. If the inverse
+	// inertia tensor is missing it's computed from the shape and the mass.
+	Mass                 float32     `json:"mass"`
+	Density              float32     `json:"density,omitempty"`
+	InverseInertiaTensor *[9]float32 `json:"inverseInertiaTensor,omitempty"`
+	CenterOfMass         *sceneVec3  `json:"centerOfMass,omitempty"`
+
+	Position     sceneVec3  `json:"position"`
+	Orientation  [4]float32 `json:"orientation"`
+	Velocity     sceneVec3  `json:"velocity"`
+	Rotation     sceneVec3  `json:"rotation"`
+	Acceleration sceneVec3  `json:"acceleration"`
+
+	LinearDamping  float32 `json:"linearDamping"`
+	AngularDamping float32 `json:"angularDamping"`
+	Friction       float32 `json:"friction"`
+	Restitution    float32 `json:"restitution"`
+	Material       string  `json:"material,omitempty"`
+	Group          uint16  `json:"group"`
+	Mask           uint16  `json:"mask"`
+}
+
+// UnmarshalJSON fills the fields missing from data with the defaults of
+// NewRigidBody. The inverse inertia tensor and the center of mass have no
+// default, they come from the shape when they are missing.
+func (b *sceneBody) UnmarshalJSON(data []byte) error {
+	type plain sceneBody
+	p := plain(bodyToScene(NewRigidBody()))
+	p.InverseInertiaTensor, p.CenterOfMass = nil, nil
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*b = sceneBody(p)
+	return nil
+}
+
+type sceneConstraint struct {
+	// rodToWorld, rodToBody, stringToWorld, stringToBody, sliderToWorld or
+	// joint.
+	Type          string      `json:"type"`
+	Bodies        []int       `json:"bodies"`
+	LocalPoints   []sceneVec3 `json:"localPoints"`
+	WorldPoint    *sceneVec3  `json:"worldPoint,omitempty"`
+	Length        float32     `json:"length,omitempty"`
+	MinLength     float32     `json:"minLength,omitempty"`
+	MaxLength     float32     `json:"maxLength,omitempty"`
+	Restitution   float32     `json:"restitution,omitempty"`
+	CollideLinked bool        `json:"collideLinked,omitempty"`
+	LocalAxes     []sceneVec3 `json:"localAxes,omitempty"`
+	LocalNormals  []sceneVec3 `json:"localNormals,omitempty"`
+	SwingLimit    float32     `json:"swingLimit,omitempty"`
+	TwistLimit    float32     `json:"twistLimit,omitempty"`
+	LeverLength   float32     `json:"leverLength,omitempty"`
+	BreakForce    float32     `json:"breakForce,omitempty"`
+	Broken        bool        `json:"broken,omitempty"`
+}
+
+// UnmarshalJSON fills the fields missing from data with their defaults.
+func (c *sceneConstraint) UnmarshalJSON(data []byte) error {
+	type plain sceneConstraint
+	p := plain{LeverLength: 1}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*c = sceneConstraint(p)
+	return nil
+}
+
+// sceneForce is a force generator or a force field. Only the fields of its type
+// are set.
+type sceneForce struct {
+	// gravity, spring, buoyancy, attractionSphere, attractionCylinder,
+	// explosion, wind, aerodynamics or vortex.
+	Type string `json:"type"`
+
+	Vector     *sceneVec3 `json:"vector,omitempty"`
+	Center     *sceneVec3 `json:"center,omitempty"`
+	Base       *sceneVec3 `json:"base,omitempty"`
+	Axis       *sceneVec3 `json:"axis,omitempty"`
+	LocalPoint *sceneVec3 `json:"localPoint,omitempty"`
+	OtherPoint *sceneVec3 `json:"otherPoint,omitempty"`
+	Other      int        `json:"other,omitempty"`
+
+	Force         float32 `json:"force,omitempty"`
+	Radius        float32 `json:"radius,omitempty"`
+	Height        float32 `json:"height,omitempty"`
+	MaxDepth      float32 `json:"maxDepth,omitempty"`
+	Volume        float32 `json:"volume,omitempty"`
+	Density       float32 `json:"density,omitempty"`
+	Stiffness     float32 `json:"stiffness,omitempty"`
+	RestLength    float32 `json:"restLength,omitempty"`
+	Impulse       float32 `json:"impulse,omitempty"`
+	Duration      float32 `json:"duration,omitempty"`
+	Coefficient   float32 `json:"coefficient,omitempty"`
+	Turbulence    float32 `json:"turbulence,omitempty"`
+	Frequency     float32 `json:"frequency,omitempty"`
+	LinearDrag    float32 `json:"linearDrag,omitempty"`
+	QuadraticDrag float32 `json:"quadraticDrag,omitempty"`
+	Lift          float32 `json:"lift,omitempty"`
+	Tangential    float32 `json:"tangential,omitempty"`
+	Inward        float32 `json:"inward,omitempty"`
+	Falloff       string  `json:"falloff,omitempty"`
+	Occluded      bool    `json:"occluded,omitempty"`
+
+	// The area of the wind and aerodynamics, the whole world if the radius
+	// is 0.
+	AreaCenter *sceneVec3 `json:"areaCenter,omitempty"`
+	AreaRadius float32    `json:"areaRadius,omitempty"`
+
+	// The time of the explosions and wind.
+	Time    float32 `json:"time,omitempty"`
+	Started bool    `json:"started,omitempty"`
+}
+
+type sceneForceGenerator struct {
+	Body  int `json:"body"`
+	Force int `json:"force"`
+}
+
+type sceneSoftBody struct {
+	Positions           []sceneVec3   `json:"positions"`
+	Previous            []sceneVec3   `json:"previous,omitempty"`
+	ParticleInverseMass float32       `json:"particleInverseMass"`
+	Springs             []sceneSpring `json:"springs"`
+	Stiffness           [3]float32    `json:"stiffness"`
+	Pins                []scenePin    `json:"pins,omitempty"`
+	Triangles           []int         `json:"triangles,omitempty"`
+	Indices             []uint16      `json:"indices,omitempty"`
+	UVs                 [][2]float32  `json:"uvs,omitempty"`
+	VertexParticles     []int         `json:"vertexParticles,omitempty"`
+	Acceleration        sceneVec3     `json:"acceleration"`
+	Damping             float32       `json:"damping"`
+	Friction            float32       `json:"friction"`
+	Thickness           float32       `json:"thickness"`
+	Iterations          int           `json:"iterations"`
+	Group               uint16        `json:"group"`
+	Mask                uint16        `json:"mask"`
+}
+
+// UnmarshalJSON fills the fields missing from data with the defaults of the
+// soft body constructors.
+func (s *sceneSoftBody) UnmarshalJSON(data []byte) error {
+	type plain sceneSoftBody
+	var sb SoftBody
+	sb.New()
+	defaults, err := softBodyToScene(&sb, nil)
+	if err != nil {
+		return err
+	}
+	p := plain(defaults)
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*s = sceneSoftBody(p)
+	return nil
+}
+
+type sceneSpring struct {
+	Particles  [2]int  `json:"particles"`
+	RestLength float32 `json:"restLength"`
+	Kind       string  `json:"kind"`
+}
+
+type scenePin struct {
+	Particle   int        `json:"particle"`
+	Body       *int       `json:"body,omitempty"`
+	LocalPoint *sceneVec3 `json:"localPoint,omitempty"`
+	WorldPoint *sceneVec3 `json:"worldPoint,omitempty"`
+}
+
+var combineModeNames = map[CombineMode]string{
+	CombineAverage:  "average",
+	CombineMin:      "min",
+	CombineMultiply: "multiply",
+	CombineMax:      "max",
+}
+
+var falloffNames = map[Falloff]string{
+	FalloffNone:      "none",
+	FalloffLinear:    "linear",
+	FalloffQuadratic: "quadratic",
+}
+
+var springKindNames = map[SpringKind]string{
+	StructuralSpring: "structural",
+	ShearSpring:      "shear",
+	BendSpring:       "bend",
+}
+
+// parseCombineMode returns the combine mode with the given name, "" is
+// CombineAverage.
+func parseCombineMode(name string) (CombineMode, error) {
+	if name == "" {
+		return CombineAverage, nil
+	}
+	for m, n := range combineModeNames {
+		if n == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("tornago: unknown combine mode %q", name)
+}
+
+// parseFalloff returns the falloff with the given name, "" is FalloffNone.
+func parseFalloff(name string) (Falloff, error) {
+	if name == "" {
+		return FalloffNone, nil
+	}
+	for f, n := range falloffNames {
+		if n == name {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("tornago: unknown falloff %q", name)
+}
+
+// parseSpringKind returns the spring kind with the given name.
+func parseSpringKind(name string) (SpringKind, error) {
+	for k, n := range springKindNames {
+		if n == name {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("tornago: unknown spring kind %q", name)
+}
+
+//==============================================================================
+//=====================================Save=====================================
+//==============================================================================
+
+// SaveWorld writes this world to out as a JSON scene that LoadWorld reads
+// back. The scene holds the broadphase and the world settings, the materials
+// and their pairs, the rigid bodies, the ignored pairs, the constraints, the
+// force generators, the force fields and the soft bodies. The callbacks, user
+// data, pair filter, break handler and narrowphase aren't saved. Only the
+// types of this package can be saved, an error is returned for any other type.
+func (w *World) SaveWorld(out io.Writer) error {
+	s, err := w.scene()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "\t")
+	return enc.Encode(s)
+}
+
+// scene returns the description of this world.
+func (w *World) scene() (*scene, error) {
+	s := scene{
+		Version:            sceneVersion,
+		NarrowphaseWorkers: w.workers,
+		Profiling:          w.profiling,
+	}
+	switch w.dispatcher.(type) {
+	case ContactResolver, *ContactResolver:
+	default:
+		return nil, fmt.Errorf("tornago: can't save dispatcher %T", w.dispatcher)
+	}
+
+	var err error
+	if s.Broadphase, err = w.sceneBroadphase(); err != nil {
+		return nil, err
+	}
+
+	// materials are gathered from the bodies and the material table.
+	materials := make(map[*Material]string)
+	names := make(map[string]*Material)
+	addMaterial := func(m *Material) error {
+		if _, ok := materials[m]; ok {
+			return nil
+		}
+		if o, ok := names[m.Name]; ok && o != m {
+			return fmt.Errorf("tornago: 2 materials are named %q, names must be unique to be saved", m.Name)
+		}
+		materials[m] = m.Name
+		names[m.Name] = m
+		s.Materials = append(s.Materials, sceneMaterial{
+			Name:               m.Name,
+			Friction:           m.Friction,
+			Restitution:        m.Restitution,
+			FrictionCombine:    combineModeNames[m.FrictionCombine],
+			RestitutionCombine: combineModeNames[m.RestitutionCombine],
+		})
+		return nil
+	}
+
+	bodies := make(map[*RigidBody]int, len(w.bodies))
+	for i, b := range w.bodies {
+		bodies[b] = i
+		sb := bodyToScene(b)
+		switch shape := b.shape.(type) {
+		case *CollisionSphere:
+			sb.Shape = sceneShape{Type: "sphere", Radius: shape.radius}
+		case *CollisionBox:
+			hs := toSceneVec3(&shape.halfSize)
+			sb.Shape = sceneShape{Type: "box", HalfSize: &hs}
+		default:
+			return nil, fmt.Errorf("tornago: can't save collision shape %T", b.shape)
+		}
+		if b.material != nil {
+			if err := addMaterial(b.material); err != nil {
+				return nil, err
+			}
+			sb.Material = b.material.Name
+		}
+		s.Bodies = append(s.Bodies, sb)
+	}
+	index := func(b *RigidBody) (int, error) {
+		i, ok := bodies[b]
+		if !ok {
+			return 0, fmt.Errorf("tornago: can't save a reference to a body that isn't in the world")
+		}
+		return i, nil
+	}
+
+	if w.materials != nil {
+		for pair, o := range w.materials.overrides {
+			// both orders are stored, only save one.
+			if materialOrder(pair[0], pair[1]) > 0 {
+				continue
+			}
+			for _, m := range pair {
+				if err := addMaterial(m); err != nil {
+					return nil, err
+				}
+			}
+			s.MaterialPairs = append(s.MaterialPairs, sceneMaterialPair{
+				Materials:   [2]string{pair[0].Name, pair[1].Name},
+				Friction:    o.friction,
+				Restitution: o.restitution,
+			})
+		}
+		sortMaterialPairs(s.MaterialPairs)
+	}
+	sortMaterials(s.Materials)
+
+	for pair := range w.ignoredPairs {
+		i0, err0 := index(pair[0])
+		i1, err1 := index(pair[1])
+		if err0 != nil || err1 != nil {
+			return nil, fmt.Errorf("tornago: can't save an ignored pair with a body that isn't in the world")
+		}
+		if i0 < i1 {
+			s.IgnoredPairs = append(s.IgnoredPairs, [2]int{i0, i1})
+		}
+	}
+	sortPairs(s.IgnoredPairs)
+
+	for _, c := range w.constraints {
+		sc, err := constraintToScene(c, index)
+		if err != nil {
+			return nil, err
+		}
+		s.Constraints = append(s.Constraints, sc)
+	}
+
+	// generators used by several entries or fields are saved once.
+	forces := make(map[ForceGenerator]int)
+	addForce := func(f ForceGenerator) (int, error) {
+		if i, ok := forces[f]; ok {
+			return i, nil
+		}
+		sf, err := forceToScene(f, index)
+		if err != nil {
+			return 0, err
+		}
+		forces[f] = len(s.Forces)
+		s.Forces = append(s.Forces, sf)
+		return len(s.Forces) - 1, nil
+	}
+	for _, e := range w.forceGeneratorEntries {
+		b, err := index(e.body)
+		if err != nil {
+			return nil, err
+		}
+		f, err := addForce(e.forceGenerator)
+		if err != nil {
+			return nil, err
+		}
+		s.ForceGenerators = append(s.ForceGenerators, sceneForceGenerator{Body: b, Force: f})
+	}
+	for _, field := range w.forceFields {
+		f, err := addForce(field)
+		if err != nil {
+			return nil, err
+		}
+		s.ForceFields = append(s.ForceFields, f)
+	}
+
+	for _, sb := range w.softBodies {
+		ssb, err := softBodyToScene(sb, index)
+		if err != nil {
+			return nil, err
+		}
+		s.SoftBodies = append(s.SoftBodies, ssb)
+	}
+	return &s, nil
+}
+
+// sceneBroadphase returns the description of the broadphase of this world.
+func (w *World) sceneBroadphase() (sceneBroadphase, error) {
+	switch b := w.configured.(type) {
+	case *NaiveBroadphase:
+		return sceneBroadphase{Type: "naive"}, nil
+	case *SAP:
+		return sceneBroadphase{Type: "sap"}, nil
+	case *SAP3:
+		return sceneBroadphase{Type: "sap3"}, nil
+	case *Octree:
+		center := toSceneVec3(&b.root.center)
+		return sceneBroadphase{
+			Type:     "octree",
+			Center:   &center,
+			HalfSize: b.root.halfSize,
+			MaxDepth: b.maxDepth,
+		}, nil
+	}
+	return sceneBroadphase{}, fmt.Errorf("tornago: can't save broadphase %T", w.configured)
+}
+
+// bodyToScene returns the description of b without its shape and material.
+func bodyToScene(b *RigidBody) sceneBody {
+	it := [9]float32(b.inverseInertiaTensor)
+	com := toSceneVec3(&b.centerOfMass)
+	o := b.orientation
+	return sceneBody{
+		Mass:                 b.Mass(),
+		InverseInertiaTensor: &it,
+		CenterOfMass:         &com,
+		Position:             toSceneVec3(&b.position),
+		Orientation:          [4]float32{o.W, o.X, o.Y, o.Z},
+		Velocity:             toSceneVec3(&b.velocity),
+		Rotation:             toSceneVec3(&b.rotation),
+		Acceleration:         toSceneVec3(&b.acceleration),
+		LinearDamping:        b.linearDamping,
+		AngularDamping:       b.angularDamping,
+		Friction:             b.friction,
+		Restitution:          b.restitution,
+		Group:                b.collisionGroup,
+		Mask:                 b.collisionMask,
+	}
+}
+
+// constraintToScene returns the description of c, index returns the index of
+// the bodies.
+func constraintToScene(c Constraint, index func(*RigidBody) (int, error)) (sceneConstraint, error) {
+	var sc sceneConstraint
+	var bodies []*RigidBody
+	var feedback *ConstraintFeedback
+	switch c := c.(type) {
+	case *RodConstraintToWorld:
+		wp := toSceneVec3(&c.WorldPoint)
+		sc = sceneConstraint{
+			Type:        "rodToWorld",
+			LocalPoints: []sceneVec3{toSceneVec3(&c.LocalPoint)},
+			WorldPoint:  &wp,
+			Length:      c.Length,
+		}
+		bodies, feedback = []*RigidBody{c.Body}, &c.ConstraintFeedback
+	case *RodConstraintToBody:
+		sc = sceneConstraint{
+			Type:          "rodToBody",
+			LocalPoints:   []sceneVec3{toSceneVec3(&c.LocalPoints[0]), toSceneVec3(&c.LocalPoints[1])},
+			Length:        c.Length,
+			CollideLinked: c.CollideLinked,
+		}
+		bodies, feedback = c.Bodies[:], &c.ConstraintFeedback
+	case *StringToWorldConstraint:
+		wp := toSceneVec3(&c.worldPoint)
+		sc = sceneConstraint{
+			Type:        "stringToWorld",
+			LocalPoints: []sceneVec3{toSceneVec3(&c.localPoint)},
+			WorldPoint:  &wp,
+			Length:      c.length,
+			Restitution: c.restitution,
+		}
+		bodies, feedback = []*RigidBody{c.body}, &c.ConstraintFeedback
+	case *StringToBodyConstraint:
+		sc = sceneConstraint{
+			Type:          "stringToBody",
+			LocalPoints:   []sceneVec3{toSceneVec3(&c.localPoints[0]), toSceneVec3(&c.localPoints[1])},
+			Length:        c.length,
+			Restitution:   c.restitution,
+			CollideLinked: c.collide,
+		}
+		bodies, feedback = c.bodies[:], &c.ConstraintFeedback
+	case *SliderToWorldConstraint:
+		wp := toSceneVec3(&c.worldPoint)
+		sc = sceneConstraint{
+			Type:        "sliderToWorld",
+			LocalPoints: []sceneVec3{toSceneVec3(&c.localPoint)},
+			WorldPoint:  &wp,
+			MinLength:   c.minlength,
+			MaxLength:   c.maxlength,
+			Restitution: c.restitution,
+		}
+		bodies, feedback = []*RigidBody{c.body}, &c.ConstraintFeedback
+	case *JointConstraint:
+		sc = sceneConstraint{
+			Type:          "joint",
+			LocalPoints:   []sceneVec3{toSceneVec3(&c.LocalPoints[0]), toSceneVec3(&c.LocalPoints[1])},
+			LocalAxes:     []sceneVec3{toSceneVec3(&c.LocalAxes[0]), toSceneVec3(&c.LocalAxes[1])},
+			LocalNormals:  []sceneVec3{toSceneVec3(&c.LocalNormals[0]), toSceneVec3(&c.LocalNormals[1])},
+			SwingLimit:    c.SwingLimit,
+			TwistLimit:    c.TwistLimit,
+			LeverLength:   c.LeverLength,
+			CollideLinked: c.CollideLinked,
+		}
+		bodies, feedback = c.Bodies[:], &c.ConstraintFeedback
+	default:
+		return sc, fmt.Errorf("tornago: can't save constraint %T", c)
+	}
+	for _, b := range bodies {
+		i, err := index(b)
+		if err != nil {
+			return sc, err
+		}
+		sc.Bodies = append(sc.Bodies, i)
+	}
+	sc.BreakForce = feedback.breakForce
+	sc.Broken = feedback.broken
+	return sc, nil
+}
+
+// forceToScene returns the description of f, index returns the index of the
+// bodies.
+func forceToScene(f ForceGenerator, index func(*RigidBody) (int, error)) (sceneForce, error) {
+	vec := func(v *glm.Vec3) *sceneVec3 {
+		sv := toSceneVec3(v)
+		return &sv
+	}
+	switch f := f.(type) {
+	case *GravityForceGenerator:
+		return sceneForce{Type: "gravity", Vector: vec(&f.gravity)}, nil
+	case *SpringForceGenerator:
+		other, err := index(f.other)
+		if err != nil {
+			return sceneForce{}, err
+		}
+		return sceneForce{
+			Type:       "spring",
+			LocalPoint: vec(&f.localPoint),
+			Other:      other,
+			OtherPoint: vec(&f.otherPoint),
+			Stiffness:  f.k,
+			RestLength: f.l,
+		}, nil
+	case *BuoyancyForceGenerator:
+		return sceneForce{
+			Type:     "buoyancy",
+			Height:   f.Height,
+			MaxDepth: f.MaxDepth,
+			Volume:   f.Volume,
+			Density:  f.Density,
+		}, nil
+	case *AttractionSphere:
+		return sceneForce{Type: "attractionSphere", Force: f.Force, Center: vec(&f.Center)}, nil
+	case *AttractionCylinder:
+		return sceneForce{
+			Type:   "attractionCylinder",
+			Force:  f.Force,
+			Radius: f.Radius,
+			Height: f.Height,
+			Base:   vec(&f.Base),
+			Axis:   vec(&f.Direction),
+		}, nil
+	case *Explosion:
+		return sceneForce{
+			Type:     "explosion",
+			Center:   vec(&f.Center),
+			Radius:   f.Radius,
+			Impulse:  f.Impulse,
+			Duration: f.Duration,
+			Falloff:  falloffNames[f.Falloff],
+			Occluded: f.Occluders != nil,
+			Time:     f.elapsed,
+			Started:  f.started,
+		}, nil
+	case *Wind:
+		return sceneForce{
+			Type:        "wind",
+			Vector:      vec(&f.Velocity),
+			Coefficient: f.Coefficient,
+			Turbulence:  f.Turbulence,
+			Frequency:   f.Frequency,
+			AreaCenter:  vec(&f.Area.center),
+			AreaRadius:  f.Area.radius,
+			Time:        f.time,
+		}, nil
+	case *Aerodynamics:
+		return sceneForce{
+			Type:          "aerodynamics",
+			Vector:        vec(&f.FluidVelocity),
+			LinearDrag:    f.LinearDrag,
+			QuadraticDrag: f.QuadraticDrag,
+			Lift:          f.Lift,
+			Axis:          vec(&f.LiftAxis),
+			AreaCenter:    vec(&f.Area.center),
+			AreaRadius:    f.Area.radius,
+		}, nil
+	case *Vortex:
+		return sceneForce{
+			Type:       "vortex",
+			Base:       vec(&f.Base),
+			Axis:       vec(&f.Axis),
+			Radius:     f.Radius,
+			Height:     f.Height,
+			Tangential: f.Tangential,
+			Inward:     f.Inward,
+			Lift:       f.Lift,
+			Falloff:    falloffNames[f.Falloff],
+		}, nil
+	}
+	return sceneForce{}, fmt.Errorf("tornago: can't save force generator %T", f)
+}
+
+// softBodyToScene returns the description of s, index returns the index of
+// the bodies.
+func softBodyToScene(s *SoftBody, index func(*RigidBody) (int, error)) (sceneSoftBody, error) {
+	ss := sceneSoftBody{
+		ParticleInverseMass: s.particleInverseMass,
+		Stiffness:           s.stiffness,
+		Triangles:           s.triangles,
+		Indices:             s.indices,
+		VertexParticles:     s.vertexParticles,
+		Acceleration:        toSceneVec3(&s.acceleration),
+		Damping:             s.damping,
+		Friction:            s.friction,
+		Thickness:           s.thickness,
+		Iterations:          s.iterations,
+		Group:               s.collisionGroup,
+		Mask:                s.collisionMask,
+	}
+	for i := range s.positions {
+		ss.Positions = append(ss.Positions, toSceneVec3(&s.positions[i]))
+		ss.Previous = append(ss.Previous, toSceneVec3(&s.previous[i]))
+	}
+	for _, uv := range s.uvs {
+		ss.UVs = append(ss.UVs, [2]float32{uv.X, uv.Y})
+	}
+	for _, spring := range s.springs {
+		ss.Springs = append(ss.Springs, sceneSpring{
+			Particles:  spring.particles,
+			RestLength: spring.restLength,
+			Kind:       springKindNames[spring.kind],
+		})
+	}
+	for _, pin := range s.pins {
+		sp := scenePin{Particle: pin.particle}
+		if pin.body != nil {
+			i, err := index(pin.body)
+			if err != nil {
+				return ss, err
+			}
+			lp := toSceneVec3(&pin.localPoint)
+			sp.Body, sp.LocalPoint = &i, &lp
+		} else {
+			wp := toSceneVec3(&pin.worldPoint)
+			sp.WorldPoint = &wp
+		}
+		ss.Pins = append(ss.Pins, sp)
+	}
+	return ss, nil
+}
+
+// materialOrder compares 2 materials by name, the materials of a pair can have
+// the same name only if they are the same material.
+func materialOrder(a, b *Material) int {
+	switch {
+	case a.Name < b.Name:
+		return -1
+	case a.Name > b.Name:
+		return 1
+	}
+	return 0
+}
+
+// sortMaterials sorts the materials by name so that saving the same world
+// always gives the same file.
+func sortMaterials(materials []sceneMaterial) {
+	for i := 1; i < len(materials); i++ {
+		for j := i; j > 0 && materials[j].Name < materials[j-1].Name; j-- {
+			materials[j], materials[j-1] = materials[j-1], materials[j]
+		}
+	}
+}
+
+// sortMaterialPairs sorts the pairs by name so that saving the same world
+// always gives the same file.
+func sortMaterialPairs(pairs []sceneMaterialPair) {
+	less := func(a, b *sceneMaterialPair) bool {
+		if a.Materials[0] != b.Materials[0] {
+			return a.Materials[0] < b.Materials[0]
+		}
+		return a.Materials[1] < b.Materials[1]
+	}
+	for i := 1; i < len(pairs); i++ {
+		for j := i; j > 0 && less(&pairs[j], &pairs[j-1]); j-- {
+			pairs[j], pairs[j-1] = pairs[j-1], pairs[j]
+		}
+	}
+}
+
+// sortPairs sorts the pairs of indices so that saving the same world always
+// gives the same file.
+func sortPairs(pairs [][2]int) {
+	less := func(a, b [2]int) bool {
+		return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+	}
+	for i := 1; i < len(pairs); i++ {
+		for j := i; j > 0 && less(pairs[j], pairs[j-1]); j-- {
+			pairs[j], pairs[j-1] = pairs[j-1], pairs[j]
+		}
+	}
+}
+
+//==============================================================================
+//=====================================Load=====================================
+//==============================================================================
+
+// LoadWorld reads a world from the JSON scene in r, as written by
+// World.SaveWorld. The fields missing from a body get the defaults of
+// NewRigidBody. The world uses a ContactResolver as dispatcher.
+func LoadWorld(r io.Reader) (*World, error) {
+	var s scene
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("tornago: can't decode scene: %v", err)
+	}
+	if s.Version > sceneVersion {
+		return nil, fmt.Errorf("tornago: scene version %d is newer than %d", s.Version, sceneVersion)
+	}
+	return s.world()
+}
+
+// world returns a new world described by this scene.
+func (s *scene) world() (*World, error) {
+	broadphase, err := s.Broadphase.broadphase()
+	if err != nil {
+		return nil, err
+	}
+	w := NewWorld(broadphase, ContactResolver{})
+	w.SetNarrowphaseWorkers(s.NarrowphaseWorkers)
+	w.SetProfiling(s.Profiling)
+
+	materials := make(map[string]*Material, len(s.Materials))
+	for _, sm := range s.Materials {
+		m := NewMaterial(sm.Name, sm.Friction, sm.Restitution)
+		if m.FrictionCombine, err = parseCombineMode(sm.FrictionCombine); err != nil {
+			return nil, err
+		}
+		if m.RestitutionCombine, err = parseCombineMode(sm.RestitutionCombine); err != nil {
+			return nil, err
+		}
+		materials[sm.Name] = m
+	}
+	material := func(name string) (*Material, error) {
+		m, ok := materials[name]
+		if !ok {
+			return nil, fmt.Errorf("tornago: unknown material %q", name)
+		}
+		return m, nil
+	}
+
+	bodies := make([]*RigidBody, len(s.Bodies))
+	for i := range s.Bodies {
+		b, err := s.Bodies[i].body()
+		if err != nil {
+			return nil, err
+		}
+		if name := s.Bodies[i].Material; name != "" {
+			m, err := material(name)
+			if err != nil {
+				return nil, err
+			}
+			b.SetMaterial(m)
+		}
+		bodies[i] = b
+		w.AddRigidBody(b)
+	}
+	body := func(i int) (*RigidBody, error) {
+		if i < 0 || i >= len(bodies) {
+			return nil, fmt.Errorf("tornago: body index %d out of range", i)
+		}
+		return bodies[i], nil
+	}
+
+	if len(s.MaterialPairs) > 0 {
+		table := NewMaterialTable()
+		for _, p := range s.MaterialPairs {
+			m0, err := material(p.Materials[0])
+			if err != nil {
+				return nil, err
+			}
+			m1, err := material(p.Materials[1])
+			if err != nil {
+				return nil, err
+			}
+			table.SetPair(m0, m1, p.Friction, p.Restitution)
+		}
+		w.SetMaterialTable(table)
+	}
+
+	for _, p := range s.IgnoredPairs {
+		b0, err := body(p[0])
+		if err != nil {
+			return nil, err
+		}
+		b1, err := body(p[1])
+		if err != nil {
+			return nil, err
+		}
+		w.IgnorePair(b0, b1)
+	}
+
+	for i := range s.Constraints {
+		c, err := s.Constraints[i].constraint(body)
+		if err != nil {
+			return nil, err
+		}
+		w.AddConstraint(c)
+	}
+
+	forces := make([]ForceGenerator, len(s.Forces))
+	for i := range s.Forces {
+		if forces[i], err = s.Forces[i].force(body, w); err != nil {
+			return nil, err
+		}
+	}
+	force := func(i int) (ForceGenerator, error) {
+		if i < 0 || i >= len(forces) {
+			return nil, fmt.Errorf("tornago: force index %d out of range", i)
+		}
+		return forces[i], nil
+	}
+	for _, e := range s.ForceGenerators {
+		b, err := body(e.Body)
+		if err != nil {
+			return nil, err
+		}
+		f, err := force(e.Force)
+		if err != nil {
+			return nil, err
+		}
+		w.AddForceGenerator(b, f)
+	}
+	for _, i := range s.ForceFields {
+		f, err := force(i)
+		if err != nil {
+			return nil, err
+		}
+		field, ok := f.(ForceField)
+		if !ok {
+			return nil, fmt.Errorf("tornago: force %d is not a force field", i)
+		}
+		w.AddForceField(field)
+	}
+
+	for i := range s.SoftBodies {
+		sb, err := s.SoftBodies[i].softBody(body)
+		if err != nil {
+			return nil, err
+		}
+		w.AddSoftBody(sb)
+	}
+	return w, nil
+}
+
+// broadphase returns the broadphase described.
+func (s *sceneBroadphase) broadphase() (Broadphase, error) {
+	switch s.Type {
+	case "", "naive":
+		return &NaiveBroadphase{}, nil
+	case "sap":
+		return &SAP{}, nil
+	case "sap3":
+		return &SAP3{}, nil
+	case "octree":
+		var center glm.Vec3
+		if s.Center != nil {
+			center = s.Center.vec3()
+		}
+		if s.HalfSize <= 0 || s.MaxDepth < 0 {
+			return nil, fmt.Errorf("tornago: octree needs a positive half size and depth")
+		}
+		return NewOctree(&center, s.HalfSize, s.MaxDepth), nil
+	}
+	return nil, fmt.Errorf("tornago: unknown broadphase %q", s.Type)
+}
+
+// body returns the rigid body described, without its material.
+func (s *sceneBody) body() (*RigidBody, error) {
+	var shape CollisionShape
+	switch s.Shape.Type {
+	case "sphere":
+		shape = NewCollisionSphere(s.Shape.Radius)
+	case "box":
+		if s.Shape.HalfSize == nil {
+			return nil, fmt.Errorf("tornago: box shape without half size")
+		}
+		shape = NewCollisionBox(s.Shape.HalfSize.vec3())
+	default:
+		return nil, fmt.Errorf("tornago: unknown collision shape %q", s.Shape.Type)
+	}
+
+	b := NewRigidBody()
+	b.SetMass(s.Mass)
+	b.SetCollisionShape(shape)
+	b.position = s.Position.vec3()
+	b.orientation = glm.Quat{W: s.Orientation[0], Vec3: glm.Vec3{X: s.Orientation[1], Y: s.Orientation[2], Z: s.Orientation[3]}}
+	if s.Density > 0 {
+		if err := b.SetMassFromDensity(s.Density); err != nil {
+			return nil, err
+		}
+	} else {
+		if s.InverseInertiaTensor != nil {
+			b.inverseInertiaTensor = glm.Mat3(*s.InverseInertiaTensor)
+		}
+		if s.CenterOfMass != nil {
+			b.centerOfMass = s.CenterOfMass.vec3()
+		}
+	}
+	b.velocity = s.Velocity.vec3()
+	b.rotation = s.Rotation.vec3()
+	b.acceleration = s.Acceleration.vec3()
+	b.linearDamping = s.LinearDamping
+	b.angularDamping = s.AngularDamping
+	b.friction = s.Friction
+	b.restitution = s.Restitution
+	b.collisionGroup = s.Group
+	b.collisionMask = s.Mask
+	b.calculateDerivedData()
+	return b, nil
+}
+
+// constraint returns the constraint described, body returns the bodies by
+// index.
+func (s *sceneConstraint) constraint(body func(int) (*RigidBody, error)) (Constraint, error) {
+	want := 1
+	switch s.Type {
+	case "rodToBody", "stringToBody", "joint":
+		want = 2
+	}
+	if len(s.Bodies) != want || len(s.LocalPoints) != want {
+		return nil, fmt.Errorf("tornago: %s constraint needs %d bodies and local points", s.Type, want)
+	}
+	var bodies [2]*RigidBody
+	var points [2]glm.Vec3
+	for i := range s.Bodies {
+		b, err := body(s.Bodies[i])
+		if err != nil {
+			return nil, err
+		}
+		bodies[i], points[i] = b, s.LocalPoints[i].vec3()
+	}
+	var wp glm.Vec3
+	if s.WorldPoint != nil {
+		wp = s.WorldPoint.vec3()
+	}
+
+	var c BreakableConstraint
+	switch s.Type {
+	case "rodToWorld":
+		c = NewRodConstraintToWorld(s.Length, bodies[0], &points[0], &wp)
+	case "rodToBody":
+		r := NewRodConstraintToBody(s.Length, bodies[0], bodies[1], &points[0], &points[1])
+		r.CollideLinked = s.CollideLinked
+		c = r
+	case "stringToWorld":
+		c = NewStringToWorldConstraint(wp, points[0], bodies[0], s.Length, s.Restitution).(*StringToWorldConstraint)
+	case "stringToBody":
+		str := NewStringToBodyConstraint(points, bodies, s.Length, s.Restitution).(*StringToBodyConstraint)
+		str.SetCollideLinked(s.CollideLinked)
+		c = str
+	case "sliderToWorld":
+		c = NewSliderToWorldConstraint(wp, points[0], bodies[0], s.MinLength, s.MaxLength, s.Restitution)
+	case "joint":
+		if len(s.LocalAxes) != 2 || len(s.LocalNormals) != 2 {
+			return nil, fmt.Errorf("tornago: joint constraint needs 2 local axes and normals")
+		}
+		c = &JointConstraint{
+			Bodies:        bodies,
+			LocalPoints:   points,
+			LocalAxes:     [2]glm.Vec3{s.LocalAxes[0].vec3(), s.LocalAxes[1].vec3()},
+			LocalNormals:  [2]glm.Vec3{s.LocalNormals[0].vec3(), s.LocalNormals[1].vec3()},
+			SwingLimit:    s.SwingLimit,
+			TwistLimit:    s.TwistLimit,
+			LeverLength:   s.LeverLength,
+			CollideLinked: s.CollideLinked,
+		}
+	default:
+		return nil, fmt.Errorf("tornago: unknown constraint %q", s.Type)
+	}
+	f := c.Feedback()
+	f.breakForce = s.BreakForce
+	f.broken = s.Broken
+	return c, nil
+}
+
+// force returns the force generator described, body returns the bodies by
+// index and w is the world the force is loaded in.
+func (s *sceneForce) force(body func(int) (*RigidBody, error), w *World) (ForceGenerator, error) {
+	vec := func(v *sceneVec3) glm.Vec3 {
+		if v == nil {
+			return glm.Vec3{}
+		}
+		return v.vec3()
+	}
+	area := func() BoundingSphere {
+		center := vec(s.AreaCenter)
+		return NewBoundingSphere(&center, s.AreaRadius)
+	}
+	falloff, err := parseFalloff(s.Falloff)
+	if err != nil {
+		return nil, err
+	}
+
+	switch s.Type {
+	case "gravity":
+		v := vec(s.Vector)
+		return NewGravityForceGenerator(&v), nil
+	case "spring":
+		other, err := body(s.Other)
+		if err != nil {
+			return nil, err
+		}
+		lp, op := vec(s.LocalPoint), vec(s.OtherPoint)
+		spring := NewSpringForceGenerator(&lp, other, &op, s.Stiffness, s.RestLength)
+		return &spring, nil
+	case "buoyancy":
+		return NewBuoyancyForceGenerator(s.Height, s.MaxDepth, s.Volume, s.Density), nil
+	case "attractionSphere":
+		center := vec(s.Center)
+		return NewAttractionSphere(s.Force, &center), nil
+	case "attractionCylinder":
+		base, dir := vec(s.Base), vec(s.Axis)
+		return NewAttractionCylinder(s.Force, s.Radius, s.Height, &base, &dir), nil
+	case "explosion":
+		center := vec(s.Center)
+		e := NewExplosion(&center, s.Radius, s.Impulse, falloff)
+		e.Duration = s.Duration
+		e.elapsed, e.started = s.Time, s.Started
+		if s.Occluded {
+			e.Occluders = w
+		}
+		return e, nil
+	case "wind":
+		v := vec(s.Vector)
+		wind := NewWind(&v, s.Coefficient)
+		wind.Turbulence = s.Turbulence
+		wind.Frequency = s.Frequency
+		wind.Area = area()
+		wind.time = s.Time
+		return wind, nil
+	case "aerodynamics":
+		a := NewAerodynamics(s.LinearDrag, s.QuadraticDrag)
+		a.FluidVelocity = vec(s.Vector)
+		a.Lift = s.Lift
+		if s.Axis != nil {
+			a.LiftAxis = s.Axis.vec3()
+		}
+		a.Area = area()
+		return a, nil
+	case "vortex":
+		base, axis := vec(s.Base), vec(s.Axis)
+		v := NewVortex(&base, &axis, s.Radius, s.Height, s.Tangential)
+		v.Inward = s.Inward
+		v.Lift = s.Lift
+		v.Falloff = falloff
+		return v, nil
+	}
+	return nil, fmt.Errorf("tornago: unknown force %q", s.Type)
+}
+
+// softBody returns the soft body described, body returns the bodies by index.
+func (s *sceneSoftBody) softBody(body func(int) (*RigidBody, error)) (*SoftBody, error) {
+	var sb SoftBody
+	sb.New()
+	n := len(s.Positions)
+	if s.Previous != nil && len(s.Previous) != n {
+		return nil, fmt.Errorf("tornago: soft body has %d previous positions for %d particles", len(s.Previous), n)
+	}
+	particle := func(i int) error {
+		if i < 0 || i >= n {
+			return fmt.Errorf("tornago: particle index %d out of range", i)
+		}
+		return nil
+	}
+
+	sb.positions = make([]glm.Vec3, n)
+	for i := range s.Positions {
+		sb.positions[i] = s.Positions[i].vec3()
+	}
+	sb.previous = append([]glm.Vec3(nil), sb.positions...)
+	for i := range s.Previous {
+		sb.previous[i] = s.Previous[i].vec3()
+	}
+	sb.particleInverseMass = s.ParticleInverseMass
+	sb.inverseMasses = make([]float32, n)
+	for i := range sb.inverseMasses {
+		sb.inverseMasses[i] = sb.particleInverseMass
+	}
+	sb.normals = make([]glm.Vec3, n)
+
+	for _, spring := range s.Springs {
+		kind, err := parseSpringKind(spring.Kind)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range spring.Particles {
+			if err := particle(p); err != nil {
+				return nil, err
+			}
+		}
+		sb.springs = append(sb.springs, softBodySpring{
+			particles:  spring.Particles,
+			restLength: spring.RestLength,
+			kind:       kind,
+		})
+	}
+	for _, p := range s.Triangles {
+		if err := particle(p); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range s.VertexParticles {
+		if err := particle(p); err != nil {
+			return nil, err
+		}
+	}
+	sb.stiffness = s.Stiffness
+	sb.triangles = s.Triangles
+	sb.indices = s.Indices
+	if sb.indices == nil {
+		sb.indices = make([]uint16, len(sb.triangles))
+		for i, p := range sb.triangles {
+			sb.indices[i] = uint16(p)
+		}
+	}
+	for _, uv := range s.UVs {
+		sb.uvs = append(sb.uvs, glm.Vec2{X: uv[0], Y: uv[1]})
+	}
+	sb.vertexParticles = s.VertexParticles
+	sb.acceleration = s.Acceleration.vec3()
+	sb.damping = s.Damping
+	sb.friction = s.Friction
+	sb.thickness = s.Thickness
+	sb.iterations = s.Iterations
+	sb.collisionGroup = s.Group
+	sb.collisionMask = s.Mask
+
+	for _, pin := range s.Pins {
+		if err := particle(pin.Particle); err != nil {
+			return nil, err
+		}
+		if pin.Body != nil {
+			b, err := body(*pin.Body)
+			if err != nil {
+				return nil, err
+			}
+			var lp glm.Vec3
+			if pin.LocalPoint != nil {
+				lp = pin.LocalPoint.vec3()
+			}
+			sb.PinToBody(pin.Particle, b, &lp)
+			continue
+		}
+		wp := sb.positions[pin.Particle]
+		if pin.WorldPoint != nil {
+			wp = pin.WorldPoint.vec3()
+		}
+		sb.PinToPoint(pin.Particle, &wp)
+	}
+	sb.calculateNormals()
+	sb.calculateVolume()
+	return &sb, nil
+}
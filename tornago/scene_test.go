@@ -0,0 +1,238 @@
+package tornago
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/luxengine/lux/glm"
+	"strings"
+	"testing"
+)
+
+// newSceneTestWorld returns a world using every type the scene format saves.
+func newSceneTestWorld() *World {
+	center := glm.Vec3{}
+	w := NewWorld(NewOctree(&center, 50, 6), ContactResolver{})
+	w.SetNarrowphaseWorkers(2)
+
+	ice := NewMaterial("ice", 0.02, 0.1)
+	ice.FrictionCombine = CombineMin
+	rubber := NewMaterial("rubber", 0.9, 0.8)
+	table := NewMaterialTable()
+	table.SetPair(ice, rubber, 0.3, 0.2)
+	w.SetMaterialTable(table)
+
+	floor := NewRigidBody()
+	floor.SetMass(0)
+	floor.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 20, Y: 1, Z: 20}))
+	floor.SetPosition3f(0, -1, 0)
+	floor.SetMaterial(ice)
+	floor.calculateDerivedData()
+	w.AddRigidBody(floor)
+
+	ball := NewRigidBody()
+	ball.SetCollisionShape(NewCollisionSphere(0.5))
+	ball.SetPosition3f(0, 3, 0)
+	ball.SetVelocity3f(1, 0, 0)
+	ball.SetMaterial(rubber)
+	ball.calculateDerivedData()
+	w.AddRigidBody(ball)
+
+	crate := NewRigidBody()
+	crate.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}))
+	crate.SetPosition3f(2, 1, 0)
+	crate.SetMassFromDensity(200)
+	crate.SetAngularDamping(0.9)
+	crate.calculateDerivedData()
+	w.AddRigidBody(crate)
+	w.IgnorePair(crate, floor)
+
+	rod := NewRodConstraintToWorld(2, ball, &glm.Vec3{}, &glm.Vec3{X: 0, Y: 5, Z: 0})
+	rod.SetBreakForce(1000)
+	w.AddConstraint(rod)
+	w.AddConstraint(NewStringToBodyConstraint([2]glm.Vec3{}, [2]*RigidBody{ball, crate}, 3, 0.5))
+	w.AddConstraint(NewJointConstraint(ball, crate, &glm.Vec3{X: 1, Y: 2, Z: 0}, &glm.Vec3{X: 1, Y: 0, Z: 0}, 0.5, 0.2))
+
+	gravity := NewGravityForceGenerator(&glm.Vec3{X: 0, Y: -10, Z: 0})
+	w.AddForceGenerator(ball, gravity)
+	w.AddForceGenerator(crate, gravity)
+	spring := NewSpringForceGenerator(&glm.Vec3{}, ball, &glm.Vec3{}, 5, 2)
+	w.AddForceGenerator(crate, &spring)
+	blast := NewExplosion(&glm.Vec3{X: 5, Y: 0, Z: 0}, 4, 20, FalloffLinear)
+	blast.Occluders = w
+	w.AddForceField(blast)
+	w.AddForceField(NewWind(&glm.Vec3{X: 0, Y: 0, Z: 3}, 0.1))
+
+	flag, _ := NewCloth(&glm.Vec3{X: -3, Y: 4, Z: 0}, 1, 1, 3, 3, 0.5)
+	flag.SetAcceleration3f(0, -10, 0)
+	flag.Pin(0)
+	flag.PinToBody(3, crate, &glm.Vec3{X: 0, Y: 0.5, Z: 0})
+	w.AddSoftBody(flag)
+	return w
+}
+
+func TestWorld_SaveWorld(t *testing.T) {
+	w := newSceneTestWorld()
+	for i := 0; i < 10; i++ {
+		w.Step(1.0 / 60.0)
+	}
+
+	var first bytes.Buffer
+	if err := w.SaveWorld(&first); err != nil {
+		t.Fatalf("SaveWorld = %v", err)
+	}
+	loaded, err := LoadWorld(bytes.NewReader(first.Bytes()))
+	if err != nil {
+		t.Fatalf("LoadWorld = %v", err)
+	}
+	var second bytes.Buffer
+	if err := loaded.SaveWorld(&second); err != nil {
+		t.Fatalf("SaveWorld = %v", err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("saving a loaded world gives a different scene\n%s\nwant\n%s", second.String(), first.String())
+	}
+
+	if n := len(loaded.bodies); n != 3 {
+		t.Fatalf("len(bodies) = %d, want 3", n)
+	}
+	if n := len(loaded.constraints); n != 3 {
+		t.Errorf("len(constraints) = %d, want 3", n)
+	}
+	if n := len(loaded.softBodies); n != 1 {
+		t.Errorf("len(softBodies) = %d, want 1", n)
+	}
+	if got, want := loaded.NarrowphaseWorkers(), 2; got != want {
+		t.Errorf("NarrowphaseWorkers() = %d, want %d", got, want)
+	}
+	ball, crate := loaded.bodies[1], loaded.bodies[2]
+	if m := ball.material; m == nil || m.Name != "rubber" {
+		t.Errorf("ball material = %v, want rubber", m)
+	}
+	if _, ok := loaded.ignoredPairs[[2]*RigidBody{crate, loaded.bodies[0]}]; !ok {
+		t.Error("the crate and the floor are no longer ignored")
+	}
+	if g0, g1 := loaded.forceGeneratorEntries[0].forceGenerator, loaded.forceGeneratorEntries[1].forceGenerator; g0 != g1 {
+		t.Error("the shared gravity generator was loaded twice")
+	}
+	if e := loaded.forceFields[0].(*Explosion); e.Occluders != loaded {
+		t.Error("the explosion isn't occluded by the loaded world")
+	}
+	if got, want := loaded.constraints[0].(*RodConstraintToWorld).BreakForce(), float32(1000); got != want {
+		t.Errorf("BreakForce() = %v, want %v", got, want)
+	}
+
+	// both worlds continue the same simulation.
+	for i := 0; i < 10; i++ {
+		w.Step(1.0 / 60.0)
+		loaded.Step(1.0 / 60.0)
+	}
+	for i := range w.bodies {
+		if got, want := loaded.bodies[i].Position(), w.bodies[i].Position(); got != want {
+			t.Errorf("body %d position = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestWorld_SaveWorldSettings(t *testing.T) {
+	w := NewWorld(&SAP{}, ContactResolver{})
+	b := NewRigidBody()
+	b.SetCollisionShape(NewCollisionSphere(1))
+	w.AddRigidBody(b)
+
+	// materials only referenced by the table.
+	table := NewMaterialTable()
+	for _, name := range []string{"wood", "ice", "steel", "rubber", "glass"} {
+		table.SetPair(NewMaterial(name, 0.5, 0.5), NewMaterial(name+"2", 0.5, 0.5), 0.1, 0.1)
+	}
+	w.SetMaterialTable(table)
+	w.Step(1.0 / 60)
+
+	var buf bytes.Buffer
+	if err := w.SaveWorld(&buf); err != nil {
+		t.Fatalf("SaveWorld = %v", err)
+	}
+	var s scene
+	if err := json.Unmarshal(buf.Bytes(), &s); err != nil {
+		t.Fatalf("json.Unmarshal = %v", err)
+	}
+	// Step replaced the broadphase it can't update, the scene keeps SAP.
+	if s.Broadphase.Type != "sap" {
+		t.Errorf("broadphase = %q, want sap", s.Broadphase.Type)
+	}
+	want := []string{"glass", "glass2", "ice", "ice2", "rubber", "rubber2", "steel", "steel2", "wood", "wood2"}
+	if len(s.Materials) != len(want) {
+		t.Fatalf("len(materials) = %d, want %d", len(s.Materials), len(want))
+	}
+	for i := range want {
+		if s.Materials[i].Name != want[i] {
+			t.Errorf("[%d] material = %q, want %q", i, s.Materials[i].Name, want[i])
+		}
+	}
+}
+
+func TestLoadWorld_Defaults(t *testing.T) {
+	w, err := LoadWorld(strings.NewReader(`{
+		"bodies": [
+			{"shape": {"type": "sphere", "radius": 2}, "mass": 4, "position": [1, 2, 3]},
+			{"shape": {"type": "box", "halfSize": [1, 2, 3]}, "mass": 2}
+		],
+		"softBodies": [{"positions": [[0, 0, 0], [1, 0, 0]], "springs": [{"particles": [0, 1], "restLength": 1, "kind": "structural"}]}]
+	}`))
+	if err != nil {
+		t.Fatalf("LoadWorld = %v", err)
+	}
+	if _, ok := w.broadphase.(*NaiveBroadphase); !ok {
+		t.Errorf("broadphase = %T, want *NaiveBroadphase", w.broadphase)
+	}
+	b, want := w.bodies[0], NewRigidBody()
+	if got := b.Mass(); got != 4 {
+		t.Errorf("Mass() = %v, want 4", got)
+	}
+	if got, want := b.Position(), (glm.Vec3{X: 1, Y: 2, Z: 3}); got != want {
+		t.Errorf("Position() = %v, want %v", got, want)
+	}
+	if b.linearDamping != want.linearDamping || b.collisionMask != want.collisionMask {
+		t.Errorf("damping, mask = %v, %v, want %v, %v", b.linearDamping, b.collisionMask, want.linearDamping, want.collisionMask)
+	}
+	if b.orientation != want.orientation {
+		t.Errorf("orientation = %v, want %v", b.orientation, want.orientation)
+	}
+
+	// without an inertia tensor the box can still rotate.
+	box := NewRigidBody()
+	box.SetMass(2)
+	box.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 1, Y: 2, Z: 3}))
+	if b := w.bodies[1]; b.inverseInertiaTensor != box.inverseInertiaTensor || b.centerOfMass != (glm.Vec3{}) {
+		t.Errorf("inverse inertia tensor, center of mass = %v, %v, want %v, {0 0 0}", b.inverseInertiaTensor, b.centerOfMass, box.inverseInertiaTensor)
+	}
+
+	var ws SoftBody
+	ws.New()
+	sb := w.softBodies[0]
+	if sb.stiffness != ws.stiffness || sb.damping != ws.damping || sb.friction != ws.friction || sb.thickness != ws.thickness || sb.iterations != ws.iterations {
+		t.Errorf("stiffness, damping, friction, thickness, iterations = %v, %v, %v, %v, %v, want %v, %v, %v, %v, %v",
+			sb.stiffness, sb.damping, sb.friction, sb.thickness, sb.iterations, ws.stiffness, ws.damping, ws.friction, ws.thickness, ws.iterations)
+	}
+	if sb.collisionGroup != ws.collisionGroup || sb.collisionMask != ws.collisionMask {
+		t.Errorf("soft body group, mask = %x, %x, want %x, %x", sb.collisionGroup, sb.collisionMask, ws.collisionGroup, ws.collisionMask)
+	}
+}
+
+func TestLoadWorld_Errors(t *testing.T) {
+	tests := []string{
+		`{`,
+		`{"version": 99}`,
+		`{"broadphase": {"type": "kdtree"}}`,
+		`{"bodies": [{"shape": {"type": "cone"}}]}`,
+		`{"bodies": [{"shape": {"type": "sphere", "radius": 1}, "material": "ice"}]}`,
+		`{"constraints": [{"type": "rodToWorld", "bodies": [0], "localPoints": [[0, 0, 0]]}]}`,
+		`{"forces": [{"type": "magnet"}]}`,
+		`{"forces": [{"type": "gravity"}], "forceFields": [0]}`,
+		`{"softBodies": [{"positions": [[0, 0, 0]], "pins": [{"particle": 3}]}]}`,
+	}
+	for _, test := range tests {
+		if _, err := LoadWorld(strings.NewReader(test)); err == nil {
+			t.Errorf("LoadWorld(%s) = nil, want an error", test)
+		}
+	}
+}
@@ -15,6 +15,7 @@ type SliderToWorldConstraint struct {
 	body                 *RigidBody
 	minlength, maxlength float32
 	restitution          float32
+	ConstraintFeedback
 }
 
 // NewSliderToWorldConstraint returns a slider-to-world constraint with the
@@ -36,6 +37,9 @@ func NewSliderToWorldConstraint(worldPoint, localPoint glm.Vec3, body *RigidBody
 // to better control memory allocation and time spent resolving contacts.
 // Returns how many contacts we're generated.
 func (s *SliderToWorldConstraint) GenerateContacts(contacts []Contact) int {
+	if s.Broken() {
+		return 0
+	}
 	// find the local point on the rigid body in world position.
 	bodyPointInWorld := s.body.transformMatrix.Transform(&s.localPoint)
 	// from that point, get the direction to the world static point
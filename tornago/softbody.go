@@ -0,0 +1,735 @@
+package tornago
+
+import (
+	"fmt"
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+)
+
+const (
+	defaultSoftBodyIterations = 8
+	defaultSoftBodyThickness  = 0.02
+)
+
+// SpringKind is the role a spring plays in a soft body. Each kind has its own
+// stiffness.
+type SpringKind int
+
+// All the kinds of springs a soft body can have.
+const (
+	// StructuralSpring connects direct neighbours, they keep the soft body
+	// from stretching.
+	StructuralSpring SpringKind = iota
+
+	// ShearSpring connects diagonal neighbours, they keep the soft body from
+	// shearing.
+	ShearSpring
+
+	// BendSpring connects particles 2 apart, they keep the soft body from
+	// folding onto itself.
+	BendSpring
+
+	numSpringKinds
+)
+
+// softBodySpring is a distance constraint between 2 particles.
+type softBodySpring struct {
+	particles  [2]int
+	restLength float32
+	kind       SpringKind
+}
+
+// softBodyPin holds a particle in place, either at a world point or at a point
+// on a rigid body.
+type softBodyPin struct {
+	particle   int
+	body       *RigidBody
+	localPoint glm.Vec3
+	worldPoint glm.Vec3
+}
+
+// SoftBody is a deformable body made of point masses held together by springs.
+// It is solved using position based dynamics which makes it stable even with
+// very stiff springs. It can be used for cloth, flags, capes or jelly.
+// Soft bodies collide against the rigid bodies of the world but do not push
+// them back. Only sphere and box collision shapes are collided with, bodies
+// with other shapes are ignored.
+type SoftBody struct {
+	// The current and previous positions of the particles. The velocity is
+	// implicit.
+	positions, previous []glm.Vec3
+
+	// The inverse mass of every particle, 0 for pinned particles.
+	inverseMasses []float32
+
+	// The inverse mass a particle gets back when it's unpinned.
+	particleInverseMass float32
+
+	springs   []softBodySpring
+	stiffness [numSpringKinds]float32
+	pins      []softBodyPin
+
+	// The triangles of the soft body, as particle indices. Used to calculate
+	// normals.
+	triangles []int
+
+	// The render data. A render vertex maps to a particle, there can be more
+	// render vertices then particles when the mesh has seams.
+	indices         []uint16
+	uvs             []glm.Vec2
+	vertexParticles []int
+
+	// The per particle normals.
+	normals []glm.Vec3
+
+	acceleration glm.Vec3
+	damping      float32
+	friction     float32
+	thickness    float32
+	iterations   int
+
+	collisionGroup, collisionMask uint16
+
+	// The bounding sphere of all the particles, updated every step.
+	volume BoundingSphere
+
+	// Scratch bounding volume of the rigid body being collided with, a local
+	// would escape to the heap.
+	bodyVolume BoundingSphere
+}
+
+// NewCloth returns a rectangular piece of cloth in the XY plane. The first row
+// of particles starts at origin and goes along +X, the following rows go
+// along -Y. There are (segmentsX+1)*(segmentsY+1) particles and the particle
+// at column x and row y has index y*(segmentsX+1)+x. The mass is spread
+// evenly over all particles. It returns an error if there isn't at least 1
+// segment along each side or if the mesh has too many vertices to be indexed
+// with uint16.
+func NewCloth(origin *glm.Vec3, width, height float32, segmentsX, segmentsY int, mass float32) (*SoftBody, error) {
+	if segmentsX < 1 || segmentsY < 1 {
+		return nil, fmt.Errorf("tornago: cloth needs at least 1 segment along each side, got %d by %d", segmentsX, segmentsY)
+	}
+	cols, rows := segmentsX+1, segmentsY+1
+	if cols*rows > 1<<16 {
+		return nil, fmt.Errorf("tornago: cloth has %d particles, at most %d can be indexed", cols*rows, 1<<16)
+	}
+
+	var s SoftBody
+	s.New()
+
+	index := func(x, y int) int { return y*cols + x }
+
+	s.positions = make([]glm.Vec3, 0, cols*rows)
+	s.uvs = make([]glm.Vec2, 0, cols*rows)
+	s.vertexParticles = make([]int, 0, cols*rows)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			u, v := float32(x)/float32(segmentsX), float32(y)/float32(segmentsY)
+			s.positions = append(s.positions, glm.Vec3{X: origin.X + u*width, Y: origin.Y - v*height, Z: origin.Z})
+			s.uvs = append(s.uvs, glm.Vec2{X: u, Y: v})
+			s.vertexParticles = append(s.vertexParticles, len(s.vertexParticles))
+		}
+	}
+
+	for y := 0; y < segmentsY; y++ {
+		for x := 0; x < segmentsX; x++ {
+			a, b, c, d := index(x, y), index(x+1, y), index(x, y+1), index(x+1, y+1)
+			s.triangles = append(s.triangles, a, c, b, b, c, d)
+		}
+	}
+
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			if x+1 < cols {
+				s.AddSpring(StructuralSpring, index(x, y), index(x+1, y))
+			}
+			if y+1 < rows {
+				s.AddSpring(StructuralSpring, index(x, y), index(x, y+1))
+			}
+			if x+1 < cols && y+1 < rows {
+				s.AddSpring(ShearSpring, index(x, y), index(x+1, y+1))
+				s.AddSpring(ShearSpring, index(x+1, y), index(x, y+1))
+			}
+			if x+2 < cols {
+				s.AddSpring(BendSpring, index(x, y), index(x+2, y))
+			}
+			if y+2 < rows {
+				s.AddSpring(BendSpring, index(x, y), index(x, y+2))
+			}
+		}
+	}
+
+	s.finish(mass)
+	return &s, nil
+}
+
+// NewSoftBodyFromMesh returns a soft body from an indexed triangle mesh, like
+// the one returned by CollisionSphere.Mesh. Vertices that share the same
+// position are merged into a single particle so the mesh doesn't tear along
+// its seams. Every triangle edge becomes a structural spring and every pair of
+// triangles sharing an edge gets a bend spring between their opposite
+// vertices. The mass is spread evenly over all particles.
+func NewSoftBodyFromMesh(indices []uint16, vertices []glm.Vec3, uvs []glm.Vec2, mass float32) *SoftBody {
+	var s SoftBody
+	s.New()
+
+	s.vertexParticles = make([]int, len(vertices))
+	for i := range vertices {
+		particle := -1
+		for p := range s.positions {
+			if s.positions[p].EqualThreshold(&vertices[i], 1e-5) {
+				particle = p
+				break
+			}
+		}
+		if particle == -1 {
+			particle = len(s.positions)
+			s.positions = append(s.positions, vertices[i])
+		}
+		s.vertexParticles[i] = particle
+	}
+
+	s.indices = append([]uint16(nil), indices...)
+	if uvs != nil {
+		s.uvs = append([]glm.Vec2(nil), uvs...)
+	} else {
+		s.uvs = make([]glm.Vec2, len(vertices))
+	}
+
+	// an edge between 2 particles, always ordered.
+	type edge [2]int
+	makeEdge := func(a, b int) edge {
+		if a > b {
+			a, b = b, a
+		}
+		return edge{a, b}
+	}
+	// the vertex opposite to the edge in the first triangle that had it.
+	opposite := make(map[edge]int)
+
+	for t := 0; t+2 < len(indices); t += 3 {
+		tri := [3]int{
+			s.vertexParticles[indices[t]],
+			s.vertexParticles[indices[t+1]],
+			s.vertexParticles[indices[t+2]],
+		}
+		if tri[0] == tri[1] || tri[1] == tri[2] || tri[2] == tri[0] {
+			continue
+		}
+		s.triangles = append(s.triangles, tri[0], tri[1], tri[2])
+		for e := 0; e < 3; e++ {
+			a, b, o := tri[e], tri[(e+1)%3], tri[(e+2)%3]
+			key := makeEdge(a, b)
+			if other, ok := opposite[key]; ok {
+				if other != o {
+					s.AddSpring(BendSpring, other, o)
+				}
+				continue
+			}
+			opposite[key] = o
+			s.AddSpring(StructuralSpring, a, b)
+		}
+	}
+
+	s.finish(mass)
+	return &s
+}
+
+// New initializes this soft body with its default values. Used for memory
+// management.
+func (s *SoftBody) New() {
+	*s = SoftBody{
+		stiffness:      [numSpringKinds]float32{1, 1, 0.5},
+		damping:        defaultLinearDamping,
+		friction:       0.5,
+		thickness:      defaultSoftBodyThickness,
+		iterations:     defaultSoftBodyIterations,
+		collisionGroup: Group(0),
+		collisionMask:  Mask(99),
+	}
+}
+
+// finish allocates the per particle data once all the particles are known.
+func (s *SoftBody) finish(mass float32) {
+	n := len(s.positions)
+	s.previous = append([]glm.Vec3(nil), s.positions...)
+	s.normals = make([]glm.Vec3, n)
+	s.inverseMasses = make([]float32, n)
+	if n > 0 && mass > 0 {
+		s.particleInverseMass = float32(n) / mass
+	}
+	for i := range s.inverseMasses {
+		s.inverseMasses[i] = s.particleInverseMass
+	}
+	if s.indices == nil {
+		s.indices = make([]uint16, len(s.triangles))
+		for i, p := range s.triangles {
+			s.indices[i] = uint16(p)
+		}
+	}
+	s.calculateNormals()
+	s.calculateVolume()
+}
+
+//==============================================================================
+//=============================Setters and Getters==============================
+//==============================================================================
+
+// NumParticles returns how many particles this soft body has.
+func (s *SoftBody) NumParticles() int {
+	return len(s.positions)
+}
+
+// ParticlePosition returns the world position of the given particle.
+func (s *SoftBody) ParticlePosition(i int) glm.Vec3 {
+	return s.positions[i]
+}
+
+// SetParticlePosition teleports the given particle, its velocity is set to 0.
+func (s *SoftBody) SetParticlePosition(i int, pos *glm.Vec3) {
+	s.positions[i] = *pos
+	s.previous[i] = *pos
+}
+
+// ParticleVelocity returns the velocity the particle had during the last step
+// of the given duration.
+func (s *SoftBody) ParticleVelocity(i int, duration float32) glm.Vec3 {
+	v := s.positions[i].Sub(&s.previous[i])
+	v.MulWith(1 / duration)
+	return v
+}
+
+// AddSpring adds a spring of the given kind between particles i and j. Its
+// rest length is the current distance between the particles.
+func (s *SoftBody) AddSpring(kind SpringKind, i, j int) {
+	d := s.positions[i].Sub(&s.positions[j])
+	s.springs = append(s.springs, softBodySpring{
+		particles:  [2]int{i, j},
+		restLength: d.Len(),
+		kind:       kind,
+	})
+}
+
+// NumSprings returns how many springs this soft body has.
+func (s *SoftBody) NumSprings() int {
+	return len(s.springs)
+}
+
+// SetStiffness sets the stiffness of every spring of the given kind. It must
+// be in the range [0, 1], 1 being perfectly rigid.
+func (s *SoftBody) SetStiffness(kind SpringKind, stiffness float32) {
+	s.stiffness[kind] = stiffness
+}
+
+// Stiffness returns the stiffness of the springs of the given kind.
+func (s *SoftBody) Stiffness(kind SpringKind) float32 {
+	return s.stiffness[kind]
+}
+
+// SetIterations sets how many times the springs are solved every step. More
+// iterations make the soft body stiffer but cost more.
+func (s *SoftBody) SetIterations(iterations int) {
+	s.iterations = iterations
+}
+
+// Iterations returns how many times the springs are solved every step.
+func (s *SoftBody) Iterations() int {
+	return s.iterations
+}
+
+// SetAcceleration3f sets the constant acceleration applied to every particle
+// (like gravity).
+func (s *SoftBody) SetAcceleration3f(x, y, z float32) {
+	s.acceleration = glm.Vec3{X: x, Y: y, Z: z}
+}
+
+// SetAccelerationVec3 sets the constant acceleration applied to every
+// particle (like gravity).
+func (s *SoftBody) SetAccelerationVec3(acceleration *glm.Vec3) {
+	s.acceleration = *acceleration
+}
+
+// Acceleration returns the constant acceleration applied to every particle.
+func (s *SoftBody) Acceleration() glm.Vec3 {
+	return s.acceleration
+}
+
+// SetDamping sets the damping of the particles movement. Must be in the range
+// [0, 1], same as RigidBody.SetLinearDamping.
+func (s *SoftBody) SetDamping(damping float32) {
+	s.damping = damping
+}
+
+// Damping returns the damping of the particles movement.
+func (s *SoftBody) Damping() float32 {
+	return s.damping
+}
+
+// SetFriction sets the friction used when particles touch rigid bodies. Must
+// be in the range [0, 1].
+func (s *SoftBody) SetFriction(friction float32) {
+	s.friction = friction
+}
+
+// Friction returns the friction used when particles touch rigid bodies.
+func (s *SoftBody) Friction() float32 {
+	return s.friction
+}
+
+// SetThickness sets the radius of the particles when colliding with rigid
+// bodies.
+func (s *SoftBody) SetThickness(thickness float32) {
+	s.thickness = thickness
+}
+
+// Thickness returns the radius of the particles when colliding with rigid
+// bodies.
+func (s *SoftBody) Thickness() float32 {
+	return s.thickness
+}
+
+// SetGroup sets the collision group of this soft body.
+func (s *SoftBody) SetGroup(group uint16) {
+	s.collisionGroup = group
+}
+
+// Group returns the collision group of this soft body.
+func (s *SoftBody) Group() uint16 {
+	return s.collisionGroup
+}
+
+// SetMask sets the collision mask of this soft body.
+func (s *SoftBody) SetMask(mask uint16) {
+	s.collisionMask = mask
+}
+
+// Mask returns the collision mask of this soft body.
+func (s *SoftBody) Mask() uint16 {
+	return s.collisionMask
+}
+
+// BoundingVolume returns a bounding sphere that contains every particle as of
+// the last step.
+func (s *SoftBody) BoundingVolume() BoundingSphere {
+	return s.volume
+}
+
+//==============================================================================
+//===================================Pinning====================================
+//==============================================================================
+
+// Pin holds the given particle at its current position.
+func (s *SoftBody) Pin(i int) {
+	s.PinToPoint(i, &s.positions[i])
+}
+
+// PinToPoint holds the given particle at the given world point.
+func (s *SoftBody) PinToPoint(i int, worldPoint *glm.Vec3) {
+	s.addPin(softBodyPin{particle: i, worldPoint: *worldPoint})
+}
+
+// PinToBody attaches the given particle to a point on a rigid body, in the
+// body local space. The particle will follow the body, this is how you attach
+// a cape to a character.
+func (s *SoftBody) PinToBody(i int, body *RigidBody, localPoint *glm.Vec3) {
+	s.addPin(softBodyPin{particle: i, body: body, localPoint: *localPoint})
+}
+
+// addPin replaces any existing pin of the same particle.
+func (s *SoftBody) addPin(pin softBodyPin) {
+	s.inverseMasses[pin.particle] = 0
+	for n := range s.pins {
+		if s.pins[n].particle == pin.particle {
+			s.pins[n] = pin
+			return
+		}
+	}
+	s.pins = append(s.pins, pin)
+}
+
+// Unpin releases the given particle.
+func (s *SoftBody) Unpin(i int) {
+	for n := range s.pins {
+		if s.pins[n].particle == i {
+			copy(s.pins[n:], s.pins[n+1:])
+			s.pins = s.pins[:len(s.pins)-1]
+			s.inverseMasses[i] = s.particleInverseMass
+			return
+		}
+	}
+}
+
+// IsPinned returns true if the given particle is pinned.
+func (s *SoftBody) IsPinned(i int) bool {
+	for n := range s.pins {
+		if s.pins[n].particle == i {
+			return true
+		}
+	}
+	return false
+}
+
+//==============================================================================
+//==================================Simulation==================================
+//==============================================================================
+
+// Step advances the soft body by duration and collides it against the given
+// rigid bodies. World.Step calls this for every soft body in the world.
+func (s *SoftBody) Step(duration float32, bodies []*RigidBody) {
+	if duration <= 0 {
+		return
+	}
+
+	// verlet integration, the velocity is the difference between the current
+	// and previous positions.
+	damping := powDamping(s.damping, duration)
+	var acceleration glm.Vec3
+	acceleration.MulOf(duration*duration, &s.acceleration)
+	for i := range s.positions {
+		if s.inverseMasses[i] == 0 {
+			continue
+		}
+		velocity := s.positions[i].Sub(&s.previous[i])
+		velocity.MulWith(damping)
+		velocity.AddWith(&acceleration)
+		s.previous[i] = s.positions[i]
+		s.positions[i].AddWith(&velocity)
+	}
+
+	s.applyPins()
+
+	iterations := s.iterations
+	if iterations < 1 {
+		iterations = 1
+	}
+
+	// the stiffness is corrected so that the soft body behaves the same no
+	// matter how many iterations are used.
+	var stiffness [numSpringKinds]float32
+	for k := range stiffness {
+		stiffness[k] = 1 - math.Pow(1-math.Clamp(s.stiffness[k], 0, 1), 1/float32(iterations))
+	}
+
+	s.calculateVolume()
+	for it := 0; it < iterations; it++ {
+		for n := range s.springs {
+			s.solveSpring(&s.springs[n], stiffness[s.springs[n].kind])
+		}
+		s.collide(bodies)
+	}
+
+	s.calculateVolume()
+	s.calculateNormals()
+}
+
+// applyPins moves every pinned particle to its pin.
+func (s *SoftBody) applyPins() {
+	for n := range s.pins {
+		pin := &s.pins[n]
+		target := pin.worldPoint
+		if pin.body != nil {
+			pin.body.PointInWorldCoordinates(&pin.localPoint, &target)
+		}
+		s.previous[pin.particle] = s.positions[pin.particle]
+		s.positions[pin.particle] = target
+	}
+}
+
+// solveSpring moves both particles of the spring toward its rest length.
+func (s *SoftBody) solveSpring(spring *softBodySpring, stiffness float32) {
+	i, j := spring.particles[0], spring.particles[1]
+	w := s.inverseMasses[i] + s.inverseMasses[j]
+	if w == 0 {
+		return
+	}
+
+	delta := s.positions[i].Sub(&s.positions[j])
+	l := delta.Len()
+	if l == 0 {
+		return
+	}
+
+	delta.MulWith((l - spring.restLength) / (l * w) * stiffness)
+	s.positions[i].AddScaledVec(-s.inverseMasses[i], &delta)
+	s.positions[j].AddScaledVec(s.inverseMasses[j], &delta)
+}
+
+// collide pushes every particle out of the rigid bodies they penetrate. Bodies
+// that aren't spheres or boxes are skipped.
+func (s *SoftBody) collide(bodies []*RigidBody) {
+	volume := &s.bodyVolume
+	for _, b := range bodies {
+		if b.shape == nil || s.collisionGroup&b.collisionMask == 0 || b.collisionGroup&s.collisionMask == 0 {
+			continue
+		}
+		boundingVolumeIn(b.shape, volume)
+		volume.radius += s.thickness
+		if !volume.Overlaps(&s.volume) {
+			continue
+		}
+		for i := range s.positions {
+			if s.inverseMasses[i] == 0 {
+				continue
+			}
+			var normal glm.Vec3
+			var hit bool
+			switch shape := b.shape.(type) {
+			case *CollisionSphere:
+				hit = s.collideSphere(i, shape, &normal)
+			case *CollisionBox:
+				hit = s.collideBox(i, shape, &normal)
+			}
+			if hit {
+				friction, _ := combineSurfaces(nil, s, b)
+				s.applyFriction(i, friction, &normal)
+			}
+		}
+	}
+}
+
+// collideSphere pushes particle i out of the sphere and stores the contact
+// normal in normal. Returns true if there was a contact.
+func (s *SoftBody) collideSphere(i int, sphere *CollisionSphere, normal *glm.Vec3) bool {
+	center := sphere.Position()
+	d := s.positions[i].Sub(&center)
+	r := sphere.Radius() + s.thickness
+	l2 := d.Len2()
+	if l2 >= r*r {
+		return false
+	}
+	if l2 == 0 {
+		*normal = glm.Vec3{X: 0, Y: 1, Z: 0}
+	} else {
+		normal.MulOf(1/math.Sqrt(l2), &d)
+	}
+	s.positions[i] = center
+	s.positions[i].AddScaledVec(r, normal)
+	return true
+}
+
+// collideBox pushes particle i out of the box through the closest face and
+// stores the contact normal in normal. Returns true if there was a contact.
+func (s *SoftBody) collideBox(i int, box *CollisionBox, normal *glm.Vec3) bool {
+	transform := &box.body.shapeTransform
+	local := transform.TransformInverse(&s.positions[i])
+	halfSize := box.halfSize
+	halfSize.AddWith(&glm.Vec3{X: s.thickness, Y: s.thickness, Z: s.thickness})
+
+	// find the face with the smallest penetration.
+	axis, depth := -1, float32(math.MaxFloat32)
+	for a := 0; a < 3; a++ {
+		p, h := *local.I(a), *halfSize.I(a)
+		if p <= -h || p >= h {
+			return false
+		}
+		if d := h - math.Abs(p); d < depth {
+			axis, depth = a, d
+		}
+	}
+
+	var localNormal glm.Vec3
+	if *local.I(axis) < 0 {
+		*localNormal.I(axis) = -1
+		*local.I(axis) = -*halfSize.I(axis)
+	} else {
+		*localNormal.I(axis) = 1
+		*local.I(axis) = *halfSize.I(axis)
+	}
+	transform.TransformIn(&local, &s.positions[i])
+	transform.TransformDirectionIn(&localNormal, normal)
+	return true
+}
+
+// surface returns the friction of this soft body, it doesn't bounce and
+// averages its friction with the other surface.
+func (s *SoftBody) surface() (friction, restitution float32, frictionCombine, restitutionCombine CombineMode) {
+	return s.friction, 0, CombineAverage, CombineAverage
+}
+
+// surfaceMaterial returns nil, soft bodies have no material so MaterialTable
+// overrides never apply to them.
+func (s *SoftBody) surfaceMaterial() *Material {
+	return nil
+}
+
+// applyFriction removes some of the tangential movement of particle i this
+// step, friction is the combined friction of both surfaces.
+func (s *SoftBody) applyFriction(i int, friction float32, normal *glm.Vec3) {
+	friction = math.Clamp(friction, 0, 1)
+	if friction == 0 {
+		return
+	}
+	movement := s.positions[i].Sub(&s.previous[i])
+	movement.AddScaledVec(-movement.Dot(normal), normal)
+	s.previous[i].AddScaledVec(friction, &movement)
+}
+
+// calculateVolume updates the bounding sphere of the particles.
+func (s *SoftBody) calculateVolume() {
+	if len(s.positions) == 0 {
+		s.volume = BoundingSphere{}
+		return
+	}
+	min, max := s.positions[0], s.positions[0]
+	for _, p := range s.positions[1:] {
+		min.X, max.X = math.Min(min.X, p.X), math.Max(max.X, p.X)
+		min.Y, max.Y = math.Min(min.Y, p.Y), math.Max(max.Y, p.Y)
+		min.Z, max.Z = math.Min(min.Z, p.Z), math.Max(max.Z, p.Z)
+	}
+	var center, extent glm.Vec3
+	center.AddOf(&min, &max)
+	center.MulWith(0.5)
+	extent.SubOf(&max, &center)
+	s.volume = BoundingSphere{center: center, radius: extent.Len() + s.thickness}
+}
+
+// calculateNormals updates the per particle normals using the area weighted
+// normals of the triangles.
+func (s *SoftBody) calculateNormals() {
+	for i := range s.normals {
+		s.normals[i] = glm.Vec3{}
+	}
+	for t := 0; t+2 < len(s.triangles); t += 3 {
+		a, b, c := s.triangles[t], s.triangles[t+1], s.triangles[t+2]
+		e0 := s.positions[b].Sub(&s.positions[a])
+		e1 := s.positions[c].Sub(&s.positions[a])
+		n := e0.Cross(&e1)
+		s.normals[a].AddWith(&n)
+		s.normals[b].AddWith(&n)
+		s.normals[c].AddWith(&n)
+	}
+	for i := range s.normals {
+		if s.normals[i].Len2() > 0 {
+			s.normals[i].Normalize()
+		}
+	}
+}
+
+//==============================================================================
+//===================================Rendering==================================
+//==============================================================================
+
+// NumVertices returns how many render vertices this soft body has.
+func (s *SoftBody) NumVertices() int {
+	return len(s.vertexParticles)
+}
+
+// Mesh returns the indices, vertices, uvs and normals of this soft body in the
+// same format as CollisionSphere.Mesh. The result can be given directly to
+// render.NewVUNModel.
+func (s *SoftBody) Mesh() ([]uint16, []glm.Vec3, []glm.Vec2, []glm.Vec3) {
+	vertices := make([]glm.Vec3, len(s.vertexParticles))
+	normals := make([]glm.Vec3, len(s.vertexParticles))
+	s.UpdateMesh(vertices, normals)
+	return append([]uint16(nil), s.indices...), vertices, append([]glm.Vec2(nil), s.uvs...), normals
+}
+
+// UpdateMesh fills vertices and normals with the current state of the soft
+// body, it doesn't allocate so it can be used every frame to update the render
+// buffers. Both slices must have a length of at least NumVertices.
+func (s *SoftBody) UpdateMesh(vertices, normals []glm.Vec3) {
+	for v, p := range s.vertexParticles {
+		vertices[v] = s.positions[p]
+		normals[v] = s.normals[p]
+	}
+}
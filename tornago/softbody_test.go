@@ -0,0 +1,262 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/glm"
+	"github.com/luxengine/lux/math"
+	"testing"
+)
+
+func TestNewCloth(t *testing.T) {
+	c, err := NewCloth(&glm.Vec3{X: 0, Y: 10, Z: 0}, 4, 2, 4, 2, 15)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewCloth(&glm.Vec3{}, 4, 2, 0, 2, 15); err == nil {
+		t.Error("NewCloth with 0 segments along X didn't return an error")
+	}
+	if _, err := NewCloth(&glm.Vec3{}, 4, 2, 4, -1, 15); err == nil {
+		t.Error("NewCloth with -1 segments along Y didn't return an error")
+	}
+	if _, err := NewCloth(&glm.Vec3{}, 4, 2, 255, 256, 15); err == nil {
+		t.Error("NewCloth with 256*257 particles didn't return an error")
+	}
+
+	if n := c.NumParticles(); n != 15 {
+		t.Errorf("c.NumParticles() = %d, want 15", n)
+	}
+
+	// 5*2 + 4*3 structural, 2*4*2 shear, 3*3 + 5*1 bend.
+	if n := c.NumSprings(); n != 22+16+14 {
+		t.Errorf("c.NumSprings() = %d, want %d", n, 22+16+14)
+	}
+
+	if p := c.ParticlePosition(14); p != (glm.Vec3{X: 4, Y: 8, Z: 0}) {
+		t.Errorf("c.ParticlePosition(14) = %v, want {4, 8, 0}", p)
+	}
+
+	indices, vertices, uvs, normals := c.Mesh()
+	if len(indices) != 4*2*6 {
+		t.Errorf("len(indices) = %d, want %d", len(indices), 4*2*6)
+	}
+	if len(vertices) != 15 || len(uvs) != 15 || len(normals) != 15 {
+		t.Errorf("mesh sizes = %d, %d, %d, want 15", len(vertices), len(uvs), len(normals))
+	}
+	for i, n := range normals {
+		if math.Abs(math.Abs(n.Z)-1) > 1e-5 {
+			t.Errorf("normals[%d] = %v, want +-Z", i, n)
+		}
+	}
+}
+
+func TestSoftBody_Pin(t *testing.T) {
+	c, _ := NewCloth(&glm.Vec3{X: 0, Y: 10, Z: 0}, 2, 2, 4, 4, 1)
+	c.SetAcceleration3f(0, -10, 0)
+	for x := 0; x < 5; x++ {
+		c.Pin(x)
+	}
+	if !c.IsPinned(0) {
+		t.Error("c.IsPinned(0) = false, want true")
+	}
+
+	for i := 0; i < 120; i++ {
+		c.Step(1.0/60, nil)
+	}
+
+	for x := 0; x < 5; x++ {
+		if p := c.ParticlePosition(x); p.Y != 10 {
+			t.Errorf("pinned particle %d moved to %v", x, p)
+		}
+	}
+	if p := c.ParticlePosition(24); p.Y > 8.1 {
+		t.Errorf("c.ParticlePosition(24) = %v, cloth should be hanging", p)
+	}
+
+	// the structural springs should keep the cloth from stretching too much.
+	for _, s := range c.springs {
+		if s.kind != StructuralSpring {
+			continue
+		}
+		d := c.positions[s.particles[0]].Sub(&c.positions[s.particles[1]])
+		if l := d.Len(); l > s.restLength*1.1 {
+			t.Errorf("spring %v stretched to %f, rest length %f", s.particles, l, s.restLength)
+		}
+	}
+
+	c.Unpin(0)
+	if c.IsPinned(0) {
+		t.Error("c.IsPinned(0) = true, want false")
+	}
+	c.Step(1.0/60, nil)
+	if p := c.ParticlePosition(0); p.Y == 10 {
+		t.Error("unpinned particle didn't move")
+	}
+}
+
+func TestSoftBody_PinToBody(t *testing.T) {
+	body := NewRigidBody()
+	body.SetCollisionShape(NewCollisionSphere(1))
+	body.SetPosition3f(0, 5, 0)
+	body.calculateDerivedData()
+
+	c, _ := NewCloth(&glm.Vec3{X: 0, Y: 5, Z: 0}, 1, 1, 1, 1, 1)
+	c.PinToBody(0, body, &glm.Vec3{X: 0, Y: 1, Z: 0})
+	c.Step(1.0/60, nil)
+
+	if p := c.ParticlePosition(0); p != (glm.Vec3{X: 0, Y: 6, Z: 0}) {
+		t.Errorf("c.ParticlePosition(0) = %v, want {0, 6, 0}", p)
+	}
+
+	body.SetPosition3f(3, 5, 0)
+	body.calculateDerivedData()
+	c.Step(1.0/60, nil)
+
+	if p := c.ParticlePosition(0); p != (glm.Vec3{X: 3, Y: 6, Z: 0}) {
+		t.Errorf("c.ParticlePosition(0) = %v, want {3, 6, 0}", p)
+	}
+}
+
+func TestSoftBody_Collide(t *testing.T) {
+	sphere := NewRigidBody()
+	sphere.SetMass(0)
+	sphere.SetCollisionShape(NewCollisionSphere(1))
+	sphere.calculateDerivedData()
+
+	box := NewRigidBody()
+	box.SetMass(0)
+	box.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 10, Y: 1, Z: 10}))
+	box.SetPosition3f(0, -3, 0)
+	box.calculateDerivedData()
+
+	bodies := []*RigidBody{sphere, box}
+
+	// a horizontal sheet falling on the sphere and then the box.
+	c, _ := NewCloth(&glm.Vec3{X: -2, Y: 0, Z: 0}, 4, 4, 8, 8, 1)
+	for i := range c.positions {
+		c.positions[i] = glm.Vec3{X: c.positions[i].X, Y: 2, Z: c.positions[i].Y + 2}
+		c.previous[i] = c.positions[i]
+	}
+	c.SetAcceleration3f(0, -10, 0)
+
+	for i := 0; i < 180; i++ {
+		c.Step(1.0/60, bodies)
+	}
+
+	r := 1 + c.Thickness()
+	for i, p := range c.positions {
+		if l := p.Len(); l < r-1e-3 {
+			t.Errorf("particle %d at %v is inside the sphere", i, p)
+		}
+		if p.Y < -2-1e-3 {
+			t.Errorf("particle %d at %v is inside the box", i, p)
+		}
+	}
+	if p := c.ParticlePosition(40); p.Len() > r+0.05 {
+		t.Errorf("center particle at %v should rest on the sphere", p)
+	}
+
+	// ignore collision with the box.
+	box.SetGroup(Group1)
+	c.SetMask(GroupAll &^ Group1)
+	for i := 0; i < 60; i++ {
+		c.Step(1.0/60, bodies)
+	}
+	if p := c.ParticlePosition(0); p.Y > -2 {
+		t.Errorf("c.ParticlePosition(0) = %v, should have fallen through the box", p)
+	}
+}
+
+func TestSoftBody_FrictionCombine(t *testing.T) {
+	tests := []struct {
+		combine CombineMode
+		moved   float32
+	}{
+		{CombineAverage, 0.75}, // 0 the soft body friction is 0.5, averaged to 0.25.
+		{CombineMin, 1},        // 1 the slippery body wins.
+		{CombineMax, 0.5},      // 2 the soft body wins.
+		{CombineMultiply, 1},   // 3
+	}
+	for i, test := range tests {
+		box := NewRigidBody()
+		box.SetMass(0)
+		box.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 10, Y: 1, Z: 10}))
+		box.SetMaterial(&Material{FrictionCombine: test.combine})
+		box.calculateDerivedData()
+
+		// a sheet resting on the box, sliding along +X by 1 a step.
+		c, _ := NewCloth(&glm.Vec3{}, 1, 1, 1, 1, 1)
+		for n := range c.positions {
+			c.positions[n] = glm.Vec3{X: c.positions[n].X, Y: 1 + c.Thickness() - 0.01, Z: c.positions[n].Y}
+			c.previous[n] = c.positions[n]
+			c.previous[n].X--
+		}
+		c.SetDamping(1)
+		// the friction slows the particles down for the next step.
+		c.Step(1, []*RigidBody{box})
+		start := c.ParticlePosition(0)
+		c.Step(1, []*RigidBody{box})
+
+		if moved := c.ParticlePosition(0).X - start.X; math.Abs(moved-test.moved) > 1e-4 {
+			t.Errorf("[%d] moved = %f, want %f", i, moved, test.moved)
+		}
+	}
+}
+
+func TestNewSoftBodyFromMesh(t *testing.T) {
+	shape := NewCollisionBox(glm.Vec3{X: 1, Y: 1, Z: 1})
+	indices, vertices, uvs, _ := shape.Mesh()
+
+	s := NewSoftBodyFromMesh(indices, vertices, uvs, 8)
+	if n := s.NumParticles(); n != 8 {
+		t.Errorf("s.NumParticles() = %d, want 8", n)
+	}
+	if n := s.NumVertices(); n != len(vertices) {
+		t.Errorf("s.NumVertices() = %d, want %d", n, len(vertices))
+	}
+	if s.NumSprings() == 0 {
+		t.Error("s.NumSprings() = 0")
+	}
+
+	// without gravity the jelly should keep its shape.
+	for i := 0; i < 60; i++ {
+		s.Step(1.0/60, nil)
+	}
+	_, v2, _, n2 := s.Mesh()
+	for i := range vertices {
+		if !v2[i].EqualThreshold(&vertices[i], 1e-4) {
+			t.Errorf("vertex %d = %v, want %v", i, v2[i], vertices[i])
+		}
+		// the normals point outward.
+		if v2[i].Dot(&n2[i]) <= 0 {
+			t.Errorf("normal %d = %v points inward", i, n2[i])
+		}
+	}
+}
+
+func TestWorld_SoftBody(t *testing.T) {
+	w, _ := newStepTestWorld(&NaiveBroadphase{}, 10, 1)
+	c, _ := NewCloth(&glm.Vec3{X: -1, Y: 3, Z: 0}, 2, 2, 8, 8, 1)
+	c.SetAcceleration3f(0, -10, 0)
+
+	w.AddSoftBody(c)
+	w.AddSoftBody(c)
+	if len(w.softBodies) != 1 {
+		t.Errorf("World should contain 1 soft body: %d", len(w.softBodies))
+	}
+
+	for i := 0; i < 10; i++ {
+		w.Step(1.0 / 60)
+	}
+	if p := c.ParticlePosition(0); p.Y >= 3 {
+		t.Errorf("c.ParticlePosition(0) = %v, soft body wasn't stepped", p)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { w.Step(1.0 / 60) }); allocs != 0 {
+		t.Errorf("w.Step allocated %v times per run, want 0", allocs)
+	}
+
+	w.RemoveSoftBody(c)
+	if len(w.softBodies) != 0 {
+		t.Errorf("World should contain 0 soft body: %d", len(w.softBodies))
+	}
+}
@@ -0,0 +1,143 @@
+package tornago
+
+import (
+	"time"
+)
+
+// StepStats holds the timings and counters collected during World.Step. When
+// it is used as an accumulator every field is the sum over all the steps.
+type StepStats struct {
+	// Time spent applying the force generators.
+	ForceGenerators time.Duration
+
+	// Time spent integrating the rigid bodies.
+	Integration time.Duration
+
+	// Time spent updating the broadphase and generating potential contacts.
+	Broadphase time.Duration
+
+	// Time spent turning potential contacts into contacts.
+	Narrowphase time.Duration
+
+	// Time spent generating contacts from the constraints.
+	Constraints time.Duration
+
+	// Time spent in the dispatcher resolving contacts.
+	Solver time.Duration
+
+	// Time spent stepping the soft bodies.
+	SoftBodies time.Duration
+
+	// How many rigid bodies were integrated.
+	BodiesIntegrated int
+
+	// How many potential contacts the broadphase generated.
+	PotentialContacts int
+
+	// How many potential contacts were dropped by the ignore lists and the
+	// pair filter of the world.
+	FilteredPairs int
+
+	// How many contacts were generated, by the narrowphase and the
+	// constraints.
+	Contacts int
+
+	// How many iterations the dispatcher used to resolve velocities.
+	VelocityIterations int
+
+	// How many iterations the dispatcher used to resolve penetrations.
+	PositionIterations int
+
+	// How many times a contact or potential contact buffer was filled and had
+	// to be grown.
+	Overflows int
+
+	// The deepest penetration of the contacts generated by the narrowphase,
+	// before the solver resolved them. When it is used as an accumulator it's
+	// the deepest penetration of all the steps.
+	MaxPenetration float32
+}
+
+// Total returns the total time spent in every phase of the step.
+func (s *StepStats) Total() time.Duration {
+	return s.ForceGenerators + s.Integration + s.Broadphase + s.Narrowphase + s.Constraints + s.Solver + s.SoftBodies
+}
+
+// Add adds every field of o to s.
+func (s *StepStats) Add(o *StepStats) {
+	s.ForceGenerators += o.ForceGenerators
+	s.Integration += o.Integration
+	s.Broadphase += o.Broadphase
+	s.Narrowphase += o.Narrowphase
+	s.Constraints += o.Constraints
+	s.Solver += o.Solver
+	s.SoftBodies += o.SoftBodies
+	s.BodiesIntegrated += o.BodiesIntegrated
+	s.PotentialContacts += o.PotentialContacts
+	s.FilteredPairs += o.FilteredPairs
+	s.Contacts += o.Contacts
+	s.VelocityIterations += o.VelocityIterations
+	s.PositionIterations += o.PositionIterations
+	s.Overflows += o.Overflows
+	if o.MaxPenetration > s.MaxPenetration {
+		s.MaxPenetration = o.MaxPenetration
+	}
+}
+
+// Stats are the statistics a world collects while profiling is enabled.
+type Stats struct {
+	// How many steps were profiled.
+	Steps int
+
+	// The stats of the last profiled step.
+	Last StepStats
+
+	// The sum of the stats of every profiled step.
+	Total StepStats
+}
+
+// Average returns the average stats of a single step, MaxPenetration is the
+// deepest of all the steps. It returns the zero StepStats if no step was
+// profiled.
+func (s *Stats) Average() StepStats {
+	if s.Steps == 0 {
+		return StepStats{}
+	}
+	n := s.Steps
+	return StepStats{
+		ForceGenerators:    s.Total.ForceGenerators / time.Duration(n),
+		Integration:        s.Total.Integration / time.Duration(n),
+		Broadphase:         s.Total.Broadphase / time.Duration(n),
+		Narrowphase:        s.Total.Narrowphase / time.Duration(n),
+		Constraints:        s.Total.Constraints / time.Duration(n),
+		Solver:             s.Total.Solver / time.Duration(n),
+		SoftBodies:         s.Total.SoftBodies / time.Duration(n),
+		BodiesIntegrated:   s.Total.BodiesIntegrated / n,
+		PotentialContacts:  s.Total.PotentialContacts / n,
+		FilteredPairs:      s.Total.FilteredPairs / n,
+		Contacts:           s.Total.Contacts / n,
+		VelocityIterations: s.Total.VelocityIterations / n,
+		PositionIterations: s.Total.PositionIterations / n,
+		Overflows:          s.Total.Overflows / n,
+		MaxPenetration:     s.Total.MaxPenetration,
+	}
+}
+
+// StatsDispatcher is a Dispatcher that can report how much work it did while
+// resolving contacts. When the world is profiling and its dispatcher
+// implements this interface ResolveContactsStats is called instead of
+// ResolveContacts.
+type StatsDispatcher interface {
+	Dispatcher
+
+	// ResolveContactsStats does the same as ResolveContacts but also fills
+	// the solver fields of stats.
+	ResolveContactsStats(contacts []Contact, duration float32, stats *StepStats)
+}
+
+// lap adds the time elapsed since start to d and returns the current time.
+func lap(d *time.Duration, start time.Time) time.Time {
+	now := time.Now()
+	*d += now.Sub(start)
+	return now
+}
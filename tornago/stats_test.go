@@ -0,0 +1,88 @@
+package tornago
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorld_StepStats(t *testing.T) {
+	w := NewWorld(&NaiveBroadphase{}, ContactResolver{})
+
+	b0 := NewRigidBody()
+	b0.SetCollisionShape(NewCollisionSphere(1))
+	b0.SetPosition3f(0, 0, 0)
+	b1 := NewRigidBody()
+	b1.SetCollisionShape(NewCollisionSphere(1))
+	b1.SetPosition3f(0, 1.5, 0)
+	w.AddRigidBody(b0)
+	w.AddRigidBody(b1)
+
+	w.SetProfiling(true)
+	if !w.Profiling() {
+		t.Error("w.Profiling() = false, want true")
+	}
+
+	w.Step(1.0 / 60)
+	s := w.Stats()
+	if s.Steps != 1 {
+		t.Errorf("s.Steps = %d, want 1", s.Steps)
+	}
+	if s.Last.BodiesIntegrated != 2 {
+		t.Errorf("s.Last.BodiesIntegrated = %d, want 2", s.Last.BodiesIntegrated)
+	}
+	if s.Last.PotentialContacts != 1 {
+		t.Errorf("s.Last.PotentialContacts = %d, want 1", s.Last.PotentialContacts)
+	}
+	if s.Last.Contacts != 1 {
+		t.Errorf("s.Last.Contacts = %d, want 1", s.Last.Contacts)
+	}
+	if s.Last.PositionIterations == 0 {
+		t.Error("s.Last.PositionIterations = 0, want > 0")
+	}
+	if p := s.Last.MaxPenetration; p < 0.49 || p > 0.51 {
+		t.Errorf("s.Last.MaxPenetration = %v, want 0.5", p)
+	}
+
+	w.Step(1.0 / 60)
+	s = w.Stats()
+	if s.Steps != 2 {
+		t.Errorf("s.Steps = %d, want 2", s.Steps)
+	}
+	if s.Total.BodiesIntegrated != 4 {
+		t.Errorf("s.Total.BodiesIntegrated = %d, want 4", s.Total.BodiesIntegrated)
+	}
+	if s.Total.MaxPenetration < s.Last.MaxPenetration {
+		t.Errorf("s.Total.MaxPenetration = %v, want the deepest of all steps", s.Total.MaxPenetration)
+	}
+
+	w.SetProfiling(false)
+	w.Step(1.0 / 60)
+	if s := w.Stats(); s.Steps != 2 {
+		t.Errorf("stats collected while not profiling: %+v", s)
+	}
+
+	w.ResetStats()
+	if s := w.Stats(); s.Steps != 0 || s.Total.BodiesIntegrated != 0 {
+		t.Errorf("stats not reset: %+v", s)
+	}
+}
+
+func TestStats_Average(t *testing.T) {
+	var s Stats
+	if a := s.Average(); a != (StepStats{}) {
+		t.Errorf("s.Average() = %+v, want zero", a)
+	}
+
+	s.Steps = 2
+	s.Total = StepStats{
+		Solver:   4 * time.Millisecond,
+		Contacts: 10,
+	}
+	a := s.Average()
+	if a.Solver != 2*time.Millisecond || a.Contacts != 5 {
+		t.Errorf("s.Average() = %+v", a)
+	}
+	if total := s.Total.Total(); total != 4*time.Millisecond {
+		t.Errorf("s.Total.Total() = %v, want 4ms", total)
+	}
+}
@@ -13,6 +13,7 @@ type StringToWorldConstraint struct {
 	body        *RigidBody
 	length      float32
 	restitution float32
+	ConstraintFeedback
 }
 
 // NewStringToWorldConstraint returns a new StringToWorldConstraint from the
@@ -78,6 +79,9 @@ func (c *StringToWorldConstraint) SetRestitution(restitution float32) {
 // GenerateContacts will generate maximum 1 contact if the rigid body's point in
 // world coordinates is too far from the set world point.
 func (c *StringToWorldConstraint) GenerateContacts(contacts []Contact) int {
+	if c.Broken() {
+		return 0
+	}
 	bodyPointInWorld := c.body.transformMatrix.Transform(&c.localPoint)
 	dir := c.worldPoint.Sub(&bodyPointInWorld)
 
@@ -105,6 +109,8 @@ type StringToBodyConstraint struct {
 	bodies      [2]*RigidBody
 	length      float32
 	restitution float32
+	collide     bool
+	ConstraintFeedback
 }
 
 // NewStringToBodyConstraint returns a new StringToBodyConstraint
@@ -159,9 +165,23 @@ func (c *StringToBodyConstraint) SetRestitution(restitution float32) {
 	c.restitution = restitution
 }
 
+// SetCollideLinked sets whether the 2 bodies of this string still collide with
+// each other.
+func (c *StringToBodyConstraint) SetCollideLinked(collide bool) {
+	c.collide = collide
+}
+
+// LinkedBodies returns the bodies of this string.
+func (c *StringToBodyConstraint) LinkedBodies() (*RigidBody, *RigidBody, bool) {
+	return c.bodies[0], c.bodies[1], c.collide
+}
+
 // GenerateContacts will generate maximum 1 contact if the rigid body's point in
 // world coordinates is too far from the other point in the other rigid body.
 func (c *StringToBodyConstraint) GenerateContacts(contacts []Contact) int {
+	if c.Broken() {
+		return 0
+	}
 	bodyPointsInWorld := [2]glm.Vec3{c.bodies[0].transformMatrix.Transform(&c.localPoints[0]),
 		c.bodies[1].transformMatrix.Transform(&c.localPoints[1])}
 	dir := bodyPointsInWorld[1].Sub(&bodyPointsInWorld[0])
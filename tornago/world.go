@@ -1,5 +1,9 @@
 package tornago
 
+import (
+	"time"
+)
+
 type forceGeneratorEntry struct {
 	body           *RigidBody
 	forceGenerator ForceGenerator
@@ -12,6 +16,10 @@ type World struct {
 	// The broadphase this world uses.
 	broadphase Broadphase
 
+	// The broadphase set by the user, Step replaces broadphase with a naive
+	// one when it can't move the bodies in it.
+	configured Broadphase
+
 	// The dispatcher this world uses.
 	dispatcher Dispatcher
 
@@ -21,10 +29,76 @@ type World struct {
 	// All the constraints in the world.
 	constraints []Constraint
 
+	// How many broken constraints don't link their bodies anymore.
+	unlinked int
+
+	// The pairs of bodies that never collide, linked by constraints or
+	// ignored explicitly. Both orders of a pair are stored.
+	linkedPairs  map[[2]*RigidBody]int
+	ignoredPairs map[[2]*RigidBody]struct{}
+
+	// The user pair filter, nil if not set.
+	pairFilter func(b0, b1 *RigidBody) bool
+
+	// Called when a constraint breaks, nil if not set.
+	breakHandler func(c BreakableConstraint)
+
 	// All the force generator entries in the world.
 	forceGeneratorEntries []forceGeneratorEntry
+
+	// The force fields applied to every body in their region.
+	forceFields []ForceField
+
+	// The contact generators used by this world, DefaultNarrowphase if nil.
+	narrowphase *NarrowphaseRegistry
+
+	// The friction and restitution overrides of pairs of materials.
+	materials *MaterialTable
+
+	// How many goroutines generate contacts, 0 or 1 to use the goroutine
+	// calling Step.
+	workers int
+
+	// The goroutines generating contacts when there is more than 1 worker.
+	pool *narrowphasePool
+
+	// The soft bodies that we want to simulate.
+	softBodies []*SoftBody
+
+	// Whether Step collects stats.
+	profiling bool
+
+	// Whether the world is inside Step and the mutations requested meanwhile.
+	stepping bool
+	commands []worldCommand
+
+	// The stats collected since profiling was enabled or the stats were last
+	// reset.
+	stats Stats
+
+	// Scratch memory reused between steps so that a step doesn't allocate
+	// once the buffers have grown large enough for the scene.
+	naive       NaiveBroadphase
+	volumes     []BoundingSphere
+	fieldVolume BoundingSphere
+	pcontacts   []potentialContact
+	contacts    []Contact
+	batches     []*narrowphaseBatch
+
+	// the scratch memory of the dispatcher if it's a scratchDispatcher.
+	derivateData []contactDerivateData
+
+	// the index of the first contact of every constraint, and one past the
+	// last, and the constraints that broke during the step.
+	constraintContacts []int
+	broken             []BreakableConstraint
 }
 
+const (
+	// the minimum amount by which the scratch buffers grow.
+	minScratchGrowth = 16
+)
+
 // NewWorld generates a new world with the given Broadphase and Dispatcher.
 func NewWorld(broadphase Broadphase, dispatcher Dispatcher) *World {
 	var w World
@@ -36,11 +110,15 @@ func NewWorld(broadphase Broadphase, dispatcher Dispatcher) *World {
 // management.
 func (w *World) New(broadphase Broadphase, dispatcher Dispatcher) {
 	w.broadphase = broadphase
+	w.configured = broadphase
 	w.dispatcher = dispatcher
 }
 
 // AddRigidBody adds the given rigid body to the world.
 func (w *World) AddRigidBody(body *RigidBody) {
+	if w.enqueue(worldCommand{op: opAddRigidBody, body: body}) {
+		return
+	}
 	var found bool
 	for _, b := range w.bodies {
 		if b == body {
@@ -55,6 +133,9 @@ func (w *World) AddRigidBody(body *RigidBody) {
 
 // RemoveRigidBody removes the rigid body from the world.
 func (w *World) RemoveRigidBody(body *RigidBody) {
+	if w.enqueue(worldCommand{op: opRemoveRigidBody, body: body}) {
+		return
+	}
 	for i, b := range w.bodies {
 		if b == body {
 			copy(w.bodies[i:], w.bodies[i+1:])
@@ -65,8 +146,38 @@ func (w *World) RemoveRigidBody(body *RigidBody) {
 	}
 }
 
+// AddSoftBody adds the given soft body to the world.
+func (w *World) AddSoftBody(s *SoftBody) {
+	if w.enqueue(worldCommand{op: opAddSoftBody, softBody: s}) {
+		return
+	}
+	for _, o := range w.softBodies {
+		if o == s {
+			return
+		}
+	}
+	w.softBodies = append(w.softBodies, s)
+}
+
+// RemoveSoftBody removes the soft body from the world.
+func (w *World) RemoveSoftBody(s *SoftBody) {
+	if w.enqueue(worldCommand{op: opRemoveSoftBody, softBody: s}) {
+		return
+	}
+	for i, o := range w.softBodies {
+		if o == s {
+			copy(w.softBodies[i:], w.softBodies[i+1:])
+			w.softBodies = w.softBodies[:len(w.softBodies)-1]
+			return
+		}
+	}
+}
+
 // AddForceGenerator adds the given force generator to the world.
 func (w *World) AddForceGenerator(body *RigidBody, forceGenerator ForceGenerator) {
+	if w.enqueue(worldCommand{op: opAddForceGenerator, body: body, forceGenerator: forceGenerator}) {
+		return
+	}
 	var found bool
 	for _, f := range w.forceGeneratorEntries {
 		if f.body == body && f.forceGenerator == forceGenerator {
@@ -83,6 +194,9 @@ func (w *World) AddForceGenerator(body *RigidBody, forceGenerator ForceGenerator
 
 // RemoveForceGenerator removes this {force generator, body} from the world.
 func (w *World) RemoveForceGenerator(body *RigidBody, forceGenerator ForceGenerator) {
+	if w.enqueue(worldCommand{op: opRemoveForceGenerator, body: body, forceGenerator: forceGenerator}) {
+		return
+	}
 	for i, e := range w.forceGeneratorEntries {
 		if e.body == body && e.forceGenerator == forceGenerator {
 			copy(w.forceGeneratorEntries[i:], w.forceGeneratorEntries[i+1:])
@@ -92,9 +206,63 @@ func (w *World) RemoveForceGenerator(body *RigidBody, forceGenerator ForceGenera
 	}
 }
 
+// AddForceField adds the given force field to the world, it will be applied to
+// every body with finite mass in its region.
+func (w *World) AddForceField(field ForceField) {
+	if w.enqueue(worldCommand{op: opAddForceField, forceField: field}) {
+		return
+	}
+	for _, f := range w.forceFields {
+		if f == field {
+			return
+		}
+	}
+	w.forceFields = append(w.forceFields, field)
+}
+
+// RemoveForceField removes the force field from the world.
+func (w *World) RemoveForceField(field ForceField) {
+	if w.enqueue(worldCommand{op: opRemoveForceField, forceField: field}) {
+		return
+	}
+	for i, f := range w.forceFields {
+		if f == field {
+			copy(w.forceFields[i:], w.forceFields[i+1:])
+			w.forceFields = w.forceFields[:len(w.forceFields)-1]
+			return
+		}
+	}
+}
+
+// SetNarrowphase sets the contact generators to use, nil for
+// DefaultNarrowphase.
+func (w *World) SetNarrowphase(narrowphase *NarrowphaseRegistry) {
+	w.narrowphase = narrowphase
+}
+
+// Narrowphase returns the contact generators used by this world.
+func (w *World) Narrowphase() *NarrowphaseRegistry {
+	if w.narrowphase == nil {
+		return DefaultNarrowphase
+	}
+	return w.narrowphase
+}
+
+// SetMaterialTable sets the table of material pairs overrides to use, nil to
+// only use the combine modes of the materials.
+func (w *World) SetMaterialTable(table *MaterialTable) {
+	w.materials = table
+}
+
+// MaterialTable returns the table of material pairs overrides of this world.
+func (w *World) MaterialTable() *MaterialTable {
+	return w.materials
+}
+
 // SetBroadphase sets the broadphase to use.
 func (w *World) SetBroadphase(broadphase Broadphase) {
 	w.broadphase = broadphase
+	w.configured = broadphase
 	for _, body := range w.bodies {
 		w.broadphase.Insert(body, body.shape.GetBoundingVolume())
 	}
@@ -115,54 +283,309 @@ func (w *World) Dispatcher() Dispatcher {
 	return w.dispatcher
 }
 
+// SetProfiling enables or disables the collection of stats during Step.
+// Profiling has a small cost and is disabled by default.
+func (w *World) SetProfiling(profiling bool) {
+	w.profiling = profiling
+}
+
+// Profiling returns true if this world collects stats during Step.
+func (w *World) Profiling() bool {
+	return w.profiling
+}
+
+// Stats returns the stats accumulated while profiling was enabled.
+func (w *World) Stats() Stats {
+	return w.stats
+}
+
+// ResetStats clears the accumulated stats.
+func (w *World) ResetStats() {
+	w.stats = Stats{}
+}
+
 // RayTest casts a ray in the world a calls RayResult.AddResult for every object
 // hit.
 func (w *World) RayTest(ray Ray, result RayResult) {
+	// broadphases that know where the bodies are can skip most of them.
+	if rt, ok := w.broadphase.(interface {
+		RayTest(Ray, RayResult)
+	}); ok {
+		rt.RayTest(ray, result)
+		return
+	}
 	for _, body := range w.bodies {
 		body.shape.RayTest(ray, result)
 	}
 }
 
 // Step steps the world forward in time by the given time amount.
+// Bodies, constraints, force generators and force fields added or removed
+// during the step, from a callback for example, are added or removed once it's
+// done, even if a callback panics.
 func (w *World) Step(duration float32) {
+	w.stepping = true
+	defer func() {
+		w.stepping = false
+		w.flushCommands()
+	}()
+	if !w.profiling {
+		w.step(duration, nil)
+	} else {
+		// collect directly into the world stats, a local would escape to the
+		// heap.
+		w.stats.Last = StepStats{}
+		w.step(duration, &w.stats.Last)
+		w.stats.Steps++
+		w.stats.Total.Add(&w.stats.Last)
+	}
+}
+
+// step does the actual work of Step. If stats is not nil it is filled with the
+// timings and counters of this step.
+func (w *World) step(duration float32, stats *StepStats) {
+	var start time.Time
+	if stats != nil {
+		start = time.Now()
+	}
 
 	// iterate over the force generators.
 	for _, e := range w.forceGeneratorEntries {
 		e.forceGenerator.UpdateForce(e.body, duration)
 	}
+	w.applyForceFields(duration)
+
+	if stats != nil {
+		start = lap(&stats.ForceGenerators, start)
+	}
 
 	// integrate all the rigid bodies.
 	for _, b := range w.bodies {
 		b.Integrate(duration)
 	}
 
-	// TODO: the broadphase needs to be updated every frame but for now we'll
-	// just rebuild it.
-	w.broadphase = &NaiveBroadphase{}
-	for _, b := range w.bodies {
-		w.broadphase.Insert(b, b.shape.GetBoundingVolume())
+	if stats != nil {
+		start = lap(&stats.Integration, start)
+		stats.BodiesIntegrated = len(w.bodies)
+	}
+
+	// the constraints repaired since the last step link their bodies again.
+	if w.unlinked > 0 {
+		for _, c := range w.constraints {
+			if bc, ok := c.(BreakableConstraint); ok {
+				w.updateLink(bc)
+			}
+		}
 	}
 
-	pcontacts := make([]potentialContact, len(w.bodies))
-	gen := w.broadphase.GeneratePotentialContacts(pcontacts)
+	// The volumes are all allocated before inserting so that the broadphase
+	// entries can point into the slice.
+	if cap(w.volumes) < len(w.bodies) {
+		w.volumes = make([]BoundingSphere, len(w.bodies), 2*len(w.bodies))
+	}
+	w.volumes = w.volumes[:len(w.bodies)]
+	if mb, ok := w.broadphase.(MovableBroadphase); ok {
+		for i, b := range w.bodies {
+			boundingVolumeIn(b.shape, &w.volumes[i])
+			mb.Move(b, &w.volumes[i])
+		}
+	} else {
+		// TODO: the other broadphases need to be updated every frame but for
+		// now we'll just rebuild a naive one.
+		w.naive.objects = w.naive.objects[:0]
+		for i, b := range w.bodies {
+			boundingVolumeIn(b.shape, &w.volumes[i])
+			// bodies are unique in the world, no need to go through Insert.
+			w.naive.objects = append(w.naive.objects, naiveBroadphaseEntry{
+				body:   b,
+				volume: &w.volumes[i],
+			})
+		}
+		w.broadphase = &w.naive
+	}
 
-	contacts := make([]Contact, gen+10) // 10 is just a buffer
-	gen = resolvePotentialContacts(pcontacts[:gen], contacts)
+	var gen int
+	if sb, ok := w.broadphase.(StreamingBroadphase); ok && w.workers > 1 {
+		gen = w.collideParallel(sb, stats, &start)
+	} else {
+		gen = w.collide(stats, &start)
+	}
+	if stats != nil {
+		for i := range w.contacts[:gen] {
+			if p := w.contacts[i].penetration; p > stats.MaxPenetration {
+				stats.MaxPenetration = p
+			}
+		}
+	}
 
+	w.constraintContacts = append(w.constraintContacts[:0], gen)
 	for _, constraint := range w.constraints {
-		if gen >= len(contacts) {
+		for {
+			var n int
+			if gen < len(w.contacts) {
+				n = constraint.GenerateContacts(w.contacts[gen:])
+			}
+			if gen+n < len(w.contacts) {
+				gen += n
+				break
+			}
+			w.growContacts(gen)
+			if stats != nil {
+				stats.Overflows++
+			}
+		}
+		w.constraintContacts = append(w.constraintContacts, gen)
+	}
+
+	if stats != nil {
+		start = lap(&stats.Constraints, start)
+		stats.Contacts = gen
+	}
+
+	contacts := w.contacts[:gen]
+	if sd, ok := w.dispatcher.(scratchDispatcher); ok {
+		positionIterations, velocityIterations := sd.resolveContacts(contacts, duration, &w.derivateData)
+		if stats != nil {
+			stats.PositionIterations, stats.VelocityIterations = positionIterations, velocityIterations
+		}
+	} else if sd, ok := w.dispatcher.(StatsDispatcher); ok && stats != nil {
+		sd.ResolveContactsStats(contacts, duration, stats)
+	} else {
+		w.dispatcher.ResolveContacts(contacts, duration)
+	}
+	w.recordFeedback(contacts, duration)
+
+	if stats != nil {
+		start = lap(&stats.Solver, start)
+	}
+
+	// soft bodies go last so they collide against the resolved rigid bodies.
+	for _, s := range w.softBodies {
+		s.Step(duration, w.bodies)
+	}
+
+	if stats != nil {
+		lap(&stats.SoftBodies, start)
+	}
+}
+
+// collide generates the potential contacts, filters them and generates their
+// contacts in w.contacts on the calling goroutine. It returns how many
+// contacts were generated, there is always room for more after them. start is
+// the beginning of the current stats lap.
+func (w *World) collide(stats *StepStats, start *time.Time) int {
+	// generate the potential contacts, if the buffer was filled some might
+	// have been dropped so we grow it and try again.
+	var npc int
+	for {
+		npc = w.broadphase.GeneratePotentialContacts(w.pcontacts)
+		if npc < len(w.pcontacts) {
+			break
+		}
+		w.growPotentialContacts()
+		if stats != nil {
+			stats.Overflows++
+		}
+	}
+
+	if stats != nil {
+		*start = lap(&stats.Broadphase, *start)
+		stats.PotentialContacts = npc
+	}
+
+	filtered := w.filterPairs(w.pcontacts[:npc])
+	if stats != nil {
+		stats.FilteredPairs = npc - filtered
+	}
+	npc = filtered
+
+	var gen int
+	for {
+		gen = resolvePotentialContacts(w.pcontacts[:npc], w.contacts, w.materials, w.narrowphase)
+		if gen < len(w.contacts) {
 			break
 		}
+		w.growContacts(0)
+		if stats != nil {
+			stats.Overflows++
+		}
+	}
 
-		n := constraint.GenerateContacts(contacts[gen:])
-		gen += n
+	if stats != nil {
+		*start = lap(&stats.Narrowphase, *start)
 	}
+	return gen
+}
 
-	w.dispatcher.ResolveContacts(contacts[:gen], duration)
+// recordFeedback saves the impulse applied to every breakable constraint,
+// breaks the overloaded ones and calls the break handler for them. The handler
+// is only called once all constraints are updated so it can remove them.
+func (w *World) recordFeedback(contacts []Contact, duration float32) {
+	w.broken = w.broken[:0]
+	for i, c := range w.constraints {
+		bc, ok := c.(BreakableConstraint)
+		if !ok {
+			continue
+		}
+		var impulse float32
+		for k := w.constraintContacts[i]; k < w.constraintContacts[i+1]; k++ {
+			impulse += contacts[k].impulse
+		}
+		if bc.Feedback().record(impulse, duration) {
+			w.broken = append(w.broken, bc)
+			w.updateLink(bc)
+		}
+	}
+	if w.breakHandler != nil {
+		for _, c := range w.broken {
+			w.breakHandler(c)
+		}
+	}
+	for i := range w.broken {
+		w.broken[i] = nil
+	}
 }
 
-// AddConstraint adds a constraint to the world.
+// applyForceFields applies every force field to the bodies in its region.
+func (w *World) applyForceFields(duration float32) {
+	for _, f := range w.forceFields {
+		f.Update(duration)
+		region, bounded := f.Region()
+		for _, b := range w.bodies {
+			if !b.HasFiniteMass() {
+				continue
+			}
+			if bounded {
+				boundingVolumeIn(b.shape, &w.fieldVolume)
+				if !region.Overlaps(&w.fieldVolume) {
+					continue
+				}
+			}
+			f.UpdateForce(b, duration)
+		}
+	}
+}
+
+// growPotentialContacts grows the potential contacts scratch buffer. The
+// previous content is discarded.
+func (w *World) growPotentialContacts() {
+	w.pcontacts = make([]potentialContact, 2*len(w.pcontacts)+minScratchGrowth)
+}
+
+// growContacts grows the contacts scratch buffer, keeping the first keep
+// contacts.
+func (w *World) growContacts(keep int) {
+	contacts := make([]Contact, 2*len(w.contacts)+minScratchGrowth)
+	copy(contacts, w.contacts[:keep])
+	w.contacts = contacts
+}
+
+// AddConstraint adds a constraint to the world. The bodies linked by a
+// LinkingConstraint stop colliding with each other unless it asks otherwise.
 func (w *World) AddConstraint(constraint Constraint) {
+	if w.enqueue(worldCommand{op: opAddConstraint, constraint: constraint}) {
+		return
+	}
 	var found bool
 	for _, c := range w.constraints {
 		if c == constraint {
@@ -171,15 +594,130 @@ func (w *World) AddConstraint(constraint Constraint) {
 	}
 	if !found {
 		w.constraints = append(w.constraints, constraint)
+		w.link(constraint, 1)
+		if bc, ok := constraint.(BreakableConstraint); ok {
+			bc.Feedback().unlinked = false
+			w.updateLink(bc)
+		}
 	}
 }
 
 // RemoveConstraint removes a constraint from the world.
 func (w *World) RemoveConstraint(constraint Constraint) {
+	if w.enqueue(worldCommand{op: opRemoveConstraint, constraint: constraint}) {
+		return
+	}
 	for i, c := range w.constraints {
 		if c == constraint {
 			copy(w.constraints[i:], w.constraints[i+1:])
 			w.constraints = w.constraints[:len(w.constraints)-1]
+			if bc, ok := constraint.(BreakableConstraint); ok && bc.Feedback().unlinked {
+				bc.Feedback().unlinked = false
+				w.unlinked--
+			} else {
+				w.link(constraint, -1)
+			}
+			return
+		}
+	}
+}
+
+// link adds delta to the link count of the bodies of constraint if it's a
+// LinkingConstraint that disables their collisions.
+func (w *World) link(constraint Constraint, delta int) {
+	lc, ok := constraint.(LinkingConstraint)
+	if !ok {
+		return
+	}
+	b0, b1, collide := lc.LinkedBodies()
+	if collide || b0 == nil || b1 == nil {
+		return
+	}
+	if w.linkedPairs == nil {
+		w.linkedPairs = make(map[[2]*RigidBody]int)
+	}
+	for _, pair := range [2][2]*RigidBody{{b0, b1}, {b1, b0}} {
+		if n := w.linkedPairs[pair] + delta; n > 0 {
+			w.linkedPairs[pair] = n
+		} else {
+			delete(w.linkedPairs, pair)
+		}
+	}
+}
+
+// updateLink unlinks the bodies of a breakable constraint when it breaks and
+// links them again once it's repaired.
+func (w *World) updateLink(c BreakableConstraint) {
+	f := c.Feedback()
+	if f.broken == f.unlinked {
+		return
+	}
+	f.unlinked = f.broken
+	if f.broken {
+		w.unlinked++
+		w.link(c, -1)
+	} else {
+		w.unlinked--
+		w.link(c, 1)
+	}
+}
+
+// IgnorePair stops the given bodies from colliding with each other.
+func (w *World) IgnorePair(b0, b1 *RigidBody) {
+	if w.ignoredPairs == nil {
+		w.ignoredPairs = make(map[[2]*RigidBody]struct{})
+	}
+	w.ignoredPairs[[2]*RigidBody{b0, b1}] = struct{}{}
+	w.ignoredPairs[[2]*RigidBody{b1, b0}] = struct{}{}
+}
+
+// UnignorePair lets the given bodies collide with each other again, unless
+// they are linked by a constraint.
+func (w *World) UnignorePair(b0, b1 *RigidBody) {
+	delete(w.ignoredPairs, [2]*RigidBody{b0, b1})
+	delete(w.ignoredPairs, [2]*RigidBody{b1, b0})
+}
+
+// SetPairFilter sets a function called for every pair of bodies the broadphase
+// finds, after the ignore lists and before the narrowphase. The pair is only
+// collided if it returns true. nil removes the filter. Group and mask
+// filtering is still applied.
+func (w *World) SetPairFilter(filter func(b0, b1 *RigidBody) bool) {
+	w.pairFilter = filter
+}
+
+// SetBreakHandler sets a function called at the end of every step for each
+// constraint that broke during it, nil to remove it. The handler can remove
+// the constraint from the world.
+func (w *World) SetBreakHandler(handler func(c BreakableConstraint)) {
+	w.breakHandler = handler
+}
+
+// ShouldCollide returns false if the given bodies are linked by a constraint,
+// ignored or rejected by the pair filter.
+func (w *World) ShouldCollide(b0, b1 *RigidBody) bool {
+	pair := [2]*RigidBody{b0, b1}
+	if _, ok := w.linkedPairs[pair]; ok {
+		return false
+	}
+	if _, ok := w.ignoredPairs[pair]; ok {
+		return false
+	}
+	return w.pairFilter == nil || w.pairFilter(b0, b1)
+}
+
+// filterPairs removes the potential contacts that shouldn't collide, in place,
+// and returns how many are left.
+func (w *World) filterPairs(pcontacts []potentialContact) int {
+	if len(w.linkedPairs) == 0 && len(w.ignoredPairs) == 0 && w.pairFilter == nil {
+		return len(pcontacts)
+	}
+	var n int
+	for _, pc := range pcontacts {
+		if w.ShouldCollide(pc.bodies[0], pc.bodies[1]) {
+			pcontacts[n] = pc
+			n++
 		}
 	}
+	return n
 }
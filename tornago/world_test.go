@@ -291,3 +291,146 @@ func TestWorld_RemoveConstraints(t *testing.T) {
 		t.Errorf("World should contain 0 constraints: %d", len(w.constraints))
 	}
 }
+
+// newStepTestWorld returns a world using broadphase and the given amount of
+// narrowphase workers with a static ground and n boxes and spheres piled on
+// it, and the boxes and spheres. The first 2 are linked by a rod.
+func newStepTestWorld(broadphase Broadphase, n, workers int) (*World, []*RigidBody) {
+	w := NewWorld(broadphase, ContactResolver{})
+	w.SetNarrowphaseWorkers(workers)
+
+	ground := NewRigidBody()
+	ground.SetMass(0)
+	ground.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 50, Y: 1, Z: 50}))
+	ground.SetPosition3f(0, -1, 0)
+	w.AddRigidBody(ground)
+
+	var bodies []*RigidBody
+	for i := 0; i < n; i++ {
+		b := NewRigidBody()
+		if i%2 == 0 {
+			b.SetCollisionShape(NewCollisionSphere(0.5))
+		} else {
+			b.SetCollisionShape(NewCollisionBox(glm.Vec3{X: 0.5, Y: 0.5, Z: 0.5}))
+		}
+		b.SetAcceleration3f(0, -10, 0)
+		b.SetPosition3f(float32(i%5)*0.95, 0.45+float32(i/25)*0.95, float32(i/5%5)*0.95)
+		w.AddRigidBody(b)
+		bodies = append(bodies, b)
+	}
+	w.AddConstraint(NewRodConstraintToBody(2, bodies[0], bodies[1], &glm.Vec3{}, &glm.Vec3{}))
+	return w, bodies
+}
+
+func TestWorld_StepAllocs(t *testing.T) {
+	w, _ := newStepTestWorld(&NaiveBroadphase{}, 10, 1)
+
+	// let the scratch buffers grow.
+	for i := 0; i < 10; i++ {
+		w.Step(1.0 / 60)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { w.Step(1.0 / 60) }); allocs != 0 {
+		t.Errorf("w.Step allocated %v times per run, want 0", allocs)
+	}
+
+	w.SetProfiling(true)
+	if allocs := testing.AllocsPerRun(100, func() { w.Step(1.0 / 60) }); allocs != 0 {
+		t.Errorf("w.Step allocated %v times per run while profiling, want 0", allocs)
+	}
+}
+
+func TestWorld_StepOverflow(t *testing.T) {
+	w := NewWorld(&NaiveBroadphase{}, ContactResolver{})
+	w.SetProfiling(true)
+
+	// every sphere overlaps every other one, that's a lot more pairs then
+	// bodies.
+	const n = 20
+	for i := 0; i < n; i++ {
+		b := NewRigidBody()
+		b.SetCollisionShape(NewCollisionSphere(1))
+		b.SetPosition3f(float32(i)*0.01, 0, 0)
+		w.AddRigidBody(b)
+	}
+
+	w.Step(1.0 / 60)
+
+	s := w.Stats()
+	if want := n * (n - 1) / 2; s.Last.PotentialContacts != want {
+		t.Errorf("s.Last.PotentialContacts = %d, want %d", s.Last.PotentialContacts, want)
+	}
+	if want := n * (n - 1) / 2; s.Last.Contacts != want {
+		t.Errorf("s.Last.Contacts = %d, want %d", s.Last.Contacts, want)
+	}
+	if s.Last.Overflows == 0 {
+		t.Error("s.Last.Overflows = 0, want buffers to have grown")
+	}
+}
+
+func BenchmarkWorld_Step(b *testing.B) {
+	w, _ := newStepTestWorld(&NaiveBroadphase{}, 10, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.Step(1.0 / 60)
+	}
+}
+
+func TestWorld_PairFiltering(t *testing.T) {
+	w := NewWorld(&NaiveBroadphase{}, ContactResolver{})
+	bodies := make([]*RigidBody, 4)
+	for i := range bodies {
+		bodies[i] = NewRigidBody()
+		bodies[i].SetCollisionShape(NewCollisionSphere(1))
+		bodies[i].SetPosition3f(float32(i), 0, 0)
+		w.AddRigidBody(bodies[i])
+	}
+	w.SetProfiling(true)
+
+	// every neighbour overlaps: 0-1, 1-2, 2-3.
+	count := func() int {
+		w.Step(0)
+		s := w.Stats()
+		return s.Last.PotentialContacts - s.Last.FilteredPairs
+	}
+	if n := count(); n != 3 {
+		t.Errorf("%d pairs before filtering, want 3", n)
+	}
+
+	rod := NewRodConstraintToBody(1, bodies[0], bodies[1], &glm.Vec3{}, &glm.Vec3{})
+	w.AddConstraint(rod)
+	if w.ShouldCollide(bodies[1], bodies[0]) {
+		t.Error("bodies linked by a rod should not collide")
+	}
+	if n := count(); n != 2 {
+		t.Errorf("%d pairs with a rod, want 2", n)
+	}
+
+	w.IgnorePair(bodies[2], bodies[1])
+	if n := count(); n != 1 {
+		t.Errorf("%d pairs with an ignored pair, want 1", n)
+	}
+
+	var calls int
+	w.SetPairFilter(func(b0, b1 *RigidBody) bool {
+		calls++
+		return b0 != bodies[3] && b1 != bodies[3]
+	})
+	if n := count(); n != 0 || calls != 1 {
+		t.Errorf("%d pairs with a pair filter called %d times, want 0 and 1", n, calls)
+	}
+
+	w.SetPairFilter(nil)
+	w.UnignorePair(bodies[1], bodies[2])
+	w.RemoveConstraint(rod)
+	if n := count(); n != 3 {
+		t.Errorf("%d pairs after removing the filters, want 3", n)
+	}
+
+	// constraints can opt out.
+	rod.CollideLinked = true
+	w.AddConstraint(rod)
+	if !w.ShouldCollide(bodies[0], bodies[1]) {
+		t.Error("the rod asked for its bodies to collide")
+	}
+}
@@ -0,0 +1,82 @@
+package tornago
+
+// worldOp is the mutation a worldCommand applies.
+type worldOp int
+
+const (
+	opAddRigidBody worldOp = iota
+	opRemoveRigidBody
+	opAddSoftBody
+	opRemoveSoftBody
+	opAddConstraint
+	opRemoveConstraint
+	opAddForceGenerator
+	opRemoveForceGenerator
+	opAddForceField
+	opRemoveForceField
+)
+
+// worldCommand is a mutation of the world requested during a step. Only the
+// fields used by its op are set.
+type worldCommand struct {
+	op             worldOp
+	body           *RigidBody
+	softBody       *SoftBody
+	constraint     Constraint
+	forceGenerator ForceGenerator
+	forceField     ForceField
+}
+
+// Stepping returns true while the world is inside Step, for example when
+// called from a collision callback. The bodies, constraints, force generators
+// and force fields added or removed while stepping are only added or removed
+// at the end of the step, in the order the calls were made.
+func (w *World) Stepping() bool {
+	return w.stepping
+}
+
+// enqueue saves the command to apply it at the end of the step and returns
+// true, or returns false if the world isn't stepping and the command should be
+// applied right away.
+func (w *World) enqueue(c worldCommand) bool {
+	if !w.stepping {
+		return false
+	}
+	w.commands = append(w.commands, c)
+	return true
+}
+
+// flushCommands applies the commands queued during the step, in order.
+func (w *World) flushCommands() {
+	for i := range w.commands {
+		c := &w.commands[i]
+		switch c.op {
+		case opAddRigidBody:
+			w.AddRigidBody(c.body)
+		case opRemoveRigidBody:
+			w.RemoveRigidBody(c.body)
+		case opAddSoftBody:
+			w.AddSoftBody(c.softBody)
+		case opRemoveSoftBody:
+			w.RemoveSoftBody(c.softBody)
+		case opAddConstraint:
+			w.AddConstraint(c.constraint)
+		case opRemoveConstraint:
+			w.RemoveConstraint(c.constraint)
+		case opAddForceGenerator:
+			w.AddForceGenerator(c.body, c.forceGenerator)
+		case opRemoveForceGenerator:
+			w.RemoveForceGenerator(c.body, c.forceGenerator)
+		case opAddForceField:
+			w.AddForceField(c.forceField)
+		case opRemoveForceField:
+			w.RemoveForceField(c.forceField)
+		}
+	}
+
+	// drop the references so the removed objects can be collected.
+	for i := range w.commands {
+		w.commands[i] = worldCommand{}
+	}
+	w.commands = w.commands[:0]
+}
@@ -0,0 +1,100 @@
+package tornago
+
+import (
+	"github.com/luxengine/lux/glm"
+	"testing"
+)
+
+func TestWorld_DeferredCommands(t *testing.T) {
+	w := NewWorld(&NaiveBroadphase{}, ContactResolver{})
+	newBody := func(x float32) *RigidBody {
+		b := NewRigidBody()
+		b.SetCollisionShape(NewCollisionSphere(1))
+		b.SetPosition3f(x, 0, 0)
+		b.SetLinearDamping(1)
+		return b
+	}
+	a, b := newBody(0), newBody(1.5)
+	w.AddRigidBody(a)
+	w.AddRigidBody(b)
+
+	spawned := newBody(10)
+	gravity := NewGravityForceGenerator(&glm.Vec3{X: 0, Y: -10, Z: 0})
+	rod := NewRodConstraintToBody(5, a, spawned, &glm.Vec3{}, &glm.Vec3{})
+
+	var calls int
+	a.SetCallback(func(other *RigidBody) {
+		calls++
+		if !w.Stepping() {
+			t.Error("Stepping() = false inside a callback")
+		}
+		w.RemoveRigidBody(other)
+		w.AddRigidBody(spawned)
+		w.AddForceGenerator(spawned, gravity)
+		w.AddConstraint(rod)
+
+		// nothing changes until the end of the step.
+		if len(w.bodies) != 2 || len(w.constraints) != 0 || len(w.forceGeneratorEntries) != 0 {
+			t.Errorf("the world changed during the step: %d bodies, %d constraints, %d force generators", len(w.bodies), len(w.constraints), len(w.forceGeneratorEntries))
+		}
+	})
+
+	w.Step(1.0 / 60)
+	if calls != 1 {
+		t.Fatalf("callback called %d times, want 1", calls)
+	}
+	if w.Stepping() {
+		t.Error("Stepping() = true after the step")
+	}
+	if len(w.bodies) != 2 || w.bodies[0] != a || w.bodies[1] != spawned {
+		t.Errorf("bodies = %v, want [%p %p]", w.bodies, a, spawned)
+	}
+	if len(w.constraints) != 1 || len(w.forceGeneratorEntries) != 1 {
+		t.Errorf("%d constraints, %d force generators, want 1, 1", len(w.constraints), len(w.forceGeneratorEntries))
+	}
+	if len(w.commands) != 0 {
+		t.Errorf("%d commands left after the step", len(w.commands))
+	}
+
+	// outside of a step the changes are immediate.
+	w.RemoveConstraint(rod)
+	if len(w.constraints) != 0 {
+		t.Errorf("%d constraints, want 0", len(w.constraints))
+	}
+}
+
+func TestWorld_DeferredCommandsPanic(t *testing.T) {
+	w := NewWorld(&NaiveBroadphase{}, ContactResolver{})
+	a, b := NewRigidBody(), NewRigidBody()
+	a.SetCollisionShape(NewCollisionSphere(1))
+	b.SetCollisionShape(NewCollisionSphere(1))
+	w.AddRigidBody(a)
+	w.AddRigidBody(b)
+
+	a.SetCallback(func(other *RigidBody) {
+		w.RemoveRigidBody(other)
+		panic("callback")
+	})
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("the callback didn't panic")
+			}
+		}()
+		w.Step(1.0 / 60)
+	}()
+
+	// the world isn't stuck stepping and the removal went through.
+	if w.Stepping() {
+		t.Error("Stepping() = true after a panic in a callback")
+	}
+	if len(w.bodies) != 1 || w.bodies[0] != a {
+		t.Errorf("bodies = %v, want [%p]", w.bodies, a)
+	}
+	c := NewRigidBody()
+	c.SetCollisionShape(NewCollisionSphere(1))
+	w.AddRigidBody(c)
+	if len(w.bodies) != 2 {
+		t.Errorf("%d bodies, the addition after the panic was deferred", len(w.bodies))
+	}
+}